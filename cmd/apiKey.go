@@ -0,0 +1,147 @@
+/*
+Copyright © 2024 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"crud-customer/config"
+	"crud-customer/internal/repository"
+	"crud-customer/internal/service"
+	"crud-customer/pkg/database"
+	"crud-customer/pkg/tenant"
+	"crud-customer/util"
+	"fmt"
+	"github.com/spf13/cobra"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// apiKeyCmd represents the apikey command
+var apiKeyCmd = &cobra.Command{
+	Use:   "apikey",
+	Short: "Manage API keys used to authenticate against /api/v1",
+}
+
+// apiKeyCreateCmd represents the apikey create command
+var apiKeyCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create an API key and print it once",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name, _ := cmd.Flags().GetString("name")
+		tenantID, _ := cmd.Flags().GetString("tenant")
+		scopes, _ := cmd.Flags().GetStringSlice("scope")
+		expiresIn, _ := cmd.Flags().GetDuration("expires-in")
+
+		if tenantID != "" && tenantID != tenant.AnyTenant && !tenant.IsValidID(tenantID) {
+			return fmt.Errorf("invalid tenant id: %q", tenantID)
+		}
+
+		apiKeyService, err := newAPIKeyService()
+		if err != nil {
+			return err
+		}
+		var expiresAt *time.Time
+		if expiresIn > 0 {
+			t := time.Now().Add(expiresIn)
+			expiresAt = &t
+		}
+		apiKey, key, err := apiKeyService.CreateAPIKey(cmd.Context(), name, tenantID, scopes, expiresAt)
+		if err != nil {
+			return fmt.Errorf("failed to create api key: %w", err)
+		}
+
+		fmt.Printf("Created API key %d (%s)\n", apiKey.ID, apiKey.Name)
+		fmt.Println("Store this key now, it will not be shown again:")
+		fmt.Println(key)
+		return nil
+	},
+}
+
+// apiKeyListCmd represents the apikey list command
+var apiKeyListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List API keys",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		apiKeyService, err := newAPIKeyService()
+		if err != nil {
+			return err
+		}
+		apiKeys, err := apiKeyService.GetAllAPIKey(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("failed to list api keys: %w", err)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tNAME\tTENANT\tPREFIX\tSCOPES\tEXPIRES\tLAST USED\tREVOKED")
+		for _, apiKey := range apiKeys {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", apiKey.ID, apiKey.Name, formatTenant(apiKey.TenantID), apiKey.Prefix,
+				strings.Join(apiKey.Scopes, ","), formatTime(apiKey.ExpiresAt), formatTime(apiKey.LastUsedAt), formatTime(apiKey.RevokedAt))
+		}
+		return w.Flush()
+	},
+}
+
+// apiKeyRevokeCmd represents the apikey revoke command
+var apiKeyRevokeCmd = &cobra.Command{
+	Use:   "revoke <id>",
+	Short: "Revoke an API key",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := strconv.ParseUint(args[0], 10, 0)
+		if err != nil {
+			return fmt.Errorf("invalid api key id: %s", args[0])
+		}
+		apiKeyService, err := newAPIKeyService()
+		if err != nil {
+			return err
+		}
+		if err := apiKeyService.RevokeAPIKey(cmd.Context(), uint(id)); err != nil {
+			return fmt.Errorf("failed to revoke api key %d: %w", id, err)
+		}
+		fmt.Printf("Revoked API key %d\n", id)
+		return nil
+	},
+}
+
+func newAPIKeyService() (service.APIKey, error) {
+	cfg, err := util.GetConfig[config.Config]()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config: %w", err)
+	}
+	db, err := database.NewGormDB(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect database: %w", err)
+	}
+	return service.NewAPIKey(cfg, repository.NewAPIKey(db.GetDB(), cfg)), nil
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Local().Format(time.RFC3339)
+}
+
+func formatTenant(tenantID string) string {
+	if tenantID == "" {
+		return "(default)"
+	}
+	return tenantID
+}
+
+func init() {
+	rootCmd.AddCommand(apiKeyCmd)
+	apiKeyCmd.AddCommand(apiKeyCreateCmd)
+	apiKeyCmd.AddCommand(apiKeyListCmd)
+	apiKeyCmd.AddCommand(apiKeyRevokeCmd)
+
+	apiKeyCreateCmd.Flags().String("name", "", "Name describing who uses the key")
+	apiKeyCreateCmd.Flags().String("tenant", "", "Tenant the key is bound to, or * for any tenant (default server.tenant.default)")
+	apiKeyCreateCmd.Flags().StringSlice("scope", nil, "Permission granted to the key, e.g. customers:read or customers:*, can be repeated")
+	apiKeyCreateCmd.Flags().Duration("expires-in", 0, "Lifetime of the key, e.g. 720h (default never expires)")
+	_ = apiKeyCreateCmd.MarkFlagRequired("name")
+	_ = apiKeyCreateCmd.MarkFlagRequired("scope")
+}
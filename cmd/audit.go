@@ -0,0 +1,49 @@
+/*
+Copyright © 2024 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"crud-customer/config"
+	"crud-customer/internal/repository"
+	"crud-customer/internal/service"
+	"crud-customer/pkg/database"
+	"crud-customer/util"
+	"fmt"
+	"github.com/spf13/cobra"
+)
+
+// auditCmd represents the audit command
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Inspect the audit log of customer mutations",
+}
+
+// auditVerifyCmd represents the audit verify command
+var auditVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Check the hash chain of the audit log for tampering",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := util.GetConfig[config.Config]()
+		if err != nil {
+			return fmt.Errorf("failed to get config: %w", err)
+		}
+		db, err := database.NewGormDB(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to connect database: %w", err)
+		}
+
+		auditService := service.NewAudit(cfg, repository.NewAudit(db.GetDB(), cfg))
+		verified, err := auditService.VerifyAuditLog(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("audit log verification failed after %d entries: %w", verified, err)
+		}
+		fmt.Printf("Audit log verified: %d entries\n", verified)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(auditCmd)
+	auditCmd.AddCommand(auditVerifyCmd)
+}
@@ -0,0 +1,87 @@
+/*
+Copyright © 2024 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"crud-customer/config"
+	"crud-customer/pkg/admin"
+	"crud-customer/util"
+	"fmt"
+	"github.com/spf13/cobra"
+	"sort"
+	"text/tabwriter"
+)
+
+// configCmd represents the config command
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Validate, print or generate the config",
+}
+
+// configValidateCmd represents the config validate command
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validate the config file, its profile, the environment and the secret files",
+	// A config error is not a usage error.
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if _, err := util.GetConfig[config.Config](); err != nil {
+			return err
+		}
+		if configProfile != "" {
+			fmt.Fprintf(cmd.OutOrStdout(), "%s with profile %s is valid\n", configFile, configProfile)
+		} else {
+			fmt.Fprintf(cmd.OutOrStdout(), "%s is valid\n", configFile)
+		}
+		return nil
+	},
+}
+
+// configPrintCmd represents the config print command
+var configPrintCmd = &cobra.Command{
+	Use:   "print",
+	Short: "Print the effective config, with secrets masked, and the source of each value",
+	// A config error is not a usage error.
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := util.GetConfig[config.Config]()
+		if err != nil {
+			return err
+		}
+
+		settings := admin.Flatten(admin.Sanitize(cfg))
+		keys := make([]string, 0, len(settings))
+		for key := range settings {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "KEY\tVALUE\tSOURCE")
+		for _, key := range keys {
+			value := settings[key]
+			if value == nil {
+				value = ""
+			}
+			fmt.Fprintf(w, "%s\t%v\t%s\n", key, value, util.ConfigSource(key))
+		}
+		return w.Flush()
+	},
+}
+
+// configExampleCmd represents the config example command
+var configExampleCmd = &cobra.Command{
+	Use:   "example",
+	Short: "Print an example config with every key, its default and its validation rules",
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Fprint(cmd.OutOrStdout(), util.ExampleConfig[config.Config]())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configValidateCmd)
+	configCmd.AddCommand(configPrintCmd)
+	configCmd.AddCommand(configExampleCmd)
+}
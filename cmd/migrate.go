@@ -0,0 +1,193 @@
+/*
+Copyright © 2024 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"crud-customer/config"
+	"crud-customer/pkg/database"
+	"crud-customer/pkg/migrate"
+	"crud-customer/util"
+	"fmt"
+	"github.com/spf13/cobra"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// migrateCmd represents the migrate command
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Apply, revert and create database migrations",
+}
+
+// migrateUpCmd represents the migrate up command
+var migrateUpCmd = &cobra.Command{
+	Use:          "up",
+	Short:        "Apply the pending migrations",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		steps, _ := cmd.Flags().GetInt("steps")
+		db, err := newMigrateDB()
+		if err != nil {
+			return err
+		}
+		applied, err := db.Migrator().Up(cmd.Context(), steps)
+		for _, migration := range applied {
+			fmt.Printf("Applied %s\n", migration)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to migrate: %w", err)
+		}
+		if len(applied) == 0 {
+			fmt.Println("No pending migrations")
+		}
+		return nil
+	},
+}
+
+// migrateDownCmd represents the migrate down command
+var migrateDownCmd = &cobra.Command{
+	Use:          "down",
+	Short:        "Revert the last applied migrations",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		steps, _ := cmd.Flags().GetInt("steps")
+		if steps < 1 {
+			return fmt.Errorf("invalid steps: %d", steps)
+		}
+		db, err := newMigrateDB()
+		if err != nil {
+			return err
+		}
+		reverted, err := db.Migrator().Down(cmd.Context(), steps)
+		for _, migration := range reverted {
+			fmt.Printf("Reverted %s\n", migration)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to revert migrations: %w", err)
+		}
+		if len(reverted) == 0 {
+			fmt.Println("No applied migrations")
+		}
+		return nil
+	},
+}
+
+// migrateRedoCmd represents the migrate redo command
+var migrateRedoCmd = &cobra.Command{
+	Use:          "redo",
+	Short:        "Revert the last applied migration and apply it again",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		db, err := newMigrateDB()
+		if err != nil {
+			return err
+		}
+		migration, err := db.Migrator().Redo(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("failed to redo migration: %w", err)
+		}
+		fmt.Printf("Redone %s\n", migration)
+		return nil
+	},
+}
+
+// migrateStatusCmd represents the migrate status command
+var migrateStatusCmd = &cobra.Command{
+	Use:          "status",
+	Short:        "List the migrations and when they were applied",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		db, err := newMigrateDB()
+		if err != nil {
+			return err
+		}
+		statuses, err := db.Migrator().Status(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("failed to get migration status: %w", err)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED")
+		for _, status := range statuses {
+			state := "applied"
+			switch {
+			case status.Unknown:
+				state = "unknown"
+			case status.Modified:
+				state = "modified"
+			case status.AppliedAt == nil:
+				state = "pending"
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", status.Version, status.Name, state, formatTime(status.AppliedAt))
+		}
+		return w.Flush()
+	},
+}
+
+// migrateCreateCmd represents the migrate create command
+var migrateCreateCmd = &cobra.Command{
+	Use:          "create <name>",
+	Short:        "Create the files of a new migration",
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, _ := cmd.Flags().GetString("dir")
+		goMigration, _ := cmd.Flags().GetBool("go")
+		files, err := migrate.Create(dir, args[0], goMigration, time.Now())
+		for _, file := range files {
+			fmt.Printf("Created %s\n", file)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to create migration: %w", err)
+		}
+		return nil
+	},
+}
+
+func newMigrateDB() (database.GormDB, error) {
+	cfg, err := util.GetConfig[config.Config]()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config: %w", err)
+	}
+	db, err := database.NewGormDB(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect database: %w", err)
+	}
+	return db, nil
+}
+
+// checkMigrations fails when the database has pending migrations, which
+// the commands using it must not run without.
+func checkMigrations(ctx context.Context, db database.GormDB) error {
+	pending, err := db.Migrator().Pending(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check migrations: %w", err)
+	}
+	if len(pending) > 0 {
+		names := make([]string, len(pending))
+		for i, migration := range pending {
+			names[i] = migration.String()
+		}
+		return fmt.Errorf("database has %d pending migrations (%s); run migrate up first",
+			len(pending), strings.Join(names, ", "))
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(migrateCmd)
+	migrateCmd.AddCommand(migrateUpCmd)
+	migrateCmd.AddCommand(migrateDownCmd)
+	migrateCmd.AddCommand(migrateRedoCmd)
+	migrateCmd.AddCommand(migrateStatusCmd)
+	migrateCmd.AddCommand(migrateCreateCmd)
+
+	migrateUpCmd.Flags().Int("steps", 0, "Migrations to apply, all pending ones when 0")
+	migrateDownCmd.Flags().Int("steps", 1, "Migrations to revert")
+	migrateCreateCmd.Flags().String("dir", "pkg/database/migrations", "Directory of the migrations")
+	migrateCreateCmd.Flags().Bool("go", false, "Create a Go migration instead of SQL files")
+}
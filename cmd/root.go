@@ -4,6 +4,7 @@ Copyright © 2024 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
+	"crud-customer/util"
 	"os"
 
 	"github.com/spf13/cobra"
@@ -33,14 +34,18 @@ func Execute() {
 	}
 }
 
-func init() {
-	// Here you will define your flags and configuration settings.
-	// Cobra supports persistent flags, which, if defined here,
-	// will be global for your application.
+var (
+	configFile    string
+	configProfile string
+)
 
-	// rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.crud-customer.yaml)")
+func init() {
+	cobra.OnInitialize(func() {
+		util.SetConfigFile(configFile, configProfile)
+	})
 
-	// Cobra also supports local flags, which will only run
-	// when this action is called directly.
+	rootCmd.PersistentFlags().StringVar(&configFile, "config", util.DefaultConfigFile, "config file")
+	rootCmd.PersistentFlags().StringVar(&configProfile, "profile", os.Getenv(util.EnvPrefix+"_PROFILE"),
+		"config profile layered on the config file, e.g. prod reads config.prod.yaml (env "+util.EnvPrefix+"_PROFILE)")
 	rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
 }
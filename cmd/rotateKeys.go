@@ -0,0 +1,63 @@
+/*
+Copyright © 2024 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"crud-customer/config"
+	"crud-customer/internal/repository"
+	"crud-customer/internal/service"
+	"crud-customer/pkg/database"
+	"crud-customer/pkg/fieldcrypt"
+	"crud-customer/util"
+	"fmt"
+	"github.com/spf13/cobra"
+)
+
+// rotateKeysCmd represents the rotate-keys command
+var rotateKeysCmd = &cobra.Command{
+	Use:   "rotate-keys",
+	Short: "Re-encrypt customer PII, outbox payloads and audit diffs with the primary key of the keyring",
+	Long: `Re-encrypt customer PII, outbox payloads and audit diffs with the primary key of the keyring.
+
+To rotate, add a new key to the keyring file, make it the primary, and run
+rotate-keys. Once it has finished the old key can be removed from the keyring.
+It also encrypts rows written before encryption was enabled.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		batchSize, _ := cmd.Flags().GetInt("batch-size")
+		if batchSize < 1 {
+			return fmt.Errorf("invalid batch size: %d", batchSize)
+		}
+
+		cfg, err := util.GetConfig[config.Config]()
+		if err != nil {
+			return fmt.Errorf("failed to get config: %w", err)
+		}
+		if !cfg.Database.Encryption.Enabled {
+			return fmt.Errorf("database.encryption is not enabled")
+		}
+		db, err := database.NewGormDB(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to connect database: %w", err)
+		}
+		if err := checkMigrations(cmd.Context(), db); err != nil {
+			return err
+		}
+
+		keyRotation := service.NewKeyRotation(cfg, repository.NewCustomer(db.GetDB(), cfg), repository.NewOutbox(db.GetDB(), cfg), repository.NewAudit(db.GetDB(), cfg))
+		err = keyRotation.RotateKeys(cmd.Context(), batchSize, func(table string, done int) {
+			fmt.Printf("%s: %d rows re-encrypted\n", table, done)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to rotate keys: %w", err)
+		}
+		fmt.Printf("All rows are encrypted with key %s\n", fieldcrypt.GetKeyring().Primary())
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(rotateKeysCmd)
+
+	rotateKeysCmd.Flags().Int("batch-size", 500, "Rows re-encrypted per transaction")
+}
@@ -5,43 +5,49 @@ package cmd
 
 import (
 	"crud-customer/config"
+	"crud-customer/internal/repository"
+	"crud-customer/internal/service"
 	"crud-customer/pkg/database"
 	"crud-customer/util"
+	"fmt"
+	"github.com/go-faker/faker/v4"
 	"github.com/spf13/cobra"
+	"math/rand"
 )
 
+// seedCustomers is the number of customers the seed command creates.
+const seedCustomers = 10
+
 // seedCmd represents the seed command
 var seedCmd = &cobra.Command{
 	Use:   "seed",
 	Short: "seed will seed the database with some data",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		cfg, err := util.GetConfig[config.Config]()
 		if err != nil {
-			panic("failed to get config")
+			return fmt.Errorf("failed to get config: %w", err)
 		}
 
 		db, err := database.NewGormDB(cfg)
-
 		if err != nil {
-			panic("failed to connect database")
+			return fmt.Errorf("failed to connect database: %w", err)
+		}
+		if err := checkMigrations(cmd.Context(), db); err != nil {
+			return err
 		}
 
-		if err := db.Seed(); err != nil {
-			panic("failed to seed")
+		// Customers are created through the service, so they get their
+		// audit entries and outbox events like any other.
+		customerService := service.NewCustomer(cfg, repository.NewCustomer(db.GetDB(), cfg))
+		for i := 0; i < seedCustomers; i++ {
+			if _, err := customerService.CreateCustomer(cmd.Context(), faker.Name(), uint(rand.Intn(100)+1)); err != nil {
+				return fmt.Errorf("failed to seed: %w", err)
+			}
 		}
+		return nil
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(seedCmd)
-
-	// Here you will define your flags and configuration settings.
-
-	// Cobra supports Persistent Flags which will work for this command
-	// and all subcommands, e.g.:
-	// seedCmd.PersistentFlags().String("foo", "", "A help for foo")
-
-	// Cobra supports local flags which will only run when this command
-	// is called directly, e.g.:
-	// seedCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
 }
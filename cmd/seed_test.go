@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"crud-customer/internal/entity"
+	"crud-customer/util/testhelper"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/suite"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type SeedCmdTestSuite struct {
+	suite.Suite
+	dbFile string
+	file   string
+}
+
+func (s *SeedCmdTestSuite) SetupTest() {
+	viper.Reset()
+	dir := s.T().TempDir()
+	s.dbFile = filepath.Join(dir, "customer.db")
+	s.file = filepath.Join(dir, "config.yaml")
+	config := strings.Replace(validConfig, "tmp/customer.db", s.dbFile, 1)
+	s.Require().NoError(os.WriteFile(s.file, []byte(config), 0o600))
+}
+
+func (s *SeedCmdTestSuite) execute(args ...string) error {
+	rootCmd.SetArgs(append(args, "--config", s.file, "--profile", ""))
+	s.T().Cleanup(func() { rootCmd.SetArgs(nil) })
+	return rootCmd.Execute()
+}
+
+func (s *SeedCmdTestSuite) TestSeedRequiresMigrations() {
+	err := s.execute("seed")
+
+	s.ErrorContains(err, "pending migrations")
+}
+
+func (s *SeedCmdTestSuite) TestSeedWritesAuditEntriesAndOutboxEvents() {
+	db := testhelper.NewMigratedDB(s.dbFile)
+
+	s.Require().NoError(s.execute("seed"))
+
+	var customers, entries, events int64
+	s.NoError(db.Model(&entity.Customer{}).Count(&customers).Error)
+	s.NoError(db.Model(&entity.AuditEntry{}).Where("action = ?", entity.AuditActionCreate).Count(&entries).Error)
+	s.NoError(db.Model(&entity.OutboxEvent{}).Where("event_type = ?", entity.EventCustomerCreated).Count(&events).Error)
+	s.Equal(int64(seedCustomers), customers)
+	s.Equal(customers, entries)
+	s.Equal(customers, events)
+}
+
+func TestSeedCmdTestSuite(t *testing.T) {
+	suite.Run(t, new(SeedCmdTestSuite))
+}
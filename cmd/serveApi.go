@@ -7,43 +7,47 @@ import (
 	"crud-customer/config"
 	"crud-customer/internal/http"
 	"crud-customer/pkg/database"
+	"crud-customer/pkg/logging"
 	"crud-customer/pkg/server"
 	"crud-customer/util"
+	"fmt"
 	"github.com/spf13/cobra"
+	"log/slog"
+	"os"
 )
 
 // serveApiCmd represents the serveApi command
 var serveApiCmd = &cobra.Command{
 	Use:   "serveApi",
 	Short: "Start the API server",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
+		logging.Setup(os.Stdout, slog.LevelInfo)
 		cfg, err := util.GetConfig[config.Config]()
 		if err != nil {
-			panic(err)
+			return fmt.Errorf("failed to get config: %w", err)
 		}
 		db, err := database.NewGormDB(cfg)
 		if err != nil {
-			panic(err)
+			return fmt.Errorf("failed to connect database: %w", err)
 		}
-		if err := db.AutoMigrate(); err != nil {
-			panic("failed to automigrate")
+		if applyMigrations, _ := cmd.Flags().GetBool("migrate"); applyMigrations {
+			if _, err := db.Migrator().Up(cmd.Context(), 0); err != nil {
+				return fmt.Errorf("failed to migrate: %w", err)
+			}
+		} else if err := checkMigrations(cmd.Context(), db); err != nil {
+			return err
+		}
+		serv, err := server.NewServer(cfg)
+		if err != nil {
+			return err
 		}
-		serv := server.NewServer(cfg)
 		app := http.NewApp(cfg, db, serv)
-		app.Start()
+		return app.Start()
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(serveApiCmd)
 
-	// Here you will define your flags and configuration settings.
-
-	// Cobra supports Persistent Flags which will work for this command
-	// and all subcommands, e.g.:
-	// serveApiCmd.PersistentFlags().String("foo", "", "A help for foo")
-
-	// Cobra supports local flags which will only run when this command
-	// is called directly, e.g.:
-	// serveApiCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	serveApiCmd.Flags().Bool("migrate", false, "Apply pending migrations before starting instead of refusing to start")
 }
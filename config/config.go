@@ -6,17 +6,173 @@ type (
 	Config struct {
 		Database DatabaseConfig
 		Server   ServerConfig
+		Outbox   OutboxConfig
+		Metrics  MetricsConfig
+		Tracing  TracingConfig
+		Admin    AdminConfig
+	}
+
+	// AdminConfig serves the admin endpoints, which have no authentication,
+	// on a listener of its own bound to localhost by default.
+	AdminConfig struct {
+		Enabled bool   `mapstructure:"enabled" default:"true"`
+		Address string `mapstructure:"address" default:"127.0.0.1:6060" validate:"required_if=Enabled true"`
+	}
+
+	TracingConfig struct {
+		Enabled     bool   `mapstructure:"enabled" default:"false"`
+		ServiceName string `mapstructure:"serviceName" default:"crud-customer" validate:"required"`
+		Exporter    string `mapstructure:"exporter" default:"stdout" validate:"required,oneof=otlp stdout file"`
+		// Endpoint is the host:port of the OTLP/HTTP collector; empty uses
+		// OTEL_EXPORTER_OTLP_ENDPOINT or localhost:4318.
+		Endpoint    string  `mapstructure:"endpoint"`
+		Insecure    bool    `mapstructure:"insecure" default:"false"`
+		File        string  `mapstructure:"file" default:"tmp/traces.ndjson" validate:"required"`
+		SampleRatio float64 `mapstructure:"sampleRatio" default:"1" validate:"min=0,max=1"`
+	}
+
+	// MetricsConfig serves Prometheus metrics on a listener of its own, so
+	// they are not exposed with the API.
+	MetricsConfig struct {
+		Enabled bool   `mapstructure:"enabled" default:"true"`
+		Address string `mapstructure:"address" default:":9090" validate:"required_if=Enabled true"`
+		Path    string `mapstructure:"path" default:"/metrics" validate:"required_if=Enabled true,omitempty,startswith=/"`
 	}
 
 	DatabaseConfig struct {
-		File string `mapstructure:"file" validate:"required"`
+		File       string           `mapstructure:"file" example:"tmp/customer.db" validate:"required"`
+		Encryption EncryptionConfig `mapstructure:"encryption"`
+		Migrations MigrationsConfig `mapstructure:"migrations"`
+	}
+
+	MigrationsConfig struct {
+		// LockTimeout bounds the wait for the migration lock held by another
+		// instance migrating the database.
+		LockTimeout time.Duration `mapstructure:"lockTimeout" default:"1m" validate:"required"`
+		// LockExpiry is the age after which the lock of an instance that
+		// stopped while migrating is taken over. It must exceed the longest
+		// migration.
+		LockExpiry time.Duration `mapstructure:"lockExpiry" default:"10m" validate:"required"`
+	}
+
+	EncryptionConfig struct {
+		Enabled     bool   `mapstructure:"enabled" default:"false"`
+		KeyringFile string `mapstructure:"keyringFile" validate:"required_if=Enabled true"`
 	}
 
 	ServerConfig struct {
-		Port         int           `mapstructure:"port" validate:"required"`
-		AllowOrigins []string      `mapstructure:"allowOrigins" validate:"required"`
-		BodyLimit    string        `mapstructure:"bodyLimit" validate:"required"`
-		Timeout      time.Duration `mapstructure:"timeout" validate:"required"`
-		LogLevel     string        `mapstructure:"logLevel" validate:"required"`
+		Port          int                 `mapstructure:"port" example:"8080" validate:"required"`
+		AllowOrigins  []string            `mapstructure:"allowOrigins" example:"*" validate:"required"`
+		BodyLimit     string              `mapstructure:"bodyLimit" example:"10M" validate:"required,bytesize"`
+		Timeout       time.Duration       `mapstructure:"timeout" example:"30" validate:"required"`
+		LogLevel      string              `mapstructure:"logLevel" example:"INFO" validate:"required,loglevel"`
+		MaxBatchSize  int                 `mapstructure:"maxBatchSize" default:"100" validate:"required,min=1"`
+		APIKey        APIKeyConfig        `mapstructure:"apiKey"`
+		JWT           JWTConfig           `mapstructure:"jwt"`
+		RBAC          RBACConfig          `mapstructure:"rbac"`
+		Tenant        TenantConfig        `mapstructure:"tenant"`
+		RateLimit     RateLimitConfig     `mapstructure:"rateLimit"`
+		TLS           TLSConfig           `mapstructure:"tls"`
+		SecureHeaders SecureHeadersConfig `mapstructure:"secureHeaders"`
+		Health        HealthConfig        `mapstructure:"health"`
+		Shutdown      ShutdownConfig      `mapstructure:"shutdown"`
+		// TrustedProxies lists the CIDRs of the proxies whose
+		// X-Forwarded-For header gives the client IP, and whose
+		// X-Forwarded-Proto header tells HTTPS requests. Without any, the
+		// client IP is the address of the connection.
+		TrustedProxies []string `mapstructure:"trustedProxies" validate:"dive,cidr"`
+	}
+
+	ShutdownConfig struct {
+		// DrainTimeout bounds the wait for requests in flight once the
+		// listener is closed; their connections are dropped after it.
+		DrainTimeout time.Duration `mapstructure:"drainTimeout" default:"20s" validate:"required"`
+	}
+
+	HealthConfig struct {
+		// CheckTimeout bounds each readiness check.
+		CheckTimeout time.Duration `mapstructure:"checkTimeout" default:"2s" validate:"required"`
+		// ShutdownDelay keeps serving, with readiness failing, for this long
+		// after a shutdown signal, so load balancers can stop routing first.
+		ShutdownDelay time.Duration `mapstructure:"shutdownDelay" default:"0s" validate:"min=0"`
+	}
+
+	SecureHeadersConfig struct {
+		Enabled bool   `mapstructure:"enabled" default:"true"`
+		Preset  string `mapstructure:"preset" default:"production" validate:"required,oneof=development production"`
+		// Headers overrides headers of the preset by name; an empty value
+		// drops a header.
+		Headers map[string]string `mapstructure:"headers"`
+	}
+
+	TLSConfig struct {
+		Enabled    bool   `mapstructure:"enabled" default:"false"`
+		CertFile   string `mapstructure:"certFile" validate:"required_if=Enabled true"`
+		KeyFile    string `mapstructure:"keyFile" validate:"required_if=Enabled true"`
+		MinVersion string `mapstructure:"minVersion" default:"1.2" validate:"required,oneof=1.2 1.3"`
+		// ClientAuth asks for client certificates, verified against
+		// ClientCAFile, and authenticates requests with them.
+		ClientAuth   string `mapstructure:"clientAuth" default:"none" validate:"required,oneof=none optional require"`
+		ClientCAFile string `mapstructure:"clientCaFile" validate:"required_unless=ClientAuth none"`
+	}
+
+	APIKeyConfig struct {
+		Enabled bool   `mapstructure:"enabled" default:"true"`
+		Header  string `mapstructure:"header" default:"X-API-Key" validate:"required"`
+	}
+
+	JWTConfig struct {
+		Enabled    bool          `mapstructure:"enabled" default:"false"`
+		JWKSFile   string        `mapstructure:"jwksFile" validate:"required_if=Enabled true"`
+		Issuer     string        `mapstructure:"issuer" validate:"required_if=Enabled true"`
+		Audience   string        `mapstructure:"audience" validate:"required_if=Enabled true"`
+		ClockSkew  time.Duration `mapstructure:"clockSkew" default:"30s"`
+		Algorithms []string      `mapstructure:"algorithms" default:"RS256,ES256" validate:"required,dive,oneof=RS256 RS384 RS512 ES256 ES384 ES512"`
+		RoleClaim  string        `mapstructure:"roleClaim" default:"roles" validate:"required"`
+	}
+
+	RBACConfig struct {
+		Enabled bool                `mapstructure:"enabled" default:"false"`
+		Roles   map[string][]string `mapstructure:"roles" validate:"required_if=Enabled true,dive,keys,required,endkeys,required"`
+	}
+
+	TenantConfig struct {
+		Enabled    bool   `mapstructure:"enabled" default:"false"`
+		Header     string `mapstructure:"header" default:"X-Tenant-ID" validate:"required"`
+		Claim      string `mapstructure:"claim" default:"tenant_id" validate:"required"`
+		BaseDomain string `mapstructure:"baseDomain"`
+		Default    string `mapstructure:"default" default:"default"`
+	}
+
+	RateLimitConfig struct {
+		Enabled bool   `mapstructure:"enabled" default:"false"`
+		Store   string `mapstructure:"store" default:"memory" validate:"required"`
+		// Groups holds the limit of each route group; routes of a group
+		// without an entry use the "default" group.
+		Groups map[string]RateLimitRule `mapstructure:"groups" validate:"required_if=Enabled true,dive"`
+	}
+
+	RateLimitRule struct {
+		Requests int           `mapstructure:"requests" validate:"required,min=1"`
+		Period   time.Duration `mapstructure:"period" validate:"required"`
+		Burst    int           `mapstructure:"burst" validate:"min=0"`
+	}
+
+	OutboxConfig struct {
+		Enabled      bool          `mapstructure:"enabled" default:"true"`
+		PollInterval time.Duration `mapstructure:"pollInterval" default:"1s" validate:"required"`
+		BatchSize    int           `mapstructure:"batchSize" default:"100" validate:"required,min=1"`
+		// MaxAttempts dead-letters an event after this many failed
+		// deliveries, which releases the events of its aggregate queued
+		// behind it.
+		MaxAttempts int `mapstructure:"maxAttempts" default:"10" validate:"required,min=1"`
+		// RetryBackoff is the wait after the first failed delivery of an
+		// event; it doubles with every further failure up to MaxRetryBackoff.
+		RetryBackoff    time.Duration `mapstructure:"retryBackoff" default:"1s" validate:"required"`
+		MaxRetryBackoff time.Duration `mapstructure:"maxRetryBackoff" default:"5m" validate:"required,gtefield=RetryBackoff"`
+		Sinks           []string      `mapstructure:"sinks" default:"log" validate:"required,dive,oneof=log file http"`
+		File            string        `mapstructure:"file" default:"tmp/outbox.ndjson" validate:"required"`
+		HTTPURL         string        `mapstructure:"httpUrl" validate:"omitempty,url" secret:"true"`
+		HTTPTimeout     time.Duration `mapstructure:"httpTimeout" default:"5s" validate:"required"`
 	}
 )
@@ -0,0 +1,26 @@
+package entity
+
+import "time"
+
+// APIKey is a hashed API key. The plaintext key is only shown once, when the
+// key is created. A key with a TenantID can only act on that tenant, "*"
+// letting it pick any; a key without one acts on the default tenant.
+type APIKey struct {
+	ID         uint       `json:"id" gorm:"primaryKey;autoIncrement;not null"`
+	Name       string     `json:"name" gorm:"not null"`
+	TenantID   string     `json:"tenant_id" gorm:"not null;default:''"`
+	Prefix     string     `json:"prefix" gorm:"not null;uniqueIndex"`
+	Hash       string     `json:"-" gorm:"not null"`
+	Scopes     []string   `json:"scopes" gorm:"serializer:json"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"not null"`
+}
+
+func (a *APIKey) IsActive(now time.Time) bool {
+	if a.RevokedAt != nil {
+		return false
+	}
+	return a.ExpiresAt == nil || now.Before(*a.ExpiresAt)
+}
@@ -0,0 +1,57 @@
+package entity
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"strconv"
+	"time"
+)
+
+const (
+	AuditActionCreate  = "create"
+	AuditActionUpdate  = "update"
+	AuditActionDelete  = "delete"
+	AuditActionRestore = "restore"
+)
+
+// AuditEntry records one customer mutation. Entries form a hash chain: Hash
+// covers the entry together with the Hash of the entry before it, stored in
+// PrevHash, so editing or removing an entry breaks the chain from there on.
+// Diff is a JSON object mapping each changed field to its before and after
+// values.
+type AuditEntry struct {
+	ID         uint      `json:"id" gorm:"primaryKey;autoIncrement;not null"`
+	TenantID   string    `json:"tenant_id" gorm:"not null;default:default;index:idx_audit_entries_customer,priority:1"`
+	CustomerID uint      `json:"customer_id" gorm:"not null;index:idx_audit_entries_customer,priority:2"`
+	Action     string    `json:"action" gorm:"not null"`
+	Actor      string    `json:"actor" gorm:"not null"`
+	RequestID  string    `json:"request_id" gorm:"not null"`
+	SourceIP   string    `json:"source_ip" gorm:"not null"`
+	Diff       string    `json:"diff" gorm:"not null;serializer:encrypted"`
+	CreatedAt  time.Time `json:"created_at" gorm:"not null"`
+	PrevHash   string    `json:"prev_hash" gorm:"not null"`
+	Hash       string    `json:"hash" gorm:"not null;uniqueIndex"`
+}
+
+// ComputeHash returns the chain hash of the entry. It covers the plaintext
+// Diff, so re-encrypting an entry does not change its hash.
+func (e *AuditEntry) ComputeHash() string {
+	h := sha256.New()
+	for _, field := range []string{
+		e.PrevHash,
+		e.TenantID,
+		strconv.FormatUint(uint64(e.CustomerID), 10),
+		e.Action,
+		e.Actor,
+		e.RequestID,
+		e.SourceIP,
+		e.Diff,
+		e.CreatedAt.UTC().Format(time.RFC3339Nano),
+	} {
+		// Length prefixes keep field boundaries unambiguous.
+		_ = binary.Write(h, binary.BigEndian, uint64(len(field)))
+		h.Write([]byte(field))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -1,11 +1,41 @@
 package entity
 
+import (
+	"crud-customer/pkg/fieldcrypt"
+	"gorm.io/gorm"
+)
+
+// CustomerNameIndexPurpose separates the blind index of customer names from
+// those of other columns.
+const CustomerNameIndexPurpose = "customers.name"
+
+// Customer is stored with Name encrypted when a keyring is configured.
+// NameIndex is its blind index, which equality lookups on Name go through.
+// Deleting a customer only sets DeletedAt, which hides it until restored.
 type Customer struct {
-	ID   uint    `json:"id" gorm:"primaryKey;autoIncrement;not null;index"`
-	Name *string `json:"name" gorm:"not null"`
-	Age  *uint   `json:"age" gorm:"not null"`
+	ID             uint           `json:"id" gorm:"primaryKey;autoIncrement;not null;index"`
+	TenantID       string         `json:"-" gorm:"not null;default:default;index;uniqueIndex:idx_customers_tenant_external,priority:1"`
+	Name           *string        `json:"name" gorm:"not null;serializer:encrypted" redact:"partial"`
+	NameIndex      *string        `json:"-" gorm:"index"`
+	Age            *uint          `json:"age" gorm:"not null"`
+	ExternalSource *string        `json:"external_source,omitempty" gorm:"uniqueIndex:idx_customers_tenant_external,priority:2"`
+	ExternalID     *string        `json:"external_id,omitempty" gorm:"uniqueIndex:idx_customers_tenant_external,priority:3"`
+	DeletedAt      gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// UpdateNameIndex recomputes NameIndex from Name. It must be called
+// whenever Name is written.
+func (c *Customer) UpdateNameIndex() {
+	c.NameIndex = nil
+	if c.Name != nil {
+		c.NameIndex = fieldcrypt.BlindIndex(*c.Name, CustomerNameIndexPurpose)
+	}
 }
 
-func init() {
-	entityList = append(entityList, Customer{})
+// CustomerFilter narrows a customer query. Nil fields are not filtered on.
+type CustomerFilter struct {
+	// Name matches exactly, through the blind index when encrypted.
+	Name   *string
+	MinAge *uint
+	MaxAge *uint
 }
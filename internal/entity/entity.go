@@ -1,7 +0,0 @@
-package entity
-
-var entityList []interface{}
-
-func GetEntityList() []interface{} {
-	return entityList
-}
@@ -0,0 +1,34 @@
+package entity
+
+import "time"
+
+const (
+	AggregateTypeCustomer = "customer"
+
+	EventCustomerCreated  = "customer.created"
+	EventCustomerUpdated  = "customer.updated"
+	EventCustomerDeleted  = "customer.deleted"
+	EventCustomerRestored = "customer.restored"
+)
+
+// OutboxEvent is a domain event written in the same transaction as the
+// mutation that produced it. The outbox relay dispatches pending rows to the
+// configured sinks and marks them dispatched once every sink has accepted them.
+// A failed event is retried from NextAttemptAt, and dead-lettered once it
+// has failed too often.
+type OutboxEvent struct {
+	ID            uint       `json:"id" gorm:"primaryKey;autoIncrement;not null"`
+	TenantID      string     `json:"tenant_id" gorm:"not null;default:default"`
+	AggregateType string     `json:"aggregate_type" gorm:"not null;index:idx_outbox_events_aggregate"`
+	AggregateID   uint       `json:"aggregate_id" gorm:"not null;index:idx_outbox_events_aggregate"`
+	EventType     string     `json:"event_type" gorm:"not null"`
+	Payload       string     `json:"payload" gorm:"not null;serializer:encrypted"`
+	CreatedAt     time.Time  `json:"created_at" gorm:"not null"`
+	DispatchedAt  *time.Time `json:"dispatched_at" gorm:"index"`
+	Attempts      uint       `json:"attempts" gorm:"not null;default:0"`
+	LastError     *string    `json:"last_error"`
+	// NextAttemptAt holds back a failed event, and the events of its
+	// aggregate after it, until then.
+	NextAttemptAt  *time.Time `json:"next_attempt_at" gorm:"index"`
+	DeadLetteredAt *time.Time `json:"dead_lettered_at" gorm:"index"`
+}
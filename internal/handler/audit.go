@@ -0,0 +1,7 @@
+package handler
+
+import "github.com/labstack/echo/v4"
+
+type Audit interface {
+	GetAuditEntries(c echo.Context) error
+}
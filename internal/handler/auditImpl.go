@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"crud-customer/config"
+	"crud-customer/internal/entity"
+	"crud-customer/internal/service"
+	"crud-customer/pkg/redact"
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+type auditImpl struct {
+	auditService service.Audit
+	cfg          *config.Config
+}
+
+func (a *auditImpl) GetAuditEntries(c echo.Context) error {
+	cd, err := negotiate(c)
+	if err != nil {
+		return err
+	}
+
+	req := new(GetAuditEntriesRequest)
+	if err := c.Bind(req); err != nil {
+		return NewBindingErrorResponse(err)
+	}
+	if err := c.Validate(req); err != nil {
+		return NewBindingErrorResponse(err)
+	}
+
+	entries, err := a.auditService.GetAuditEntriesByCustomerID(c.Request().Context(), req.CustomerID)
+	if err != nil {
+		return NewErrorResponse(http.StatusInternalServerError, fmt.Sprintf("error getting audit entries: %v", err))
+	}
+
+	// Diffs quote customer fields, which render cannot see into.
+	masking := redact.MaskingFromContext(c.Request().Context())
+	data := []AuditEntryData{}
+	for _, entry := range entries {
+		diff := entry.Diff
+		if masking {
+			diff = redact.MaskDiff(diff, entity.Customer{})
+		}
+		data = append(data, AuditEntryData{
+			ID:         entry.ID,
+			CustomerID: entry.CustomerID,
+			Action:     entry.Action,
+			Actor:      entry.Actor,
+			RequestID:  entry.RequestID,
+			SourceIP:   entry.SourceIP,
+			Diff:       AuditDiff(diff),
+			CreatedAt:  entry.CreatedAt,
+			PrevHash:   entry.PrevHash,
+			Hash:       entry.Hash,
+		})
+	}
+
+	resp := &GetAuditEntriesResponse{
+		Success: true,
+		Data:    data,
+		Message: "audit entries found",
+	}
+
+	return render(c, cd, http.StatusOK, resp)
+}
+
+func NewAudit(cfg *config.Config, auditService service.Audit) Audit {
+	return &auditImpl{
+		auditService: auditService,
+		cfg:          cfg,
+	}
+}
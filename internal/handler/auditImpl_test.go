@@ -0,0 +1,185 @@
+package handler
+
+import (
+	"crud-customer/config"
+	"crud-customer/internal/entity"
+	mockservice "crud-customer/mocks/internal_/service"
+	"crud-customer/pkg/redact"
+	"crud-customer/util/validator"
+	"encoding/json"
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func Test_auditImpl_GetAuditEntries(t *testing.T) {
+	entry := &entity.AuditEntry{
+		ID:         1,
+		CustomerID: 7,
+		Action:     entity.AuditActionUpdate,
+		Actor:      "api_key:1",
+		RequestID:  "req-1",
+		SourceIP:   "10.0.0.1",
+		Diff:       `{"age":{"before":20,"after":21}}`,
+		CreatedAt:  time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		PrevHash:   "",
+		Hash:       "abc",
+	}
+	renamed := *entry
+	renamed.Diff = `{"age":{"before":20,"after":21},"name":{"before":"John Doe","after":"Jane Roe"}}`
+	testCases := []struct {
+		name       string
+		query      string
+		accept     string
+		masking    bool
+		setupFunc  func(auditService *mockservice.Audit)
+		wantStatus int
+		wantResp   string
+		wantErr    assert.ErrorAssertionFunc
+	}{
+		{
+			name:  "success",
+			query: "?customer_id=7",
+			setupFunc: func(auditService *mockservice.Audit) {
+				auditService.EXPECT().GetAuditEntriesByCustomerID(mock.Anything, uint(7)).Return([]*entity.AuditEntry{entry}, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantResp: `{"success":true,"data":[{"id":1,"customer_id":7,"action":"update","actor":"api_key:1","request_id":"req-1",` +
+				`"source_ip":"10.0.0.1","diff":{"age":{"before":20,"after":21}},"created_at":"2024-01-01T00:00:00Z","prev_hash":"","hash":"abc"}],` +
+				`"message":"audit entries found"}`,
+			wantErr: assert.NoError,
+		},
+		{
+			name:  "success as csv",
+			query: "?customer_id=7",
+			setupFunc: func(auditService *mockservice.Audit) {
+				auditService.EXPECT().GetAuditEntriesByCustomerID(mock.Anything, uint(7)).Return([]*entity.AuditEntry{entry}, nil)
+			},
+			accept:     "text/csv",
+			wantStatus: http.StatusOK,
+			wantResp: "id,customer_id,action,actor,request_id,source_ip,diff,created_at,prev_hash,hash\n" +
+				`1,7,update,api_key:1,req-1,10.0.0.1,"{""age"":{""before"":20,""after"":21}}",2024-01-01 00:00:00 +0000 UTC,,abc` + "\n",
+			wantErr: assert.NoError,
+		},
+		{
+			name:  "success with names",
+			query: "?customer_id=7",
+			setupFunc: func(auditService *mockservice.Audit) {
+				auditService.EXPECT().GetAuditEntriesByCustomerID(mock.Anything, uint(7)).Return([]*entity.AuditEntry{&renamed}, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantResp: `{"success":true,"data":[{"id":1,"customer_id":7,"action":"update","actor":"api_key:1","request_id":"req-1",` +
+				`"source_ip":"10.0.0.1","diff":{"age":{"before":20,"after":21},"name":{"before":"John Doe","after":"Jane Roe"}},` +
+				`"created_at":"2024-01-01T00:00:00Z","prev_hash":"","hash":"abc"}],"message":"audit entries found"}`,
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "success masked",
+			query:   "?customer_id=7",
+			masking: true,
+			setupFunc: func(auditService *mockservice.Audit) {
+				auditService.EXPECT().GetAuditEntriesByCustomerID(mock.Anything, uint(7)).Return([]*entity.AuditEntry{&renamed}, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantResp: `{"success":true,"data":[{"id":1,"customer_id":7,"action":"update","actor":"api_key:1","request_id":"req-1",` +
+				`"source_ip":"10.0.0.1","diff":{"age":{"before":20,"after":21},"name":{"before":"J*** D***","after":"J*** R***"}},` +
+				`"created_at":"2024-01-01T00:00:00Z","prev_hash":"","hash":"abc"}],"message":"audit entries found"}`,
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "success masked as csv",
+			query:   "?customer_id=7",
+			accept:  "text/csv",
+			masking: true,
+			setupFunc: func(auditService *mockservice.Audit) {
+				auditService.EXPECT().GetAuditEntriesByCustomerID(mock.Anything, uint(7)).Return([]*entity.AuditEntry{&renamed}, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantResp: "id,customer_id,action,actor,request_id,source_ip,diff,created_at,prev_hash,hash\n" +
+				`1,7,update,api_key:1,req-1,10.0.0.1,"{""age"":{""after"":21,""before"":20},""name"":{""after"":""J*** R***"",""before"":""J*** D***""}}",` +
+				"2024-01-01 00:00:00 +0000 UTC,,abc\n",
+			wantErr: assert.NoError,
+		},
+		{
+			name:  "no entries",
+			query: "?customer_id=8",
+			setupFunc: func(auditService *mockservice.Audit) {
+				auditService.EXPECT().GetAuditEntriesByCustomerID(mock.Anything, uint(8)).Return(nil, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantResp:   `{"success":true,"data":[],"message":"audit entries found"}`,
+			wantErr:    assert.NoError,
+		},
+		{
+			name:       "Cannot validate request",
+			query:      "",
+			setupFunc:  func(auditService *mockservice.Audit) {},
+			wantStatus: http.StatusBadRequest,
+			wantResp:   `{"message":"Key: 'GetAuditEntriesRequest.CustomerID' Error:Field validation for 'CustomerID' failed on the 'required' tag", "status_code":400, "success":false}`,
+			wantErr:    assert.Error,
+		},
+		{
+			name:  "service error",
+			query: "?customer_id=7",
+			setupFunc: func(auditService *mockservice.Audit) {
+				auditService.EXPECT().GetAuditEntriesByCustomerID(mock.Anything, uint(7)).Return(nil, fmt.Errorf("internal error"))
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantResp:   `{"message":"error getting audit entries: internal error", "status_code":500, "success":false}`,
+			wantErr:    assert.Error,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			auditService := mockservice.NewAudit(t)
+			tt.setupFunc(auditService)
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/audit"+tt.query, nil)
+			if tt.accept != "" {
+				req.Header.Set(echo.HeaderAccept, tt.accept)
+			}
+			if tt.masking {
+				req = req.WithContext(redact.WithMasking(req.Context()))
+			}
+			app := echo.New()
+			app.Validator = validator.GetEchoValidator()
+			c := app.NewContext(req, rec)
+
+			err := NewAudit(&config.Config{}, auditService).GetAuditEntries(c)
+			if tt.wantErr(t, err) {
+				if err == nil {
+					assert.Equal(t, tt.wantStatus, rec.Code)
+					if tt.accept == "" {
+						assert.JSONEq(t, tt.wantResp, rec.Body.String())
+					} else {
+						assert.Equal(t, tt.wantResp, rec.Body.String())
+					}
+				} else {
+					httpErr := err.(*echo.HTTPError)
+					assert.Equal(t, tt.wantStatus, httpErr.Code)
+					jsonRes, err := json.Marshal(httpErr.Message.(*ErrorResponse))
+					assert.NoError(t, err)
+					assert.JSONEq(t, tt.wantResp, string(jsonRes))
+				}
+			}
+		})
+	}
+}
+
+func TestNewAudit(t *testing.T) {
+	cfg := &config.Config{}
+	auditService := mockservice.NewAudit(t)
+
+	want := &auditImpl{
+		auditService: auditService,
+		cfg:          cfg,
+	}
+
+	got := NewAudit(cfg, auditService)
+	assert.Equal(t, want, got)
+}
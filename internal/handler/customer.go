@@ -6,6 +6,10 @@ type Customer interface {
 	CreateCustomer(c echo.Context) error
 	UpdateCustomer(c echo.Context) error
 	GetCustomerByID(c echo.Context) error
+	BatchGetCustomers(c echo.Context) error
 	DeleteCustomer(c echo.Context) error
+	RestoreCustomer(c echo.Context) error
 	GetAllCustomer(c echo.Context) error
+	UpsertCustomerByExternalID(c echo.Context) error
+	ExportCustomers(c echo.Context) error
 }
@@ -4,17 +4,33 @@ import (
 	"crud-customer/config"
 	"crud-customer/internal/entity"
 	"crud-customer/internal/service"
+	"crud-customer/pkg/codec"
+	"crud-customer/pkg/redact"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/labstack/echo/v4"
+	"log/slog"
 	"net/http"
+	"strconv"
+	"strings"
 )
 
+// exportFlushEvery is the number of exported rows written between flushes.
+const exportFlushEvery = 500
+
 type customerImpl struct {
 	customerService service.Customer
 	cfg             *config.Config
 }
 
 func (cu *customerImpl) CreateCustomer(c echo.Context) error {
+	cd, err := negotiate(c)
+	if err != nil {
+		return err
+	}
+
 	req := new(CreateCustomerRequest)
 	if err := c.Bind(req); err != nil {
 		return NewBindingErrorResponse(err)
@@ -25,7 +41,7 @@ func (cu *customerImpl) CreateCustomer(c echo.Context) error {
 
 	customer, err := cu.customerService.CreateCustomer(c.Request().Context(), req.Name, req.Age)
 	if err != nil {
-		return NewErrorResponse(http.StatusInternalServerError, fmt.Sprintf("error creating customer: %v", err))
+		return NewErrorResponse(http.StatusInternalServerError, redact.Scrub(fmt.Sprintf("error creating customer: %v", err), req))
 	}
 
 	resp := &CreateUpdateCustomerResponse{
@@ -38,10 +54,15 @@ func (cu *customerImpl) CreateCustomer(c echo.Context) error {
 		},
 	}
 
-	return c.JSON(http.StatusCreated, resp)
+	return render(c, cd, http.StatusCreated, resp)
 }
 
 func (cu *customerImpl) UpdateCustomer(c echo.Context) error {
+	cd, err := negotiate(c)
+	if err != nil {
+		return err
+	}
+
 	req := new(UpdateCustomerRequest)
 	if err := c.Bind(req); err != nil {
 		return NewBindingErrorResponse(err)
@@ -50,7 +71,7 @@ func (cu *customerImpl) UpdateCustomer(c echo.Context) error {
 		return NewBindingErrorResponse(err)
 	}
 
-	_, err := cu.customerService.GetCustomerByID(c.Request().Context(), req.ID)
+	_, err = cu.customerService.GetCustomerByID(c.Request().Context(), req.ID)
 	if err != nil {
 		return NewErrorResponse(http.StatusNotFound, fmt.Sprintf("customer not found: %v", err))
 	}
@@ -61,7 +82,7 @@ func (cu *customerImpl) UpdateCustomer(c echo.Context) error {
 	})
 
 	if err != nil {
-		return NewErrorResponse(http.StatusInternalServerError, fmt.Sprintf("error updating customer: %v", err))
+		return NewErrorResponse(http.StatusInternalServerError, redact.Scrub(fmt.Sprintf("error updating customer: %v", err), req))
 	}
 
 	resp := &CreateUpdateCustomerResponse{
@@ -74,10 +95,15 @@ func (cu *customerImpl) UpdateCustomer(c echo.Context) error {
 		},
 	}
 
-	return c.JSON(http.StatusOK, resp)
+	return render(c, cd, http.StatusOK, resp)
 }
 
 func (cu *customerImpl) DeleteCustomer(c echo.Context) error {
+	cd, err := negotiate(c)
+	if err != nil {
+		return err
+	}
+
 	req := new(DeleteCustomerRequest)
 	if err := c.Bind(req); err != nil {
 		return NewBindingErrorResponse(err)
@@ -86,7 +112,7 @@ func (cu *customerImpl) DeleteCustomer(c echo.Context) error {
 		return NewErrorResponse(http.StatusBadRequest, fmt.Sprintf("error validating request: %v", err))
 	}
 
-	_, err := cu.customerService.GetCustomerByID(c.Request().Context(), req.ID)
+	_, err = cu.customerService.GetCustomerByID(c.Request().Context(), req.ID)
 	if err != nil {
 		return NewErrorResponse(http.StatusNotFound, fmt.Sprintf("customer not found: %v", err))
 	}
@@ -101,10 +127,50 @@ func (cu *customerImpl) DeleteCustomer(c echo.Context) error {
 		Message: "customer deleted successfully",
 	}
 
-	return c.JSON(http.StatusOK, resp)
+	return render(c, cd, http.StatusOK, resp)
+}
+
+func (cu *customerImpl) RestoreCustomer(c echo.Context) error {
+	cd, err := negotiate(c)
+	if err != nil {
+		return err
+	}
+
+	req := new(RestoreCustomerRequest)
+	if err := c.Bind(req); err != nil {
+		return NewBindingErrorResponse(err)
+	}
+	if err := c.Validate(req); err != nil {
+		return NewErrorResponse(http.StatusBadRequest, fmt.Sprintf("error validating request: %v", err))
+	}
+
+	customer, err := cu.customerService.RestoreCustomer(c.Request().Context(), req.ID)
+	if errors.Is(err, service.ErrCustomerNotFound) {
+		return NewErrorResponse(http.StatusNotFound, fmt.Sprintf("deleted customer not found: %v", err))
+	}
+	if err != nil {
+		return NewErrorResponse(http.StatusInternalServerError, fmt.Sprintf("error restoring customer: %v", err))
+	}
+
+	resp := &CreateUpdateCustomerResponse{
+		Success: true,
+		Message: "customer restored successfully",
+		Data: CustomerData{
+			ID:   customer.ID,
+			Name: *customer.Name,
+			Age:  *customer.Age,
+		},
+	}
+
+	return render(c, cd, http.StatusOK, resp)
 }
 
 func (cu *customerImpl) GetCustomerByID(c echo.Context) error {
+	cd, err := negotiate(c)
+	if err != nil {
+		return err
+	}
+
 	req := new(GetCustomerByIDRequest)
 	if err := c.Bind(req); err != nil {
 		return NewBindingErrorResponse(err)
@@ -128,10 +194,66 @@ func (cu *customerImpl) GetCustomerByID(c echo.Context) error {
 		},
 	}
 
-	return c.JSON(http.StatusOK, resp)
+	return render(c, cd, http.StatusOK, resp)
+}
+
+func (cu *customerImpl) BatchGetCustomers(c echo.Context) error {
+	cd, err := negotiate(c)
+	if err != nil {
+		return err
+	}
+
+	req := new(BatchGetCustomersRequest)
+	if err := c.Bind(req); err != nil {
+		return NewBindingErrorResponse(err)
+	}
+	if err := c.Validate(req); err != nil {
+		return NewBindingErrorResponse(err)
+	}
+
+	parts := strings.Split(req.IDs, ",")
+	if len(parts) > cu.cfg.Server.MaxBatchSize {
+		return NewErrorResponse(http.StatusBadRequest, fmt.Sprintf("too many ids: %d, maximum is %d", len(parts), cu.cfg.Server.MaxBatchSize))
+	}
+	ids := make([]uint, 0, len(parts))
+	for _, part := range parts {
+		id, err := strconv.ParseUint(strings.TrimSpace(part), 10, 0)
+		if err != nil || id == 0 {
+			return NewErrorResponse(http.StatusBadRequest, fmt.Sprintf("invalid id: %q", part))
+		}
+		ids = append(ids, uint(id))
+	}
+
+	customers, missing, err := cu.customerService.BatchGetCustomers(c.Request().Context(), ids)
+	if err != nil {
+		return NewErrorResponse(http.StatusInternalServerError, fmt.Sprintf("error getting customers: %v", err))
+	}
+
+	data := make([]CustomerData, 0, len(customers))
+	for _, customer := range customers {
+		data = append(data, CustomerData{
+			ID:   customer.ID,
+			Name: *customer.Name,
+			Age:  *customer.Age,
+		})
+	}
+
+	resp := &BatchGetCustomersResponse{
+		Success:    true,
+		Data:       data,
+		MissingIDs: missing,
+		Message:    "customers found",
+	}
+
+	return render(c, cd, http.StatusOK, resp)
 }
 
 func (cu *customerImpl) GetAllCustomer(c echo.Context) error {
+	cd, err := negotiate(c)
+	if err != nil {
+		return err
+	}
+
 	customers, err := cu.customerService.GetAllCustomer(c.Request().Context())
 	if err != nil {
 		return NewErrorResponse(http.StatusInternalServerError, fmt.Sprintf("error getting all customers: %v", err))
@@ -152,7 +274,120 @@ func (cu *customerImpl) GetAllCustomer(c echo.Context) error {
 		Message: "customers found",
 	}
 
-	return c.JSON(http.StatusOK, resp)
+	return render(c, cd, http.StatusOK, resp)
+}
+
+func (cu *customerImpl) UpsertCustomerByExternalID(c echo.Context) error {
+	cd, err := negotiate(c)
+	if err != nil {
+		return err
+	}
+
+	req := new(UpsertCustomerByExternalIDRequest)
+	if err := c.Bind(req); err != nil {
+		return NewBindingErrorResponse(err)
+	}
+	if err := c.Validate(req); err != nil {
+		return NewBindingErrorResponse(err)
+	}
+
+	customer, created, err := cu.customerService.UpsertCustomerByExternalID(c.Request().Context(), req.Source, req.ExternalID, req.Name, req.Age)
+	if errors.Is(err, service.ErrCustomerDeleted) {
+		return NewErrorResponse(http.StatusConflict, "customer with this external ID is deleted, restore it first")
+	}
+	if err != nil {
+		return NewErrorResponse(http.StatusInternalServerError, redact.Scrub(fmt.Sprintf("error upserting customer: %v", err), req))
+	}
+
+	resp := &UpsertCustomerResponse{
+		Success: true,
+		Message: "customer updated successfully",
+		Created: created,
+		Data: CustomerData{
+			ID:   customer.ID,
+			Name: *customer.Name,
+			Age:  *customer.Age,
+		},
+	}
+	status := http.StatusOK
+	if created {
+		resp.Message = "customer created successfully"
+		status = http.StatusCreated
+	}
+
+	return render(c, cd, status, resp)
+}
+
+func (cu *customerImpl) ExportCustomers(c echo.Context) error {
+	req := new(ExportCustomersRequest)
+	if err := c.Bind(req); err != nil {
+		return NewBindingErrorResponse(err)
+	}
+	if err := c.Validate(req); err != nil {
+		return NewBindingErrorResponse(err)
+	}
+
+	res := c.Response()
+	var writeRow func(data CustomerData) error
+	var flush func() error
+	if req.Format == "csv" {
+		writer := csv.NewWriter(res)
+		res.Header().Set(echo.HeaderContentType, "text/csv")
+		res.Header().Set(echo.HeaderContentDisposition, `attachment; filename="customers.csv"`)
+		writeRow = func(data CustomerData) error {
+			return writer.Write([]string{strconv.FormatUint(uint64(data.ID), 10), codec.EscapeCSVFormula(data.Name), strconv.FormatUint(uint64(data.Age), 10)})
+		}
+		flush = func() error {
+			writer.Flush()
+			res.Flush()
+			return writer.Error()
+		}
+		if err := writer.Write([]string{"id", "name", "age"}); err != nil {
+			return NewErrorResponse(http.StatusInternalServerError, fmt.Sprintf("error exporting customers: %v", err))
+		}
+	} else {
+		encoder := json.NewEncoder(res)
+		res.Header().Set(echo.HeaderContentType, "application/x-ndjson")
+		res.Header().Set(echo.HeaderContentDisposition, `attachment; filename="customers.ndjson"`)
+		writeRow = func(data CustomerData) error {
+			return encoder.Encode(data)
+		}
+		flush = func() error {
+			res.Flush()
+			return nil
+		}
+	}
+
+	count := 0
+	masking := redact.MaskingFromContext(c.Request().Context())
+	filter := entity.CustomerFilter{Name: req.Name, MinAge: req.MinAge, MaxAge: req.MaxAge}
+	err := cu.customerService.ExportCustomers(c.Request().Context(), filter, func(customer *entity.Customer) error {
+		data := CustomerData{ID: customer.ID, Name: *customer.Name, Age: *customer.Age}
+		if masking {
+			redact.Apply(&data)
+		}
+		if err := writeRow(data); err != nil {
+			return err
+		}
+		count++
+		if count%exportFlushEvery == 0 {
+			return flush()
+		}
+		return nil
+	})
+	if err != nil {
+		if !res.Committed {
+			return NewErrorResponse(http.StatusInternalServerError, redact.Scrub(fmt.Sprintf("error exporting customers: %v", err), req))
+		}
+		// The status line is already on the wire; all we can do is cut the
+		// stream short and record why.
+		slog.ErrorContext(c.Request().Context(), "error exporting customers", "rows", count, "error", err)
+		return nil
+	}
+	if err := flush(); err != nil {
+		slog.ErrorContext(c.Request().Context(), "error exporting customers", "rows", count, "error", err)
+	}
+	return nil
 }
 
 func NewCustomer(cfg *config.Config, customerService service.Customer) Customer {
@@ -1,10 +1,14 @@
 package handler
 
 import (
+	"bytes"
+	"context"
 	"crud-customer/config"
 	"crud-customer/internal/entity"
 	"crud-customer/internal/service"
 	mockservice "crud-customer/mocks/internal_/service"
+	"crud-customer/pkg/codec"
+	"crud-customer/pkg/redact"
 	"crud-customer/util/typehelper"
 	"crud-customer/util/validator"
 	"encoding/json"
@@ -411,6 +415,88 @@ func Test_customerImpl_DeleteCustomer(t *testing.T) {
 	}
 }
 
+func Test_customerImpl_RestoreCustomer(t *testing.T) {
+	testCases := []struct {
+		name       string
+		id         string
+		setupFunc  func(customerService *mockservice.Customer)
+		wantStatus int
+		wantResp   string
+		wantErr    assert.ErrorAssertionFunc
+	}{
+		{
+			name: "success",
+			id:   "1",
+			setupFunc: func(customerService *mockservice.Customer) {
+				customerService.EXPECT().RestoreCustomer(mock.Anything, uint(1)).Return(&entity.Customer{
+					ID:   1,
+					Name: typehelper.GetPointer("test"),
+					Age:  typehelper.GetPointer(uint(20)),
+				}, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantResp:   `{"success":true,"message":"customer restored successfully","data":{"id":1,"name":"test","age":20}}`,
+			wantErr:    assert.NoError,
+		},
+		{
+			name: "Cannot find deleted customer",
+			id:   "1",
+			setupFunc: func(customerService *mockservice.Customer) {
+				customerService.EXPECT().RestoreCustomer(mock.Anything, uint(1)).Return(nil, service.ErrCustomerNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+			wantResp:   `{"message":"deleted customer not found: customer not found", "status_code":404, "success":false}`,
+			wantErr:    assert.Error,
+		},
+		{
+			name: "service error",
+			id:   "1",
+			setupFunc: func(customerService *mockservice.Customer) {
+				customerService.EXPECT().RestoreCustomer(mock.Anything, uint(1)).Return(nil, fmt.Errorf("internal error"))
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantResp:   `{"message":"error restoring customer: internal error", "status_code":500, "success":false}`,
+			wantErr:    assert.Error,
+		},
+		{
+			name:       "Cannot bind request",
+			id:         "asdf",
+			setupFunc:  func(customerService *mockservice.Customer) {},
+			wantStatus: http.StatusBadRequest,
+			wantResp:   `{"message":"strconv.ParseUint: parsing \"asdf\": invalid syntax", "status_code":400, "success":false}`,
+			wantErr:    assert.Error,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			customerService := mockservice.NewCustomer(t)
+			tt.setupFunc(customerService)
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			rec := httptest.NewRecorder()
+			app := echo.New()
+			app.Validator = validator.GetEchoValidator()
+			c := app.NewContext(req, rec)
+			c.SetPath("/customers/:id/restore")
+			c.SetParamNames("id")
+			c.SetParamValues(tt.id)
+
+			err := NewCustomer(&config.Config{}, customerService).RestoreCustomer(c)
+			if tt.wantErr(t, err) {
+				if err == nil {
+					assert.Equal(t, tt.wantStatus, rec.Code)
+					assert.JSONEq(t, tt.wantResp, rec.Body.String())
+				} else {
+					httpErr := err.(*echo.HTTPError)
+					assert.Equal(t, tt.wantStatus, httpErr.Code)
+					jsonRes, err := json.Marshal(httpErr.Message.(*ErrorResponse))
+					assert.NoError(t, err)
+					assert.JSONEq(t, tt.wantResp, string(jsonRes))
+				}
+			}
+		})
+	}
+}
+
 func Test_customerImpl_GetCustomerByID(t *testing.T) {
 	type fields struct {
 		customerService service.Customer
@@ -640,3 +726,503 @@ func TestNewCustomer(t *testing.T) {
 	got := NewCustomer(cfg, customerService)
 	assert.Equalf(t, want, got, "NewCustomer() = %v, want %v", got, want)
 }
+
+func Test_customerImpl_ContentNegotiation(t *testing.T) {
+	customers := []*entity.Customer{
+		{ID: 1, Name: typehelper.GetPointer("test"), Age: typehelper.GetPointer(uint(20))},
+		{ID: 2, Name: typehelper.GetPointer("test, jr"), Age: typehelper.GetPointer(uint(5))},
+	}
+	testCases := []struct {
+		name            string
+		accept          string
+		wantStatus      int
+		wantContentType string
+		wantResp        string
+	}{
+		{
+			name:            "csv",
+			accept:          "text/csv",
+			wantStatus:      http.StatusOK,
+			wantContentType: "text/csv",
+			wantResp:        "id,name,age\n1,test,20\n2,\"test, jr\",5\n",
+		},
+		{
+			name:            "xml preferred by q-value",
+			accept:          "application/json;q=0.5, application/xml",
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/xml",
+			wantResp: `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+				`<GetAllCustomerResponse><success>true</success><data><customer><id>1</id><name>test</name><age>20</age></customer>` +
+				`<customer><id>2</id><name>test, jr</name><age>5</age></customer></data><message>customers found</message></GetAllCustomerResponse>`,
+		},
+		{
+			name:            "wildcard falls back to json",
+			accept:          "*/*",
+			wantStatus:      http.StatusOK,
+			wantContentType: echo.MIMEApplicationJSON,
+			wantResp:        `{"success":true,"data":[{"id":1,"name":"test","age":20},{"id":2,"name":"test, jr","age":5}],"message":"customers found"}` + "\n",
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			customerService := mockservice.NewCustomer(t)
+			customerService.EXPECT().GetAllCustomer(mock.Anything).Return(customers, nil)
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set(echo.HeaderAccept, tt.accept)
+			rec := httptest.NewRecorder()
+			c := echo.New().NewContext(req, rec)
+
+			err := NewCustomer(&config.Config{}, customerService).GetAllCustomer(c)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.Contains(t, rec.Header().Get(echo.HeaderContentType), tt.wantContentType)
+			assert.Equal(t, tt.wantResp, rec.Body.String())
+		})
+	}
+
+	t.Run("msgpack", func(t *testing.T) {
+		customerService := mockservice.NewCustomer(t)
+		customerService.EXPECT().GetAllCustomer(mock.Anything).Return(customers[:1], nil)
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(echo.HeaderAccept, "application/msgpack")
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(req, rec)
+
+		err := NewCustomer(&config.Config{}, customerService).GetAllCustomer(c)
+		assert.NoError(t, err)
+		var got GetAllCustomerResponse
+		assert.NoError(t, (&codec.MsgPackCodec{}).Decode(rec.Body, &got))
+		assert.Equal(t, GetAllCustomerResponse{
+			Success: true,
+			Data:    []CustomerData{{ID: 1, Name: "test", Age: 20}},
+			Message: "customers found",
+		}, got)
+	})
+
+	t.Run("not acceptable", func(t *testing.T) {
+		customerService := mockservice.NewCustomer(t)
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(echo.HeaderAccept, "application/pdf")
+		c := echo.New().NewContext(req, httptest.NewRecorder())
+
+		err := NewCustomer(&config.Config{}, customerService).GetAllCustomer(c)
+		assert.Equal(t, http.StatusNotAcceptable, err.(*echo.HTTPError).Code)
+	})
+}
+
+// Test_customerImpl_NotAcceptable checks that a request whose response
+// cannot be rendered is refused before the service is called, so nothing is
+// changed by a request answered with 406.
+func Test_customerImpl_NotAcceptable(t *testing.T) {
+	testCases := []struct {
+		name    string
+		method  string
+		body    string
+		params  []string
+		values  []string
+		handler func(h Customer) echo.HandlerFunc
+	}{
+		{name: "create", method: http.MethodPost, body: `{"name": "test", "age": 20}`, handler: func(h Customer) echo.HandlerFunc { return h.CreateCustomer }},
+		{name: "update", method: http.MethodPut, body: `{"name": "test", "age": 20}`, params: []string{"id"}, values: []string{"1"}, handler: func(h Customer) echo.HandlerFunc { return h.UpdateCustomer }},
+		{name: "delete", method: http.MethodDelete, params: []string{"id"}, values: []string{"1"}, handler: func(h Customer) echo.HandlerFunc { return h.DeleteCustomer }},
+		{name: "restore", method: http.MethodPost, params: []string{"id"}, values: []string{"1"}, handler: func(h Customer) echo.HandlerFunc { return h.RestoreCustomer }},
+		{name: "upsert", method: http.MethodPut, body: `{"name": "test", "age": 20}`, params: []string{"source", "id"}, values: []string{"crm", "42"}, handler: func(h Customer) echo.HandlerFunc { return h.UpsertCustomerByExternalID }},
+		{name: "get", method: http.MethodGet, params: []string{"id"}, values: []string{"1"}, handler: func(h Customer) echo.HandlerFunc { return h.GetCustomerByID }},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			// The mock fails the test on any call.
+			customerService := mockservice.NewCustomer(t)
+			req := httptest.NewRequest(tt.method, "/", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set(echo.HeaderAccept, "application/pdf")
+			app := echo.New()
+			app.Validator = validator.GetEchoValidator()
+			app.Binder = codec.NewBinder(codec.GetRegistry())
+			c := app.NewContext(req, httptest.NewRecorder())
+			c.SetParamNames(tt.params...)
+			c.SetParamValues(tt.values...)
+
+			err := tt.handler(NewCustomer(&config.Config{}, customerService))(c)
+			assert.Equal(t, http.StatusNotAcceptable, err.(*echo.HTTPError).Code)
+		})
+	}
+}
+
+func Test_customerImpl_CreateCustomer_RequestFormats(t *testing.T) {
+	msgpackBody := new(bytes.Buffer)
+	assert.NoError(t, (&codec.MsgPackCodec{}).Encode(msgpackBody, map[string]interface{}{"name": "test", "age": 20}))
+
+	testCases := []struct {
+		name        string
+		contentType string
+		body        string
+		wantStatus  int
+	}{
+		{name: "msgpack", contentType: "application/msgpack", body: msgpackBody.String(), wantStatus: http.StatusCreated},
+		{name: "csv", contentType: "text/csv", body: "name,age\ntest,20\n", wantStatus: http.StatusCreated},
+		{name: "xml", contentType: echo.MIMEApplicationXML, body: "<customer><name>test</name><age>20</age></customer>", wantStatus: http.StatusCreated},
+		{name: "unsupported", contentType: "application/pdf", body: "%PDF", wantStatus: http.StatusUnsupportedMediaType},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			customerService := mockservice.NewCustomer(t)
+			if tt.wantStatus == http.StatusCreated {
+				customerService.EXPECT().CreateCustomer(mock.Anything, "test", uint(20)).Return(&entity.Customer{
+					ID:   1,
+					Name: typehelper.GetPointer("test"),
+					Age:  typehelper.GetPointer(uint(20)),
+				}, nil)
+			}
+			req := httptest.NewRequest(http.MethodPost, "/customers", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, tt.contentType)
+			rec := httptest.NewRecorder()
+			app := echo.New()
+			app.Validator = validator.GetEchoValidator()
+			app.Binder = codec.NewBinder(codec.GetRegistry())
+			c := app.NewContext(req, rec)
+
+			err := NewCustomer(&config.Config{}, customerService).CreateCustomer(c)
+			if err != nil {
+				assert.Equal(t, tt.wantStatus, err.(*echo.HTTPError).Code)
+				return
+			}
+			assert.Equal(t, tt.wantStatus, rec.Code)
+		})
+	}
+}
+
+func Test_customerImpl_ExportCustomers(t *testing.T) {
+	customers := []*entity.Customer{
+		{ID: 1, Name: typehelper.GetPointer("test"), Age: typehelper.GetPointer(uint(20))},
+		{ID: 2, Name: typehelper.GetPointer("test, jr"), Age: typehelper.GetPointer(uint(5))},
+	}
+	streamCustomers := func(ctx context.Context, filter entity.CustomerFilter, fn func(customer *entity.Customer) error) error {
+		for _, customer := range customers {
+			if err := fn(customer); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	testCases := []struct {
+		name            string
+		query           string
+		setupFunc       func(customerService *mockservice.Customer)
+		wantStatus      int
+		wantContentType string
+		wantResp        string
+		wantErr         assert.ErrorAssertionFunc
+	}{
+		{
+			name:  "ndjson with filters",
+			query: "?name=test&min_age=1&max_age=30",
+			setupFunc: func(customerService *mockservice.Customer) {
+				customerService.EXPECT().ExportCustomers(mock.Anything, entity.CustomerFilter{
+					Name:   typehelper.GetPointer("test"),
+					MinAge: typehelper.GetPointer(uint(1)),
+					MaxAge: typehelper.GetPointer(uint(30)),
+				}, mock.Anything).RunAndReturn(streamCustomers)
+			},
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/x-ndjson",
+			wantResp:        `{"id":1,"name":"test","age":20}` + "\n" + `{"id":2,"name":"test, jr","age":5}` + "\n",
+			wantErr:         assert.NoError,
+		},
+		{
+			name:  "csv",
+			query: "?format=csv",
+			setupFunc: func(customerService *mockservice.Customer) {
+				customerService.EXPECT().ExportCustomers(mock.Anything, entity.CustomerFilter{}, mock.Anything).RunAndReturn(streamCustomers)
+			},
+			wantStatus:      http.StatusOK,
+			wantContentType: "text/csv",
+			wantResp:        "id,name,age\n1,test,20\n2,\"test, jr\",5\n",
+			wantErr:         assert.NoError,
+		},
+		{
+			name:  "csv escapes formulas",
+			query: "?format=csv",
+			setupFunc: func(customerService *mockservice.Customer) {
+				customerService.EXPECT().ExportCustomers(mock.Anything, entity.CustomerFilter{}, mock.Anything).RunAndReturn(
+					func(ctx context.Context, filter entity.CustomerFilter, fn func(customer *entity.Customer) error) error {
+						return fn(&entity.Customer{ID: 1, Name: typehelper.GetPointer("=1+2"), Age: typehelper.GetPointer(uint(20))})
+					})
+			},
+			wantStatus:      http.StatusOK,
+			wantContentType: "text/csv",
+			wantResp:        "id,name,age\n1,'=1+2,20\n",
+			wantErr:         assert.NoError,
+		},
+		{
+			name:       "invalid format",
+			query:      "?format=xlsx",
+			setupFunc:  func(customerService *mockservice.Customer) {},
+			wantStatus: http.StatusBadRequest,
+			wantErr:    assert.Error,
+		},
+		{
+			name:  "service error before first row",
+			query: "?format=csv",
+			setupFunc: func(customerService *mockservice.Customer) {
+				customerService.EXPECT().ExportCustomers(mock.Anything, entity.CustomerFilter{}, mock.Anything).Return(fmt.Errorf("internal error"))
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantErr:    assert.Error,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			customerService := mockservice.NewCustomer(t)
+			tt.setupFunc(customerService)
+			rec := httptest.NewRecorder()
+			app := echo.New()
+			app.Validator = validator.GetEchoValidator()
+			c := app.NewContext(httptest.NewRequest(http.MethodGet, "/customers/export"+tt.query, nil), rec)
+
+			err := NewCustomer(&config.Config{}, customerService).ExportCustomers(c)
+			if tt.wantErr(t, err) {
+				if err == nil {
+					assert.Equal(t, tt.wantStatus, rec.Code)
+					assert.Equal(t, tt.wantContentType, rec.Header().Get(echo.HeaderContentType))
+					assert.Equal(t, tt.wantResp, rec.Body.String())
+				} else {
+					assert.Equal(t, tt.wantStatus, err.(*echo.HTTPError).Code)
+				}
+			}
+		})
+	}
+}
+
+func Test_customerImpl_UpsertCustomerByExternalID(t *testing.T) {
+	testCases := []struct {
+		name       string
+		body       string
+		setupFunc  func(customerService *mockservice.Customer)
+		wantStatus int
+		wantResp   string
+		wantErr    assert.ErrorAssertionFunc
+	}{
+		{
+			name: "created",
+			body: `{"name":"test","age":20}`,
+			setupFunc: func(customerService *mockservice.Customer) {
+				customerService.EXPECT().UpsertCustomerByExternalID(mock.Anything, "crm", "A-1", "test", uint(20)).Return(&entity.Customer{
+					ID:   1,
+					Name: typehelper.GetPointer("test"),
+					Age:  typehelper.GetPointer(uint(20)),
+				}, true, nil)
+			},
+			wantStatus: http.StatusCreated,
+			wantResp:   `{"success":true,"message":"customer created successfully","created":true,"data":{"id":1,"name":"test","age":20}}`,
+			wantErr:    assert.NoError,
+		},
+		{
+			name: "updated",
+			body: `{"name":"test","age":21}`,
+			setupFunc: func(customerService *mockservice.Customer) {
+				customerService.EXPECT().UpsertCustomerByExternalID(mock.Anything, "crm", "A-1", "test", uint(21)).Return(&entity.Customer{
+					ID:   1,
+					Name: typehelper.GetPointer("test"),
+					Age:  typehelper.GetPointer(uint(21)),
+				}, false, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantResp:   `{"success":true,"message":"customer updated successfully","created":false,"data":{"id":1,"name":"test","age":21}}`,
+			wantErr:    assert.NoError,
+		},
+		{
+			name:       "Cannot validate request",
+			body:       `{"name":"test"}`,
+			setupFunc:  func(customerService *mockservice.Customer) {},
+			wantStatus: http.StatusBadRequest,
+			wantResp:   `{"message":"Key: 'UpsertCustomerByExternalIDRequest.Age' Error:Field validation for 'Age' failed on the 'required' tag", "status_code":400, "success":false}`,
+			wantErr:    assert.Error,
+		},
+		{
+			name: "deleted",
+			body: `{"name":"test","age":20}`,
+			setupFunc: func(customerService *mockservice.Customer) {
+				customerService.EXPECT().UpsertCustomerByExternalID(mock.Anything, "crm", "A-1", "test", uint(20)).Return(nil, false, service.ErrCustomerDeleted)
+			},
+			wantStatus: http.StatusConflict,
+			wantResp:   `{"message":"customer with this external ID is deleted, restore it first", "status_code":409, "success":false}`,
+			wantErr:    assert.Error,
+		},
+		{
+			name: "service error",
+			body: `{"name":"test","age":20}`,
+			setupFunc: func(customerService *mockservice.Customer) {
+				customerService.EXPECT().UpsertCustomerByExternalID(mock.Anything, "crm", "A-1", "test", uint(20)).Return(nil, false, fmt.Errorf("internal error"))
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantResp:   `{"message":"error upserting customer: internal error", "status_code":500, "success":false}`,
+			wantErr:    assert.Error,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			customerService := mockservice.NewCustomer(t)
+			tt.setupFunc(customerService)
+			req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			app := echo.New()
+			app.Validator = validator.GetEchoValidator()
+			c := app.NewContext(req, rec)
+			c.SetPath("/customers/by-external/:source/:id")
+			c.SetParamNames("source", "id")
+			c.SetParamValues("crm", "A-1")
+
+			err := NewCustomer(&config.Config{}, customerService).UpsertCustomerByExternalID(c)
+			if tt.wantErr(t, err) {
+				if err == nil {
+					assert.Equal(t, tt.wantStatus, rec.Code)
+					assert.JSONEq(t, tt.wantResp, rec.Body.String())
+				} else {
+					httpErr := err.(*echo.HTTPError)
+					assert.Equal(t, tt.wantStatus, httpErr.Code)
+					jsonRes, err := json.Marshal(httpErr.Message.(*ErrorResponse))
+					assert.NoError(t, err)
+					assert.JSONEq(t, tt.wantResp, string(jsonRes))
+				}
+			}
+		})
+	}
+}
+
+func Test_customerImpl_BatchGetCustomers(t *testing.T) {
+	testCases := []struct {
+		name       string
+		query      string
+		setupFunc  func(customerService *mockservice.Customer)
+		wantStatus int
+		wantResp   string
+		wantErr    assert.ErrorAssertionFunc
+	}{
+		{
+			name:  "success",
+			query: "?ids=2,1,3",
+			setupFunc: func(customerService *mockservice.Customer) {
+				customerService.EXPECT().BatchGetCustomers(mock.Anything, []uint{2, 1, 3}).Return([]*entity.Customer{
+					{ID: 2, Name: typehelper.GetPointer("test2"), Age: typehelper.GetPointer(uint(22))},
+					{ID: 1, Name: typehelper.GetPointer("test1"), Age: typehelper.GetPointer(uint(21))},
+				}, []uint{3}, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantResp:   `{"success":true,"data":[{"id":2,"name":"test2","age":22},{"id":1,"name":"test1","age":21}],"missing_ids":[3],"message":"customers found"}`,
+			wantErr:    assert.NoError,
+		},
+		{
+			name:       "Cannot validate request",
+			query:      "",
+			setupFunc:  func(customerService *mockservice.Customer) {},
+			wantStatus: http.StatusBadRequest,
+			wantResp:   `{"message":"Key: 'BatchGetCustomersRequest.IDs' Error:Field validation for 'IDs' failed on the 'required' tag", "status_code":400, "success":false}`,
+			wantErr:    assert.Error,
+		},
+		{
+			name:       "invalid id",
+			query:      "?ids=1,abc",
+			setupFunc:  func(customerService *mockservice.Customer) {},
+			wantStatus: http.StatusBadRequest,
+			wantResp:   `{"message":"invalid id: \"abc\"", "status_code":400, "success":false}`,
+			wantErr:    assert.Error,
+		},
+		{
+			name:       "too many ids",
+			query:      "?ids=1,2,3,4",
+			setupFunc:  func(customerService *mockservice.Customer) {},
+			wantStatus: http.StatusBadRequest,
+			wantResp:   `{"message":"too many ids: 4, maximum is 3", "status_code":400, "success":false}`,
+			wantErr:    assert.Error,
+		},
+		{
+			name:  "service error",
+			query: "?ids=1",
+			setupFunc: func(customerService *mockservice.Customer) {
+				customerService.EXPECT().BatchGetCustomers(mock.Anything, []uint{1}).Return(nil, nil, fmt.Errorf("internal error"))
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantResp:   `{"message":"error getting customers: internal error", "status_code":500, "success":false}`,
+			wantErr:    assert.Error,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			customerService := mockservice.NewCustomer(t)
+			tt.setupFunc(customerService)
+			rec := httptest.NewRecorder()
+			app := echo.New()
+			app.Validator = validator.GetEchoValidator()
+			c := app.NewContext(httptest.NewRequest(http.MethodGet, "/customers"+tt.query, nil), rec)
+			cfg := &config.Config{Server: config.ServerConfig{MaxBatchSize: 3}}
+
+			err := NewCustomer(cfg, customerService).BatchGetCustomers(c)
+			if tt.wantErr(t, err) {
+				if err == nil {
+					assert.Equal(t, tt.wantStatus, rec.Code)
+					assert.JSONEq(t, tt.wantResp, rec.Body.String())
+				} else {
+					httpErr := err.(*echo.HTTPError)
+					assert.Equal(t, tt.wantStatus, httpErr.Code)
+					jsonRes, err := json.Marshal(httpErr.Message.(*ErrorResponse))
+					assert.NoError(t, err)
+					assert.JSONEq(t, tt.wantResp, string(jsonRes))
+				}
+			}
+		})
+	}
+}
+
+func Test_customerImpl_Redaction(t *testing.T) {
+	newMaskedContext := func(req *http.Request, rec *httptest.ResponseRecorder) echo.Context {
+		app := echo.New()
+		app.Validator = validator.GetEchoValidator()
+		return app.NewContext(req.WithContext(redact.WithMasking(req.Context())), rec)
+	}
+
+	t.Run("masks names in responses", func(t *testing.T) {
+		name := "John Doe"
+		customerService := mockservice.NewCustomer(t)
+		customerService.EXPECT().GetAllCustomer(mock.Anything).Return([]*entity.Customer{
+			{ID: 1, Name: &name, Age: typehelper.GetPointer(uint(20))},
+		}, nil)
+		rec := httptest.NewRecorder()
+		c := newMaskedContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+
+		err := NewCustomer(&config.Config{}, customerService).GetAllCustomer(c)
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"success":true,"data":[{"id":1,"name":"J*** D***","age":20}],"message":"customers found"}`, rec.Body.String())
+		assert.Equal(t, "John Doe", name)
+	})
+
+	t.Run("masks names in exports", func(t *testing.T) {
+		customerService := mockservice.NewCustomer(t)
+		customerService.EXPECT().ExportCustomers(mock.Anything, entity.CustomerFilter{}, mock.Anything).
+			RunAndReturn(func(ctx context.Context, filter entity.CustomerFilter, fn func(*entity.Customer) error) error {
+				return fn(&entity.Customer{ID: 1, Name: typehelper.GetPointer("Jane Doe"), Age: typehelper.GetPointer(uint(30))})
+			})
+		rec := httptest.NewRecorder()
+		c := newMaskedContext(httptest.NewRequest(http.MethodGet, "/customers/export?format=csv", nil), rec)
+
+		err := NewCustomer(&config.Config{}, customerService).ExportCustomers(c)
+		assert.NoError(t, err)
+		assert.Equal(t, "id,name,age\n1,J*** D***,30\n", rec.Body.String())
+	})
+
+	t.Run("scrubs names from error messages", func(t *testing.T) {
+		customerService := mockservice.NewCustomer(t)
+		customerService.EXPECT().CreateCustomer(mock.Anything, "John Doe", uint(20)).
+			Return(nil, fmt.Errorf("duplicate customer John Doe"))
+		req := httptest.NewRequest(http.MethodPost, "/customers", strings.NewReader(`{"name":"John Doe","age":20}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		app := echo.New()
+		app.Validator = validator.GetEchoValidator()
+
+		err := NewCustomer(&config.Config{}, customerService).CreateCustomer(app.NewContext(req, httptest.NewRecorder()))
+		httpErr := err.(*echo.HTTPError)
+		assert.Equal(t, http.StatusInternalServerError, httpErr.Code)
+		assert.Equal(t, "error creating customer: duplicate customer J*** D***", httpErr.Message.(*ErrorResponse).Message)
+	})
+}
@@ -0,0 +1,8 @@
+package handler
+
+import "github.com/labstack/echo/v4"
+
+type Health interface {
+	Liveness(c echo.Context) error
+	Readiness(c echo.Context) error
+}
@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"crud-customer/config"
+	"crud-customer/pkg/health"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+type healthImpl struct {
+	registry *health.Registry
+	cfg      *config.Config
+}
+
+// Liveness only tells that the process serves requests; dependencies are
+// left to Readiness, so an outage does not get the pod restarted.
+func (h *healthImpl) Liveness(c echo.Context) error {
+	return c.JSON(http.StatusOK, map[string]string{"status": health.StatusOK})
+}
+
+func (h *healthImpl) Readiness(c echo.Context) error {
+	report := h.registry.Check(c.Request().Context())
+	if !report.OK() {
+		return c.JSON(http.StatusServiceUnavailable, report)
+	}
+	return c.JSON(http.StatusOK, report)
+}
+
+func NewHealth(cfg *config.Config, registry *health.Registry) Health {
+	return &healthImpl{
+		registry: registry,
+		cfg:      cfg,
+	}
+}
@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"context"
+	"crud-customer/config"
+	"crud-customer/pkg/health"
+	"encoding/json"
+	"errors"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func Test_healthImpl_Liveness(t *testing.T) {
+	registry := health.NewRegistry()
+	registry.Register("database", time.Second, func(ctx context.Context) error {
+		return errors.New("unreachable")
+	})
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/healthz", nil), rec)
+
+	err := NewHealth(&config.Config{}, registry).Liveness(c)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"status":"ok"}`, rec.Body.String())
+}
+
+func Test_healthImpl_Readiness(t *testing.T) {
+	testCases := []struct {
+		name       string
+		setupFunc  func(registry *health.Registry)
+		wantStatus int
+		wantChecks map[string]health.CheckResult
+	}{
+		{
+			name: "every check passes",
+			setupFunc: func(registry *health.Registry) {
+				registry.Register("database", time.Second, func(ctx context.Context) error { return nil })
+				registry.Register("disk", time.Second, health.DiskWritable(t.TempDir()))
+			},
+			wantStatus: http.StatusOK,
+			wantChecks: map[string]health.CheckResult{
+				"database": {Status: health.StatusOK},
+				"disk":     {Status: health.StatusOK},
+			},
+		},
+		{
+			name: "a check fails",
+			setupFunc: func(registry *health.Registry) {
+				registry.Register("database", time.Second, func(ctx context.Context) error { return nil })
+				registry.Register("migrations", time.Second, func(ctx context.Context) error { return errors.New("pending: table customers") })
+			},
+			wantStatus: http.StatusServiceUnavailable,
+			wantChecks: map[string]health.CheckResult{
+				"database":   {Status: health.StatusOK},
+				"migrations": {Status: health.StatusFail, Error: "pending: table customers"},
+			},
+		},
+		{
+			name: "a check times out",
+			setupFunc: func(registry *health.Registry) {
+				registry.Register("database", 10*time.Millisecond, func(ctx context.Context) error {
+					time.Sleep(time.Second)
+					return nil
+				})
+			},
+			wantStatus: http.StatusServiceUnavailable,
+			wantChecks: map[string]health.CheckResult{
+				"database": {Status: health.StatusFail, Error: context.DeadlineExceeded.Error()},
+			},
+		},
+		{
+			name: "shutting down",
+			setupFunc: func(registry *health.Registry) {
+				registry.Register("database", time.Second, func(ctx context.Context) error { return nil })
+				registry.SetShuttingDown()
+			},
+			wantStatus: http.StatusServiceUnavailable,
+			wantChecks: map[string]health.CheckResult{
+				"database": {Status: health.StatusOK},
+				"shutdown": {Status: health.StatusFail, Error: health.ErrShuttingDown.Error()},
+			},
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := health.NewRegistry()
+			tt.setupFunc(registry)
+			rec := httptest.NewRecorder()
+			c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/readyz", nil), rec)
+
+			err := NewHealth(&config.Config{}, registry).Readiness(c)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, rec.Code)
+
+			var report health.Report
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+			for name, check := range report.Checks {
+				assert.NotEmpty(t, check.Duration)
+				check.Duration = ""
+				report.Checks[name] = check
+			}
+			assert.Equal(t, tt.wantChecks, report.Checks)
+		})
+	}
+}
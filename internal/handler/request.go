@@ -1,20 +1,46 @@
 package handler
 
 type CreateCustomerRequest struct {
-	Name string `json:"name" validate:"required"`
-	Age  uint   `json:"age" validate:"required,min=1,max=200"`
+	Name string `json:"name" xml:"name" validate:"required" redact:"partial"`
+	Age  uint   `json:"age" xml:"age" validate:"required,min=1,max=200"`
 }
 
 type UpdateCustomerRequest struct {
 	ID   uint   `param:"id" validate:"required"`
-	Name string `json:"name" validate:"required"`
-	Age  uint   `json:"age" validate:"required,min=1,max=200"`
+	Name string `json:"name" xml:"name" validate:"required" redact:"partial"`
+	Age  uint   `json:"age" xml:"age" validate:"required,min=1,max=200"`
+}
+
+type UpsertCustomerByExternalIDRequest struct {
+	Source     string `param:"source" validate:"required,max=64"`
+	ExternalID string `param:"id" validate:"required,max=128"`
+	Name       string `json:"name" xml:"name" validate:"required" redact:"partial"`
+	Age        uint   `json:"age" xml:"age" validate:"required,min=1,max=200"`
 }
 
 type GetCustomerByIDRequest struct {
 	ID uint `param:"id" validate:"required"`
 }
 
+type BatchGetCustomersRequest struct {
+	IDs string `query:"ids" validate:"required"`
+}
+
 type DeleteCustomerRequest struct {
 	ID uint `param:"id" validate:"required"`
 }
+
+type RestoreCustomerRequest struct {
+	ID uint `param:"id" validate:"required"`
+}
+
+type ExportCustomersRequest struct {
+	Format string  `query:"format" validate:"omitempty,oneof=ndjson csv"`
+	Name   *string `query:"name" redact:"partial"`
+	MinAge *uint   `query:"min_age"`
+	MaxAge *uint   `query:"max_age"`
+}
+
+type GetAuditEntriesRequest struct {
+	CustomerID uint `query:"customer_id" validate:"required"`
+}
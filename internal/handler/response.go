@@ -1,36 +1,110 @@
 package handler
 
 import (
+	"bytes"
+	"crud-customer/pkg/codec"
+	"crud-customer/pkg/redact"
 	"github.com/labstack/echo/v4"
+	"net/http"
+	"time"
 )
 
 type CustomerData struct {
-	ID   uint   `json:"id"`
-	Name string `json:"name"`
-	Age  uint   `json:"age"`
+	ID   uint   `json:"id" xml:"id"`
+	Name string `json:"name" xml:"name" redact:"partial"`
+	Age  uint   `json:"age" xml:"age"`
 }
 
 type CreateUpdateCustomerResponse struct {
-	Success bool         `json:"success"`
-	Message string       `json:"message"`
-	Data    CustomerData `json:"data"`
+	Success bool         `json:"success" xml:"success"`
+	Message string       `json:"message" xml:"message"`
+	Data    CustomerData `json:"data" xml:"data"`
+}
+
+func (r *CreateUpdateCustomerResponse) MarshalCSV() ([][]string, error) {
+	return codec.MarshalCSVRecords(r.Data)
+}
+
+type UpsertCustomerResponse struct {
+	Success bool         `json:"success" xml:"success"`
+	Message string       `json:"message" xml:"message"`
+	Created bool         `json:"created" xml:"created"`
+	Data    CustomerData `json:"data" xml:"data"`
+}
+
+func (r *UpsertCustomerResponse) MarshalCSV() ([][]string, error) {
+	return codec.MarshalCSVRecords(r.Data)
 }
 
 type DeleteCustomerResponse struct {
-	Success bool   `json:"success"`
-	Message string `json:"message"`
+	Success bool   `json:"success" xml:"success"`
+	Message string `json:"message" xml:"message"`
 }
 
 type GetAllCustomerResponse struct {
-	Success bool           `json:"success"`
-	Data    []CustomerData `json:"data"`
-	Message string         `json:"message"`
+	Success bool           `json:"success" xml:"success"`
+	Data    []CustomerData `json:"data" xml:"data>customer"`
+	Message string         `json:"message" xml:"message"`
+}
+
+func (r *GetAllCustomerResponse) MarshalCSV() ([][]string, error) {
+	return codec.MarshalCSVRecords(r.Data)
 }
 
 type GetCustomerByIDResponse struct {
-	Success bool         `json:"success"`
-	Data    CustomerData `json:"data"`
-	Message string       `json:"message"`
+	Success bool         `json:"success" xml:"success"`
+	Data    CustomerData `json:"data" xml:"data"`
+	Message string       `json:"message" xml:"message"`
+}
+
+func (r *GetCustomerByIDResponse) MarshalCSV() ([][]string, error) {
+	return codec.MarshalCSVRecords(r.Data)
+}
+
+type BatchGetCustomersResponse struct {
+	Success    bool           `json:"success" xml:"success"`
+	Data       []CustomerData `json:"data" xml:"data>customer"`
+	MissingIDs []uint         `json:"missing_ids" xml:"missing_ids>id"`
+	Message    string         `json:"message" xml:"message"`
+}
+
+func (r *BatchGetCustomersResponse) MarshalCSV() ([][]string, error) {
+	return codec.MarshalCSVRecords(r.Data)
+}
+
+// AuditDiff is the JSON diff of an audit entry. It is embedded in JSON
+// responses as an object and rendered as its JSON text elsewhere.
+type AuditDiff string
+
+func (d AuditDiff) MarshalJSON() ([]byte, error) {
+	return []byte(d), nil
+}
+
+func (d AuditDiff) String() string {
+	return string(d)
+}
+
+type AuditEntryData struct {
+	ID         uint      `json:"id" xml:"id"`
+	CustomerID uint      `json:"customer_id" xml:"customer_id"`
+	Action     string    `json:"action" xml:"action"`
+	Actor      string    `json:"actor" xml:"actor"`
+	RequestID  string    `json:"request_id" xml:"request_id"`
+	SourceIP   string    `json:"source_ip" xml:"source_ip"`
+	Diff       AuditDiff `json:"diff" xml:"diff"`
+	CreatedAt  time.Time `json:"created_at" xml:"created_at"`
+	PrevHash   string    `json:"prev_hash" xml:"prev_hash"`
+	Hash       string    `json:"hash" xml:"hash"`
+}
+
+type GetAuditEntriesResponse struct {
+	Success bool             `json:"success" xml:"success"`
+	Data    []AuditEntryData `json:"data" xml:"data>entry"`
+	Message string           `json:"message" xml:"message"`
+}
+
+func (r *GetAuditEntriesResponse) MarshalCSV() ([][]string, error) {
+	return codec.MarshalCSVRecords(r.Data)
 }
 
 type ErrorResponse struct {
@@ -52,3 +126,31 @@ func NewBindingErrorResponse(err error) error {
 	bindingErr := err.(*echo.HTTPError)
 	return NewErrorResponse(bindingErr.Code, bindingErr.Message.(string))
 }
+
+// negotiate picks the codec of the response from the Accept header.
+// Handlers call it before anything else, so that a request whose response
+// cannot be rendered fails without changing anything.
+func negotiate(c echo.Context) (codec.Codec, error) {
+	cd, err := codec.GetRegistry().Negotiate(c.Request().Header.Get(echo.HeaderAccept))
+	if err != nil {
+		return nil, NewErrorResponse(http.StatusNotAcceptable, "unsupported Accept header")
+	}
+	return cd, nil
+}
+
+// render writes resp with the codec picked by negotiate, masking PII first
+// when the caller may not see it.
+func render(c echo.Context, cd codec.Codec, statusCode int, resp interface{}) error {
+	if redact.MaskingFromContext(c.Request().Context()) {
+		redact.Apply(resp)
+	}
+	if _, ok := cd.(*codec.JSONCodec); ok {
+		return c.JSON(statusCode, resp)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := cd.Encode(buf, resp); err != nil {
+		return NewErrorResponse(http.StatusNotAcceptable, "response cannot be rendered as "+cd.ContentType())
+	}
+	return c.Blob(statusCode, cd.ContentType(), buf.Bytes())
+}
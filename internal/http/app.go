@@ -1,32 +1,253 @@
 package http
 
 import (
+	"context"
 	"crud-customer/config"
+	"crud-customer/internal/http/routes"
 	"crud-customer/internal/http/routes/api/v1"
+	"crud-customer/internal/outbox"
+	"crud-customer/internal/repository"
+	"crud-customer/pkg/admin"
 	"crud-customer/pkg/database"
+	"crud-customer/pkg/health"
+	"crud-customer/pkg/metrics"
 	"crud-customer/pkg/server"
+	"crud-customer/pkg/tracing"
+	"errors"
+	"fmt"
+	"log/slog"
+	nethttp "net/http"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
 )
 
 type App struct {
-	Config *config.Config
-	Server server.Server
-	DB     database.GormDB
+	Config    *config.Config
+	Server    server.Server
+	DB        database.GormDB
+	Lifecycle *Lifecycle
+	// effective is Config with the settings reloaded since the start.
+	effective atomic.Pointer[config.Config]
 }
 
 func NewApp(cfg *config.Config, db database.GormDB, server server.Server) *App {
 	return &App{
-		Config: cfg,
-		DB:     db,
-		Server: server,
+		Config:    cfg,
+		DB:        db,
+		Server:    server,
+		Lifecycle: &Lifecycle{},
 	}
 }
 
-func (a *App) Start() {
+// Start serves until SIGINT or SIGTERM arrives, or the server fails, then
+// shuts down phase by phase and closes the database.
+func (a *App) Start() error {
+	flushTraces, err := a.setupTracing()
+	if err != nil {
+		return err
+	}
 	a.Server.SetupServer()
-	a.SetupRoute()
-	a.Server.HttpListening()
+	if err := a.SetupRoute(); err != nil {
+		return err
+	}
+	a.watchConfig()
+
+	a.registerServerShutdown()
+	if err := a.startOutboxRelay(); err != nil {
+		return err
+	}
+	a.startMetricsServer()
+	a.startAdminServer()
+	a.Lifecycle.OnShutdown(PhaseFlushWorkers, "traces", flushTraces)
+	a.Lifecycle.OnShutdown(PhaseCheckpoint, "database", a.DB.Checkpoint)
+	a.Lifecycle.OnShutdown(PhaseCloseDB, "database", func(ctx context.Context) error {
+		return a.DB.Close()
+	})
+
+	signalCtx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- a.Server.HttpListening()
+	}()
+	select {
+	case <-signalCtx.Done():
+		// A second signal kills the process.
+		stop()
+	case err = <-serveErr:
+		slog.Error("error serving http", "error", err)
+	}
+	a.Lifecycle.Shutdown(context.Background())
+	return err
+}
+
+// registerServerShutdown closes the listener of the API and then waits up
+// to server.shutdown.drainTimeout for the requests in flight, before
+// dropping their connections.
+func (a *App) registerServerShutdown() {
+	timeout := a.Config.Server.Shutdown.DrainTimeout
+	var drained chan error
+	var cancel context.CancelFunc
+	a.Lifecycle.OnShutdown(PhaseStopAccepting, "http server", func(ctx context.Context) error {
+		var drainCtx context.Context
+		drainCtx, cancel = context.WithTimeout(ctx, timeout)
+		drained = make(chan error, 1)
+		go func() {
+			drained <- a.Server.Shutdown(drainCtx)
+		}()
+		return nil
+	})
+	a.Lifecycle.OnShutdown(PhaseDrain, "http server", func(ctx context.Context) error {
+		defer cancel()
+		if err := <-drained; err != nil {
+			return fmt.Errorf("requests still in flight after %s, closing their connections: %w", timeout, errors.Join(err, a.Server.Close()))
+		}
+		return nil
+	})
+}
+
+func (a *App) SetupRoute() error {
+	routes.SetHealthRoutes(a.Config, a.Server.GetEchoApp(), a.newHealthRegistry())
+	limit, err := v1.NewRateLimitMiddleware(a.Config)
+	if err != nil {
+		return err
+	}
+	v1Group, closeGroup, err := v1.NewGroup(a.Config, a.Server.GetEchoApp(), a.DB, limit)
+	if err != nil {
+		return err
+	}
+	a.Lifecycle.OnShutdown(PhaseFlushWorkers, "authenticators", func(ctx context.Context) error {
+		return closeGroup()
+	})
+	v1.SetCustomerRoutes(a.Config, v1Group, a.DB, limit)
+	v1.SetAuditRoutes(a.Config, v1Group, a.DB, limit)
+	return nil
+}
+
+// newHealthRegistry registers the checks readiness depends on. Readiness
+// fails from the first shutdown phase, which then keeps serving for
+// server.health.shutdownDelay.
+func (a *App) newHealthRegistry() *health.Registry {
+	timeout := a.Config.Server.Health.CheckTimeout
+	registry := health.NewRegistry()
+	registry.Register("database", timeout, a.DB.Ping)
+	registry.Register("migrations", timeout, func(ctx context.Context) error {
+		pending, err := a.DB.Migrator().Pending(ctx)
+		if err != nil {
+			return err
+		}
+		if len(pending) > 0 {
+			names := make([]string, len(pending))
+			for i, migration := range pending {
+				names[i] = migration.String()
+			}
+			return fmt.Errorf("pending: %s", strings.Join(names, ", "))
+		}
+		return nil
+	})
+	registry.Register("disk", timeout, health.DiskWritable(filepath.Dir(a.Config.Database.File)))
+	delay := a.Config.Server.Health.ShutdownDelay
+	a.Lifecycle.OnShutdown(PhaseNotReady, "readiness", func(ctx context.Context) error {
+		registry.SetShuttingDown()
+		select {
+		case <-time.After(delay):
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+	return registry
 }
 
-func (a *App) SetupRoute() {
-	v1.SetCustomerRoutes(a.Config, a.Server.GetEchoApp(), a.DB)
+// setupTracing installs the tracer provider. The returned function flushes
+// the spans not exported yet.
+func (a *App) setupTracing() (func(ctx context.Context) error, error) {
+	if !a.Config.Tracing.Enabled {
+		return func(ctx context.Context) error { return nil }, nil
+	}
+	shutdown, err := tracing.Setup(context.Background(), tracing.Config{
+		ServiceName: a.Config.Tracing.ServiceName,
+		Exporter:    a.Config.Tracing.Exporter,
+		Endpoint:    a.Config.Tracing.Endpoint,
+		Insecure:    a.Config.Tracing.Insecure,
+		File:        a.Config.Tracing.File,
+		SampleRatio: a.Config.Tracing.SampleRatio,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error setting up tracing: %w", err)
+	}
+	return func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		return shutdown(ctx)
+	}, nil
+}
+
+func (a *App) startMetricsServer() {
+	if !a.Config.Metrics.Enabled {
+		return
+	}
+	server := metrics.NewServer(a.Config.Metrics.Address, a.Config.Metrics.Path)
+	go func() {
+		slog.Info("metrics server started", "address", a.Config.Metrics.Address)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, nethttp.ErrServerClosed) {
+			slog.Error("error serving metrics", "error", err)
+		}
+	}()
+	a.Lifecycle.OnShutdown(PhaseFlushWorkers, "metrics server", func(ctx context.Context) error {
+		return server.Close()
+	})
+}
+
+// startAdminServer serves the admin endpoints, which are not authenticated.
+func (a *App) startAdminServer() {
+	if !a.Config.Admin.Enabled {
+		return
+	}
+	server := admin.NewServer(a.Config.Admin.Address, admin.Options{
+		Routes: a.Server.GetEchoApp().Routes,
+		Config: func() interface{} {
+			return a.effective.Load()
+		},
+	})
+	go func() {
+		slog.Info("admin server started", "address", a.Config.Admin.Address)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, nethttp.ErrServerClosed) {
+			slog.Error("error serving admin", "error", err)
+		}
+	}()
+	a.Lifecycle.OnShutdown(PhaseFlushWorkers, "admin server", func(ctx context.Context) error {
+		return server.Close()
+	})
+}
+
+// startOutboxRelay runs the relay until the workers are flushed, which
+// waits up to server.shutdown.drainTimeout for the batch being dispatched
+// before aborting it.
+func (a *App) startOutboxRelay() error {
+	if !a.Config.Outbox.Enabled {
+		return nil
+	}
+	sinks, err := outbox.NewSinks(a.Config)
+	if err != nil {
+		return err
+	}
+	relay := outbox.NewRelay(a.Config, repository.NewOutbox(a.DB.GetDB(), a.Config), sinks)
+	ctx, cancel := context.WithCancel(context.Background())
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		relay.Run(ctx, stop)
+	}()
+	a.Lifecycle.OnShutdown(PhaseFlushWorkers, "outbox relay", func(ctx context.Context) error {
+		close(stop)
+		defer cancel()
+		return waitFor(ctx, done, a.Config.Server.Shutdown.DrainTimeout)
+	})
+	return nil
 }
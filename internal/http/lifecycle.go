@@ -0,0 +1,89 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// Phase is a step of the shutdown. Phases run in the order declared here.
+type Phase int
+
+const (
+	// PhaseNotReady fails readiness, so load balancers stop routing to the
+	// instance, while it keeps serving.
+	PhaseNotReady Phase = iota
+	// PhaseStopAccepting closes the listeners.
+	PhaseStopAccepting
+	// PhaseDrain waits for the requests in flight.
+	PhaseDrain
+	// PhaseFlushWorkers stops the background workers and flushes what they
+	// buffer.
+	PhaseFlushWorkers
+	// PhaseCheckpoint checkpoints the write-ahead log of the database.
+	PhaseCheckpoint
+	// PhaseCloseDB closes the database.
+	PhaseCloseDB
+)
+
+var phaseNames = [...]string{
+	PhaseNotReady:      "not ready",
+	PhaseStopAccepting: "stop accepting requests",
+	PhaseDrain:         "drain requests",
+	PhaseFlushWorkers:  "flush workers",
+	PhaseCheckpoint:    "checkpoint wal",
+	PhaseCloseDB:       "close database",
+}
+
+func (p Phase) String() string {
+	return phaseNames[p]
+}
+
+type hook struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+// Lifecycle runs the shutdown hooks phase by phase. A failing hook is
+// logged and does not stop the shutdown.
+type Lifecycle struct {
+	hooks [len(phaseNames)][]hook
+}
+
+// OnShutdown registers fn to run during phase, after the hooks registered
+// for it before.
+func (l *Lifecycle) OnShutdown(phase Phase, name string, fn func(ctx context.Context) error) {
+	l.hooks[phase] = append(l.hooks[phase], hook{name: name, fn: fn})
+}
+
+func (l *Lifecycle) Shutdown(ctx context.Context) {
+	slog.Info("shutting down service")
+	for phase, hooks := range l.hooks {
+		start := time.Now()
+		slog.Info("shutdown phase started", "phase", Phase(phase).String())
+		failed := 0
+		for _, hook := range hooks {
+			if err := hook.fn(ctx); err != nil {
+				failed++
+				slog.Error("shutdown hook failed", "phase", Phase(phase).String(), "hook", hook.name, "error", err)
+			}
+		}
+		slog.Info("shutdown phase finished", "phase", Phase(phase).String(),
+			"hooks", len(hooks), "failed", failed, "elapsed_ms", time.Since(start).Milliseconds())
+	}
+	slog.Info("service shut down")
+}
+
+// waitFor waits for done to be closed, for at most timeout, so a hook
+// waiting on a stuck worker does not hold up the shutdown.
+func waitFor(ctx context.Context, done <-chan struct{}, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("still running after %s: %w", timeout, ctx.Err())
+	}
+}
@@ -0,0 +1,90 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+type LifecycleTestSuite struct {
+	suite.Suite
+	lifecycle *Lifecycle
+	calls     []string
+}
+
+func (s *LifecycleTestSuite) SetupTest() {
+	s.lifecycle = &Lifecycle{}
+	s.calls = nil
+}
+
+func (s *LifecycleTestSuite) TearDownTest() {
+	s.lifecycle = nil
+	s.calls = nil
+}
+
+func (s *LifecycleTestSuite) hook(name string, err error) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		s.calls = append(s.calls, name)
+		return err
+	}
+}
+
+func (s *LifecycleTestSuite) TestRunsPhasesInOrder() {
+	s.lifecycle.OnShutdown(PhaseCloseDB, "close", s.hook("close", nil))
+	s.lifecycle.OnShutdown(PhaseFlushWorkers, "outbox", s.hook("outbox", nil))
+	s.lifecycle.OnShutdown(PhaseNotReady, "readiness", s.hook("readiness", nil))
+	s.lifecycle.OnShutdown(PhaseFlushWorkers, "traces", s.hook("traces", nil))
+	s.lifecycle.OnShutdown(PhaseDrain, "drain", s.hook("drain", nil))
+
+	s.lifecycle.Shutdown(context.Background())
+	s.Equal([]string{"readiness", "drain", "outbox", "traces", "close"}, s.calls)
+}
+
+func (s *LifecycleTestSuite) TestContinuesAfterFailedHook() {
+	s.lifecycle.OnShutdown(PhaseDrain, "drain", s.hook("drain", fmt.Errorf("error")))
+	s.lifecycle.OnShutdown(PhaseCheckpoint, "checkpoint", s.hook("checkpoint", nil))
+	s.lifecycle.OnShutdown(PhaseCloseDB, "close", s.hook("close", nil))
+
+	s.lifecycle.Shutdown(context.Background())
+	s.Equal([]string{"drain", "checkpoint", "close"}, s.calls)
+}
+
+func (s *LifecycleTestSuite) TestWaitForReturnsWhenDone() {
+	done := make(chan struct{})
+	close(done)
+
+	s.NoError(waitFor(context.Background(), done, time.Second))
+}
+
+func (s *LifecycleTestSuite) TestWaitForGivesUpAfterTimeout() {
+	s.lifecycle.OnShutdown(PhaseFlushWorkers, "stuck", func(ctx context.Context) error {
+		return waitFor(ctx, make(chan struct{}), 10*time.Millisecond)
+	})
+	s.lifecycle.OnShutdown(PhaseCloseDB, "close", s.hook("close", nil))
+
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		s.lifecycle.Shutdown(context.Background())
+	}()
+
+	select {
+	case <-finished:
+		s.Equal([]string{"close"}, s.calls)
+	case <-time.After(5 * time.Second):
+		s.Fail("shutdown waited on a stuck hook")
+	}
+}
+
+func (s *LifecycleTestSuite) TestWaitForError() {
+	err := waitFor(context.Background(), make(chan struct{}), 10*time.Millisecond)
+
+	s.ErrorIs(err, context.DeadlineExceeded)
+	s.EqualError(err, "still running after 10ms: context deadline exceeded")
+}
+
+func TestLifecycleSuite(t *testing.T) {
+	suite.Run(t, new(LifecycleTestSuite))
+}
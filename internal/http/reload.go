@@ -0,0 +1,91 @@
+package http
+
+import (
+	"crud-customer/config"
+	"crud-customer/pkg/admin"
+	"crud-customer/util"
+	"log/slog"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// reloadableSettings are the config keys applied while serving, by
+// withReloadable, Server.Reload and the rate limit middleware. Changes to
+// other keys take effect on the next start.
+var reloadableSettings = []string{
+	"server.allowOrigins",
+	"server.timeout",
+	"server.bodyLimit",
+	"server.logLevel",
+	"server.rateLimit.groups",
+}
+
+// withReloadable returns a copy of cfg with the reloadable settings of
+// reloaded.
+func withReloadable(cfg *config.Config, reloaded *config.Config) *config.Config {
+	effective := *cfg
+	effective.Server.AllowOrigins = reloaded.Server.AllowOrigins
+	effective.Server.Timeout = reloaded.Server.Timeout
+	effective.Server.BodyLimit = reloaded.Server.BodyLimit
+	effective.Server.LogLevel = reloaded.Server.LogLevel
+	effective.Server.RateLimit.Groups = reloaded.Server.RateLimit.Groups
+	return &effective
+}
+
+// watchConfig applies the reloadable settings of every valid change of
+// config.yaml, and logs the changes that need a restart.
+func (a *App) watchConfig() {
+	a.effective.Store(a.Config)
+	util.OnConfigChange(func(reloaded *config.Config) {
+		previous := a.effective.Load()
+		applied, ignored := changedSettings(previous, reloaded)
+		if len(applied) > 0 {
+			a.Server.Reload(reloaded)
+			a.effective.Store(withReloadable(previous, reloaded))
+			slog.Info("config reloaded", "applied", applied)
+		}
+		if len(ignored) > 0 {
+			slog.Warn("config changes take effect on restart", "settings", ignored)
+		}
+	})
+}
+
+// changedSettings lists the keys whose values differ between previous and
+// reloaded, split into reloadable ones and the others. Only keys are
+// returned, since values may be secret.
+func changedSettings(previous *config.Config, reloaded *config.Config) (applied []string, ignored []string) {
+	before := admin.Flatten(admin.Sanitize(previous))
+	after := admin.Flatten(admin.Sanitize(reloaded))
+
+	changed := map[string]bool{}
+	for key, value := range before {
+		if other, ok := after[key]; !ok || !reflect.DeepEqual(value, other) {
+			changed[key] = true
+		}
+	}
+	for key := range after {
+		if _, ok := before[key]; !ok {
+			changed[key] = true
+		}
+	}
+	for key := range changed {
+		if isReloadable(key) {
+			applied = append(applied, key)
+		} else {
+			ignored = append(ignored, key)
+		}
+	}
+	sort.Strings(applied)
+	sort.Strings(ignored)
+	return applied, ignored
+}
+
+func isReloadable(key string) bool {
+	for _, setting := range reloadableSettings {
+		if key == setting || strings.HasPrefix(key, setting+".") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,18 @@
+package v1
+
+import (
+	"crud-customer/config"
+	"crud-customer/internal/handler"
+	"crud-customer/internal/repository"
+	"crud-customer/internal/service"
+	"crud-customer/pkg/database"
+	"github.com/labstack/echo/v4"
+)
+
+func SetAuditRoutes(cfg *config.Config, v1Group *echo.Group, db database.GormDB, limit RateLimitMiddleware) {
+	auditRepo := repository.NewAudit(db.GetDB(), cfg)
+	auditService := service.NewAudit(cfg, auditRepo)
+	auditHandler := handler.NewAudit(cfg, auditService)
+	require := newPermissionMiddleware(cfg)
+	v1Group.GET("/audit", auditHandler.GetAuditEntries, limit(RateLimitGroupDefault), require(PermissionAuditRead)).Name = "GetAuditEntries"
+}
@@ -6,17 +6,26 @@ import (
 	"crud-customer/internal/repository"
 	"crud-customer/internal/service"
 	"crud-customer/pkg/database"
+	"crud-customer/pkg/echo_server"
+	"crud-customer/pkg/redact"
 	"github.com/labstack/echo/v4"
 )
 
-func SetCustomerRoutes(cfg *config.Config, echoApp *echo.Echo, db database.GormDB) {
-	customerRepo := repository.NewCustomer(db.GetDB(), cfg)
-	customerService := service.NewCustomer(cfg, customerRepo)
+func SetCustomerRoutes(cfg *config.Config, v1Group *echo.Group, db database.GormDB, limit RateLimitMiddleware) {
+	customerRepo := repository.NewCustomerTracing(repository.NewCustomer(db.GetDB(), cfg))
+	customerService := service.NewCustomerMetrics(service.NewCustomerTracing(service.NewCustomer(cfg, customerRepo)))
 	customerHandler := handler.NewCustomer(cfg, customerService)
-	v1Group := echoApp.Group("/api/v1")
-	v1Group.POST("/customers/", customerHandler.CreateCustomer).Name = "CreateCustomer"
-	v1Group.PUT("/customers/:id", customerHandler.UpdateCustomer).Name = "UpdateCustomer"
-	v1Group.GET("/customers/:id", customerHandler.GetCustomerByID).Name = "GetCustomerByID"
-	v1Group.DELETE("/customers/:id", customerHandler.DeleteCustomer).Name = "DeleteCustomer"
-	v1Group.GET("/customers/", customerHandler.GetAllCustomer).Name = "GetAllCustomer"
+	require := newPermissionMiddleware(cfg)
+	v1Group.POST("/customers/", customerHandler.CreateCustomer, limit(RateLimitGroupDefault), require(PermissionCustomersWrite)).Name = "CreateCustomer"
+	v1Group.PUT("/customers/:id", customerHandler.UpdateCustomer, limit(RateLimitGroupDefault), require(PermissionCustomersWrite)).Name = "UpdateCustomer"
+	v1Group.GET("/customers/:id", customerHandler.GetCustomerByID, limit(RateLimitGroupDefault), require(PermissionCustomersRead)).Name = "GetCustomerByID"
+	v1Group.DELETE("/customers/:id", customerHandler.DeleteCustomer, limit(RateLimitGroupDefault), require(PermissionCustomersDelete)).Name = "DeleteCustomer"
+	v1Group.POST("/customers/:id/restore", customerHandler.RestoreCustomer, limit(RateLimitGroupDefault), require(PermissionCustomersDelete)).Name = "RestoreCustomer"
+	v1Group.GET("/customers/", customerHandler.GetAllCustomer, limit(RateLimitGroupBulk), require(PermissionCustomersRead)).Name = "GetAllCustomer"
+	v1Group.GET("/customers", customerHandler.BatchGetCustomers, limit(RateLimitGroupBulk), require(PermissionCustomersRead)).Name = "BatchGetCustomers"
+	v1Group.PUT("/customers/by-external/:source/:id", customerHandler.UpsertCustomerByExternalID, limit(RateLimitGroupDefault), require(PermissionCustomersWrite)).Name = "UpsertCustomerByExternalID"
+	exportRoute := v1Group.GET("/customers/export", customerHandler.ExportCustomers, limit(RateLimitGroupBulk), require(PermissionCustomersRead))
+	exportRoute.Name = "ExportCustomers"
+	echo_server.RegisterStreamingRoute(exportRoute)
+	echo_server.RegisterSensitiveQueryParams(redact.QueryParams(handler.ExportCustomersRequest{})...)
 }
@@ -0,0 +1,95 @@
+package v1
+
+import (
+	"context"
+	"crud-customer/config"
+	"crud-customer/internal/repository"
+	"crud-customer/internal/service"
+	"crud-customer/pkg/auth"
+	"crud-customer/pkg/database"
+	"crud-customer/pkg/echo_server"
+	"crud-customer/pkg/tenant"
+	"crud-customer/pkg/tlsconfig"
+	"fmt"
+	"github.com/labstack/echo/v4"
+)
+
+// NewGroup creates the /api/v1 group with the rate limit of the auth group,
+// then the authentication middleware for every enabled credential type (API keys, JWTs, client certificates),
+// followed by tenant resolution when multi-tenancy is enabled and PII masking. The returned function releases
+// what the authenticators hold, on shutdown.
+func NewGroup(cfg *config.Config, echoApp *echo.Echo, db database.GormDB, limit RateLimitMiddleware) (*echo.Group, func() error, error) {
+	closeGroup := func() error { return nil }
+	var authenticators []auth.Authenticator
+	if cfg.Server.APIKey.Enabled {
+		apiKeyService := service.NewAPIKey(cfg, repository.NewAPIKey(db.GetDB(), cfg))
+		authenticators = append(authenticators, auth.NewAPIKeyAuthenticator(cfg.Server.APIKey.Header, func(ctx context.Context, key string) (*auth.Principal, error) {
+			apiKey, err := apiKeyService.Authenticate(ctx, key)
+			if err != nil {
+				return nil, err
+			}
+			return &auth.Principal{
+				Type:     auth.PrincipalTypeAPIKey,
+				Subject:  fmt.Sprintf("api_key:%d", apiKey.ID),
+				Scopes:   apiKey.Scopes,
+				TenantID: apiKey.TenantID,
+			}, nil
+		}))
+	}
+
+	if cfg.Server.JWT.Enabled {
+		tenantClaim := ""
+		if cfg.Server.Tenant.Enabled {
+			tenantClaim = cfg.Server.Tenant.Claim
+		}
+		verifier, err := auth.NewJWTVerifier(auth.JWTVerifierConfig{
+			JWKSFile:    cfg.Server.JWT.JWKSFile,
+			Issuer:      cfg.Server.JWT.Issuer,
+			Audience:    cfg.Server.JWT.Audience,
+			ClockSkew:   cfg.Server.JWT.ClockSkew,
+			Algorithms:  cfg.Server.JWT.Algorithms,
+			RoleClaim:   cfg.Server.JWT.RoleClaim,
+			TenantClaim: tenantClaim,
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("error loading jwks: %w", err)
+		}
+		closeGroup = verifier.Close
+		authenticators = append(authenticators, auth.NewJWTAuthenticator(verifier))
+	}
+
+	if cfg.Server.TLS.Enabled && cfg.Server.TLS.ClientAuth != tlsconfig.ClientAuthNone {
+		authenticators = append(authenticators, auth.NewClientCertAuthenticator())
+	}
+
+	middlewares := []echo.MiddlewareFunc{limit(RateLimitGroupAuth)}
+	if len(authenticators) > 0 {
+		middlewares = append(middlewares, echo_server.GetAuthMiddleware(authenticators...))
+	}
+	if cfg.Server.Tenant.Enabled {
+		middlewares = append(middlewares, echo_server.GetTenantMiddleware(tenant.NewResolver(tenant.ResolverConfig{
+			Header:     cfg.Server.Tenant.Header,
+			BaseDomain: cfg.Server.Tenant.BaseDomain,
+			Default:    cfg.Server.Tenant.Default,
+		})))
+	}
+	middlewares = append(middlewares, echo_server.GetRedactionMiddleware(newAuthorizer(cfg), PermissionCustomersReadPII))
+	return echoApp.Group("/api/v1", middlewares...), closeGroup, nil
+}
+
+// newPermissionMiddleware returns a function that builds the middleware
+// requiring a permission on a route, sharing one authorizer across routes.
+func newPermissionMiddleware(cfg *config.Config) func(permission string) echo.MiddlewareFunc {
+	authorizer := newAuthorizer(cfg)
+	return func(permission string) echo.MiddlewareFunc {
+		return echo_server.GetPermissionMiddleware(authorizer, permission)
+	}
+}
+
+// newAuthorizer returns nil when RBAC is disabled.
+func newAuthorizer(cfg *config.Config) *auth.Authorizer {
+	if !cfg.Server.RBAC.Enabled {
+		return nil
+	}
+	return auth.NewAuthorizer(cfg.Server.RBAC.Roles)
+}
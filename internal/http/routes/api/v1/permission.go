@@ -0,0 +1,12 @@
+package v1
+
+// Permissions required by the v1 routes. Roles in the rbac config map to
+// lists of these.
+const (
+	PermissionCustomersRead   = "customers:read"
+	PermissionCustomersWrite  = "customers:write"
+	PermissionCustomersDelete = "customers:delete"
+	PermissionAuditRead       = "audit:read"
+	// PermissionCustomersReadPII lets a caller see customer names unmasked.
+	PermissionCustomersReadPII = "customers:pii"
+)
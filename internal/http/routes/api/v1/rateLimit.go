@@ -0,0 +1,61 @@
+package v1
+
+import (
+	"crud-customer/config"
+	"crud-customer/pkg/echo_server"
+	"crud-customer/pkg/ratelimit"
+	"crud-customer/util"
+	"github.com/labstack/echo/v4"
+)
+
+// Rate limit groups of the v1 routes. The server.rateLimit.groups config
+// sets the limit of each; bulk covers the routes reading many customers at
+// once, which hold SQLite the longest. auth limits every request by client
+// IP before authentication, so that guessing credentials is limited too.
+const (
+	RateLimitGroupDefault = "default"
+	RateLimitGroupBulk    = "bulk"
+	RateLimitGroupAuth    = "auth"
+)
+
+// RateLimitMiddleware builds the middleware limiting a route by its group.
+type RateLimitMiddleware func(group string) echo.MiddlewareFunc
+
+// NewRateLimitMiddleware returns the RateLimitMiddleware of every route,
+// sharing one store and one set of limits. Groups without a limit fall back
+// to the default group, and routes are not limited when neither is
+// configured. Limits follow config reloads.
+func NewRateLimitMiddleware(cfg *config.Config) (RateLimitMiddleware, error) {
+	if !cfg.Server.RateLimit.Enabled {
+		return func(group string) echo.MiddlewareFunc {
+			return noopMiddleware
+		}, nil
+	}
+	store, err := ratelimit.NewStore(cfg)
+	if err != nil {
+		return nil, err
+	}
+	limits := ratelimit.NewLimits(RateLimitGroupDefault, rateLimits(cfg))
+	util.OnConfigChange(func(cfg *config.Config) {
+		limits.Set(rateLimits(cfg))
+	})
+	return func(group string) echo.MiddlewareFunc {
+		return echo_server.GetRateLimitMiddleware(store, limits, group)
+	}, nil
+}
+
+func rateLimits(cfg *config.Config) map[string]ratelimit.Limit {
+	limits := make(map[string]ratelimit.Limit, len(cfg.Server.RateLimit.Groups))
+	for group, rule := range cfg.Server.RateLimit.Groups {
+		limits[group] = ratelimit.Limit{
+			Requests: rule.Requests,
+			Period:   rule.Period,
+			Burst:    rule.Burst,
+		}
+	}
+	return limits
+}
+
+func noopMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return next
+}
@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"crud-customer/config"
+	"crud-customer/internal/handler"
+	"crud-customer/pkg/health"
+	"github.com/labstack/echo/v4"
+)
+
+// SetHealthRoutes registers the probes at the root, outside /api/v1, so they
+// need no credentials.
+func SetHealthRoutes(cfg *config.Config, echoApp *echo.Echo, registry *health.Registry) {
+	healthHandler := handler.NewHealth(cfg, registry)
+	echoApp.GET("/healthz", healthHandler.Liveness).Name = "Liveness"
+	echoApp.GET("/readyz", healthHandler.Readiness).Name = "Readiness"
+}
@@ -0,0 +1,48 @@
+package outbox
+
+import (
+	"context"
+	"crud-customer/config"
+	"crud-customer/internal/entity"
+	"encoding/json"
+	"os"
+	"sync"
+)
+
+// fileSink appends every event as one JSON line and fsyncs before reporting
+// success.
+type fileSink struct {
+	mu   sync.Mutex
+	path string
+}
+
+func (f *fileSink) Name() string {
+	return "file"
+}
+
+func (f *fileSink) Send(ctx context.Context, event *entity.OutboxEvent) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	return file.Sync()
+}
+
+func NewFileSink(cfg *config.Config) (Sink, error) {
+	return &fileSink{path: cfg.Outbox.File}, nil
+}
+
+func init() {
+	RegisterSink("file", NewFileSink)
+}
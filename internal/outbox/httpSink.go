@@ -0,0 +1,61 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"crud-customer/config"
+	"crud-customer/internal/entity"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// httpSink POSTs every event as JSON. Any non-2xx response is treated as a
+// failed delivery and retried by the relay.
+type httpSink struct {
+	url    string
+	client *http.Client
+}
+
+func (h *httpSink) Name() string {
+	return "http"
+}
+
+func (h *httpSink) Send(ctx context.Context, event *entity.OutboxEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", strconv.FormatUint(uint64(event.ID), 10))
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func NewHTTPSink(cfg *config.Config) (Sink, error) {
+	if cfg.Outbox.HTTPURL == "" {
+		return nil, errors.New("outbox.httpUrl is required")
+	}
+	return &httpSink{
+		url:    cfg.Outbox.HTTPURL,
+		client: &http.Client{Timeout: cfg.Outbox.HTTPTimeout},
+	}, nil
+}
+
+func init() {
+	RegisterSink("http", NewHTTPSink)
+}
@@ -0,0 +1,49 @@
+package outbox
+
+import (
+	"context"
+	"crud-customer/config"
+	"crud-customer/internal/entity"
+	"crud-customer/pkg/redact"
+	"encoding/json"
+	"log/slog"
+)
+
+type logSink struct{}
+
+func (l *logSink) Name() string {
+	return "log"
+}
+
+func (l *logSink) Send(ctx context.Context, event *entity.OutboxEvent) error {
+	slog.InfoContext(ctx, "outbox event",
+		"event_id", event.ID,
+		"event_type", event.EventType,
+		"aggregate_type", event.AggregateType,
+		"aggregate_id", event.AggregateID,
+		"payload", maskPayload(event.Payload))
+	return nil
+}
+
+// maskPayload masks the PII of a customer payload. Payloads that cannot be
+// read as a customer are hidden whole.
+func maskPayload(payload string) string {
+	var customer entity.Customer
+	if err := json.Unmarshal([]byte(payload), &customer); err != nil {
+		return redact.Mask(redact.MaskFull, payload)
+	}
+	redact.Apply(&customer)
+	masked, err := json.Marshal(&customer)
+	if err != nil {
+		return redact.Mask(redact.MaskFull, payload)
+	}
+	return string(masked)
+}
+
+func NewLogSink(cfg *config.Config) (Sink, error) {
+	return &logSink{}, nil
+}
+
+func init() {
+	RegisterSink("log", NewLogSink)
+}
@@ -0,0 +1,12 @@
+package outbox
+
+import "context"
+
+type Relay interface {
+	// Run polls the outbox until stop is closed, after finishing the batch
+	// being dispatched. Cancelling ctx aborts that batch too.
+	Run(ctx context.Context, stop <-chan struct{})
+	// DispatchPending delivers one batch of pending events and returns the
+	// number of events marked dispatched.
+	DispatchPending(ctx context.Context) (int, error)
+}
@@ -0,0 +1,114 @@
+package outbox
+
+import (
+	"context"
+	"crud-customer/config"
+	"crud-customer/internal/entity"
+	"crud-customer/internal/repository"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+type aggregateKey struct {
+	aggregateType string
+	aggregateID   uint
+}
+
+type relayImpl struct {
+	outboxRepo repository.Outbox
+	sinks      []Sink
+	cfg        *config.Config
+}
+
+func (r *relayImpl) Run(ctx context.Context, stop <-chan struct{}) {
+	ticker := time.NewTicker(r.cfg.Outbox.PollInterval)
+	defer ticker.Stop()
+	for {
+		if _, err := r.DispatchPending(ctx); err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "error dispatching outbox events", "error", err)
+		}
+		select {
+		case <-stop:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DispatchPending sends pending events in ID order. Once delivery of an event
+// fails, later events for the same aggregate are held back until its retry,
+// which keeps per-customer ordering intact. An event failing
+// outbox.maxAttempts times is dead-lettered, so the aggregate moves on.
+func (r *relayImpl) DispatchPending(ctx context.Context) (int, error) {
+	events, err := r.outboxRepo.GetPendingEvents(ctx, r.cfg.Outbox.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	dispatched := 0
+	blocked := map[aggregateKey]bool{}
+	for _, event := range events {
+		if ctx.Err() != nil {
+			return dispatched, ctx.Err()
+		}
+		key := aggregateKey{event.AggregateType, event.AggregateID}
+		if blocked[key] {
+			continue
+		}
+		if err := r.send(ctx, event); err != nil {
+			blocked[key] = true
+			if err := r.markFailed(ctx, event, err); err != nil {
+				return dispatched, err
+			}
+			continue
+		}
+		if err := r.outboxRepo.MarkDispatched(ctx, event.ID); err != nil {
+			return dispatched, err
+		}
+		dispatched++
+	}
+	return dispatched, nil
+}
+
+func (r *relayImpl) markFailed(ctx context.Context, event *entity.OutboxEvent, sendErr error) error {
+	attempts := int(event.Attempts) + 1
+	if attempts >= r.cfg.Outbox.MaxAttempts {
+		slog.ErrorContext(ctx, "outbox event dead-lettered", "event_id", event.ID, "attempts", attempts, "error", sendErr)
+		return r.outboxRepo.MarkDeadLettered(ctx, event.ID, sendErr.Error())
+	}
+	backoff := r.backoff(attempts)
+	slog.WarnContext(ctx, "outbox event not delivered", "event_id", event.ID, "attempts", attempts,
+		"retry_in", backoff.String(), "error", sendErr)
+	return r.outboxRepo.MarkFailed(ctx, event.ID, sendErr.Error(), time.Now().Add(backoff))
+}
+
+// backoff returns the wait after the given number of failed attempts:
+// outbox.retryBackoff doubled for every attempt after the first, up to
+// outbox.maxRetryBackoff.
+func (r *relayImpl) backoff(attempts int) time.Duration {
+	backoff := r.cfg.Outbox.RetryBackoff
+	for i := 1; i < attempts && backoff < r.cfg.Outbox.MaxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, r.cfg.Outbox.MaxRetryBackoff)
+}
+
+func (r *relayImpl) send(ctx context.Context, event *entity.OutboxEvent) error {
+	for _, sink := range r.sinks {
+		if err := sink.Send(ctx, event); err != nil {
+			return fmt.Errorf("%s sink: %w", sink.Name(), err)
+		}
+	}
+	return nil
+}
+
+func NewRelay(cfg *config.Config, outboxRepo repository.Outbox, sinks []Sink) Relay {
+	return &relayImpl{
+		outboxRepo: outboxRepo,
+		sinks:      sinks,
+		cfg:        cfg,
+	}
+}
@@ -0,0 +1,205 @@
+package outbox
+
+import (
+	"context"
+	"crud-customer/config"
+	"crud-customer/internal/entity"
+	mockrepo "crud-customer/mocks/internal_/repository"
+	"fmt"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+type fakeSink struct {
+	failIDs map[uint]bool
+	sent    []uint
+}
+
+func (f *fakeSink) Name() string {
+	return "fake"
+}
+
+func (f *fakeSink) Send(ctx context.Context, event *entity.OutboxEvent) error {
+	if f.failIDs[event.ID] {
+		return fmt.Errorf("error")
+	}
+	f.sent = append(f.sent, event.ID)
+	return nil
+}
+
+// blockingSink holds every event until release is closed, reporting on
+// sending when it starts.
+type blockingSink struct {
+	sending chan uint
+	release chan struct{}
+}
+
+func (b *blockingSink) Name() string {
+	return "blocking"
+}
+
+func (b *blockingSink) Send(ctx context.Context, event *entity.OutboxEvent) error {
+	b.sending <- event.ID
+	select {
+	case <-b.release:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+type RelayImplTestSuite struct {
+	suite.Suite
+	mockOutboxRepo *mockrepo.Outbox
+	sink           *fakeSink
+	relay          Relay
+}
+
+func (s *RelayImplTestSuite) SetupTest() {
+	s.mockOutboxRepo = mockrepo.NewOutbox(s.T())
+	s.sink = &fakeSink{failIDs: map[uint]bool{}}
+	s.relay = NewRelay(&config.Config{Outbox: config.OutboxConfig{
+		PollInterval:    time.Hour,
+		BatchSize:       10,
+		MaxAttempts:     3,
+		RetryBackoff:    time.Second,
+		MaxRetryBackoff: 3 * time.Second,
+	}}, s.mockOutboxRepo, []Sink{s.sink})
+}
+
+func (s *RelayImplTestSuite) TearDownTest() {
+	s.mockOutboxRepo = nil
+	s.sink = nil
+	s.relay = nil
+}
+
+func (s *RelayImplTestSuite) TestDispatchPendingSuccess() {
+	s.mockOutboxRepo.EXPECT().GetPendingEvents(mock.Anything, 10).Return([]*entity.OutboxEvent{
+		{ID: 1, AggregateType: entity.AggregateTypeCustomer, AggregateID: 1},
+		{ID: 2, AggregateType: entity.AggregateTypeCustomer, AggregateID: 2},
+	}, nil)
+	s.mockOutboxRepo.EXPECT().MarkDispatched(mock.Anything, uint(1)).Return(nil)
+	s.mockOutboxRepo.EXPECT().MarkDispatched(mock.Anything, uint(2)).Return(nil)
+
+	got, err := s.relay.DispatchPending(context.Background())
+	s.NoError(err)
+	s.Equal(2, got)
+	s.Equal([]uint{1, 2}, s.sink.sent)
+}
+
+func (s *RelayImplTestSuite) TestDispatchPendingHoldsBackFailedAggregate() {
+	s.sink.failIDs[1] = true
+	s.mockOutboxRepo.EXPECT().GetPendingEvents(mock.Anything, 10).Return([]*entity.OutboxEvent{
+		{ID: 1, AggregateType: entity.AggregateTypeCustomer, AggregateID: 1},
+		{ID: 2, AggregateType: entity.AggregateTypeCustomer, AggregateID: 2},
+		{ID: 3, AggregateType: entity.AggregateTypeCustomer, AggregateID: 1},
+	}, nil)
+	s.mockOutboxRepo.EXPECT().MarkFailed(mock.Anything, uint(1), "fake sink: error", mock.Anything).Return(nil)
+	s.mockOutboxRepo.EXPECT().MarkDispatched(mock.Anything, uint(2)).Return(nil)
+
+	got, err := s.relay.DispatchPending(context.Background())
+	s.NoError(err)
+	s.Equal(1, got)
+	s.Equal([]uint{2}, s.sink.sent)
+}
+
+func (s *RelayImplTestSuite) TestDispatchPendingBacksOff() {
+	s.sink.failIDs[1] = true
+	s.mockOutboxRepo.EXPECT().GetPendingEvents(mock.Anything, 10).Return([]*entity.OutboxEvent{
+		{ID: 1, AggregateType: entity.AggregateTypeCustomer, AggregateID: 1, Attempts: 1},
+	}, nil)
+	start := time.Now()
+	s.mockOutboxRepo.EXPECT().MarkFailed(mock.Anything, uint(1), "fake sink: error", mock.Anything).
+		Run(func(ctx context.Context, id uint, reason string, retryAt time.Time) {
+			s.WithinRange(retryAt, start.Add(2*time.Second), time.Now().Add(2*time.Second))
+		}).Return(nil)
+
+	got, err := s.relay.DispatchPending(context.Background())
+	s.NoError(err)
+	s.Zero(got)
+}
+
+func (s *RelayImplTestSuite) TestDispatchPendingDeadLettersAfterMaxAttempts() {
+	s.sink.failIDs[1] = true
+	s.mockOutboxRepo.EXPECT().GetPendingEvents(mock.Anything, 10).Return([]*entity.OutboxEvent{
+		{ID: 1, AggregateType: entity.AggregateTypeCustomer, AggregateID: 1, Attempts: 2},
+	}, nil)
+	s.mockOutboxRepo.EXPECT().MarkDeadLettered(mock.Anything, uint(1), "fake sink: error").Return(nil)
+
+	got, err := s.relay.DispatchPending(context.Background())
+	s.NoError(err)
+	s.Zero(got)
+}
+
+func (s *RelayImplTestSuite) TestBackoffDoublesUpToMax() {
+	relay := s.relay.(*relayImpl)
+	s.Equal(time.Second, relay.backoff(1))
+	s.Equal(2*time.Second, relay.backoff(2))
+	s.Equal(3*time.Second, relay.backoff(3))
+	s.Equal(3*time.Second, relay.backoff(30))
+}
+
+func (s *RelayImplTestSuite) TestDispatchPendingError() {
+	s.mockOutboxRepo.EXPECT().GetPendingEvents(mock.Anything, 10).Return(nil, fmt.Errorf("error"))
+
+	got, err := s.relay.DispatchPending(context.Background())
+	s.Error(err)
+	s.Zero(got)
+}
+
+func (s *RelayImplTestSuite) TestRunFinishesBatchWhenStopped() {
+	sink := &blockingSink{sending: make(chan uint, 1), release: make(chan struct{})}
+	relay := NewRelay(&config.Config{Outbox: config.OutboxConfig{PollInterval: time.Hour, BatchSize: 10}},
+		s.mockOutboxRepo, []Sink{sink})
+	s.mockOutboxRepo.EXPECT().GetPendingEvents(mock.Anything, 10).Return([]*entity.OutboxEvent{
+		{ID: 1, AggregateType: entity.AggregateTypeCustomer, AggregateID: 1},
+	}, nil).Once()
+	s.mockOutboxRepo.EXPECT().MarkDispatched(mock.Anything, uint(1)).Return(nil).Once()
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		relay.Run(context.Background(), stop)
+	}()
+
+	s.Equal(uint(1), <-sink.sending)
+	close(stop)
+	close(sink.release)
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		s.Fail("relay did not stop")
+	}
+}
+
+func (s *RelayImplTestSuite) TestRunAbortsBatchWhenCancelled() {
+	sink := &blockingSink{sending: make(chan uint, 1), release: make(chan struct{})}
+	relay := NewRelay(&config.Config{Outbox: config.OutboxConfig{PollInterval: time.Hour, BatchSize: 10, MaxAttempts: 3}},
+		s.mockOutboxRepo, []Sink{sink})
+	s.mockOutboxRepo.EXPECT().GetPendingEvents(mock.Anything, 10).Return([]*entity.OutboxEvent{
+		{ID: 1, AggregateType: entity.AggregateTypeCustomer, AggregateID: 1},
+	}, nil).Once()
+	s.mockOutboxRepo.EXPECT().MarkFailed(mock.Anything, uint(1), "blocking sink: context canceled", mock.Anything).Return(nil).Once()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		relay.Run(ctx, make(chan struct{}))
+	}()
+
+	s.Equal(uint(1), <-sink.sending)
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		s.Fail("relay did not stop")
+	}
+}
+
+func TestRelayImplTestSuite(t *testing.T) {
+	suite.Run(t, new(RelayImplTestSuite))
+}
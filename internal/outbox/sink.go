@@ -0,0 +1,41 @@
+package outbox
+
+import (
+	"context"
+	"crud-customer/config"
+	"crud-customer/internal/entity"
+	"fmt"
+)
+
+// Sink receives outbox events from the relay. Deliveries are at-least-once,
+// so a sink may see the same event more than once and should be idempotent
+// on OutboxEvent.ID.
+type Sink interface {
+	Name() string
+	Send(ctx context.Context, event *entity.OutboxEvent) error
+}
+
+type SinkFactory func(cfg *config.Config) (Sink, error)
+
+var sinkFactories = map[string]SinkFactory{}
+
+// RegisterSink makes a sink available under name for the outbox.sinks config.
+func RegisterSink(name string, factory SinkFactory) {
+	sinkFactories[name] = factory
+}
+
+func NewSinks(cfg *config.Config) ([]Sink, error) {
+	var sinks []Sink
+	for _, name := range cfg.Outbox.Sinks {
+		factory, ok := sinkFactories[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown outbox sink: %s", name)
+		}
+		sink, err := factory(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("error creating outbox sink %s: %w", name, err)
+		}
+		sinks = append(sinks, sink)
+	}
+	return sinks, nil
+}
@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+	"crud-customer/internal/entity"
+	"time"
+)
+
+type APIKey interface {
+	CreateAPIKey(ctx context.Context, apiKey *entity.APIKey) (*uint, error)
+	GetAPIKeyByPrefix(ctx context.Context, prefix string) (*entity.APIKey, error)
+	GetAllAPIKey(ctx context.Context) ([]*entity.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id uint, revokedAt time.Time) error
+	UpdateLastUsed(ctx context.Context, id uint, lastUsedAt time.Time) error
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"crud-customer/config"
+	"crud-customer/internal/entity"
+	"gorm.io/gorm"
+	"time"
+)
+
+type apiKeyImpl struct {
+	db  *gorm.DB
+	cfg *config.Config
+}
+
+func (a *apiKeyImpl) CreateAPIKey(ctx context.Context, apiKey *entity.APIKey) (*uint, error) {
+	result := a.db.WithContext(ctx).Create(apiKey)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &apiKey.ID, nil
+}
+
+func (a *apiKeyImpl) GetAPIKeyByPrefix(ctx context.Context, prefix string) (*entity.APIKey, error) {
+	var apiKey entity.APIKey
+	result := a.db.WithContext(ctx).Where("prefix = ?", prefix).First(&apiKey)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &apiKey, nil
+}
+
+func (a *apiKeyImpl) GetAllAPIKey(ctx context.Context) ([]*entity.APIKey, error) {
+	var apiKeys []*entity.APIKey
+	result := a.db.WithContext(ctx).Order("id").Find(&apiKeys)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return apiKeys, nil
+}
+
+func (a *apiKeyImpl) RevokeAPIKey(ctx context.Context, id uint, revokedAt time.Time) error {
+	result := a.db.WithContext(ctx).Model(&entity.APIKey{}).Where("id = ? AND revoked_at IS NULL", id).Update("revoked_at", revokedAt)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (a *apiKeyImpl) UpdateLastUsed(ctx context.Context, id uint, lastUsedAt time.Time) error {
+	return a.db.WithContext(ctx).Model(&entity.APIKey{}).Where("id = ?", id).Update("last_used_at", lastUsedAt).Error
+}
+
+func NewAPIKey(db *gorm.DB, cfg *config.Config) APIKey {
+	return &apiKeyImpl{
+		db:  db,
+		cfg: cfg,
+	}
+}
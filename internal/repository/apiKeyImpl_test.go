@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"crud-customer/config"
+	"crud-customer/internal/entity"
+	"crud-customer/util/testhelper"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+	"os"
+	"testing"
+	"time"
+)
+
+type APIKeyImplTestSuite struct {
+	suite.Suite
+	apiKey    APIKey
+	tmpDBFile *os.File
+	db        *gorm.DB
+	tx        *gorm.DB
+}
+
+func (s *APIKeyImplTestSuite) SetupSuite() {
+	f, err := os.CreateTemp("", "test.*.db")
+	if err != nil {
+		panic(err)
+	}
+	s.tmpDBFile = f
+	s.db = testhelper.NewMigratedDB(f.Name())
+}
+
+func (s *APIKeyImplTestSuite) TearDownSuite() {
+	os.Remove(s.tmpDBFile.Name())
+	s.db = nil
+}
+
+func (s *APIKeyImplTestSuite) SetupTest() {
+	s.tx = s.db.Begin()
+	s.apiKey = NewAPIKey(s.tx, &config.Config{})
+}
+
+func (s *APIKeyImplTestSuite) TearDownTest() {
+	s.tx.Rollback()
+	s.apiKey = nil
+}
+
+func (s *APIKeyImplTestSuite) createAPIKey() {
+	if err := s.tx.Create(&entity.APIKey{
+		Name:   "ci",
+		Prefix: "0123456789ab",
+		Hash:   "hash",
+		Scopes: []string{"customers:read"},
+	}).Error; err != nil {
+		panic(err)
+	}
+}
+
+func (s *APIKeyImplTestSuite) TestCreateAPIKeySuccess() {
+	got, err := s.apiKey.CreateAPIKey(context.Background(), &entity.APIKey{
+		Name:   "ci",
+		Prefix: "0123456789ab",
+		Hash:   "hash",
+	})
+	s.NoError(err)
+	s.Equal(uint(1), *got)
+}
+
+func (s *APIKeyImplTestSuite) TestCreateAPIKeyDuplicatePrefixError() {
+	s.createAPIKey()
+
+	got, err := s.apiKey.CreateAPIKey(context.Background(), &entity.APIKey{
+		Name:   "other",
+		Prefix: "0123456789ab",
+		Hash:   "hash",
+	})
+	s.Error(err)
+	s.Nil(got)
+}
+
+func (s *APIKeyImplTestSuite) TestGetAPIKeyByPrefixSuccess() {
+	s.createAPIKey()
+
+	got, err := s.apiKey.GetAPIKeyByPrefix(context.Background(), "0123456789ab")
+	s.NoError(err)
+	s.Equal("ci", got.Name)
+	s.Equal([]string{"customers:read"}, got.Scopes)
+}
+
+func (s *APIKeyImplTestSuite) TestGetAPIKeyByPrefixError() {
+	got, err := s.apiKey.GetAPIKeyByPrefix(context.Background(), "0123456789ab")
+	s.ErrorIs(err, gorm.ErrRecordNotFound)
+	s.Nil(got)
+}
+
+func (s *APIKeyImplTestSuite) TestGetAllAPIKeySuccess() {
+	s.createAPIKey()
+
+	got, err := s.apiKey.GetAllAPIKey(context.Background())
+	s.NoError(err)
+	s.Len(got, 1)
+}
+
+func (s *APIKeyImplTestSuite) TestRevokeAPIKeySuccess() {
+	s.createAPIKey()
+	now := time.Now()
+
+	s.NoError(s.apiKey.RevokeAPIKey(context.Background(), 1, now))
+	got, err := s.apiKey.GetAPIKeyByPrefix(context.Background(), "0123456789ab")
+	s.NoError(err)
+	s.False(got.IsActive(now))
+
+	s.ErrorIs(s.apiKey.RevokeAPIKey(context.Background(), 1, now), gorm.ErrRecordNotFound)
+}
+
+func (s *APIKeyImplTestSuite) TestUpdateLastUsedSuccess() {
+	s.createAPIKey()
+	now := time.Now()
+
+	s.NoError(s.apiKey.UpdateLastUsed(context.Background(), 1, now))
+	got, err := s.apiKey.GetAPIKeyByPrefix(context.Background(), "0123456789ab")
+	s.NoError(err)
+	s.WithinDuration(now, *got.LastUsedAt, time.Second)
+}
+
+func TestAPIKeyImplSuite(t *testing.T) {
+	suite.Run(t, new(APIKeyImplTestSuite))
+}
@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+	"crud-customer/internal/entity"
+)
+
+// Audit reads the audit log. Entries are only ever added, by the customer
+// repository in the transaction of the mutation they record.
+type Audit interface {
+	// GetAuditEntriesByCustomerID returns the entries of a customer, oldest
+	// first.
+	GetAuditEntriesByCustomerID(ctx context.Context, customerID uint) ([]*entity.AuditEntry, error)
+	// ScanAuditEntries calls fn for every entry of every tenant, in ID order,
+	// reading from a cursor inside a single read transaction.
+	ScanAuditEntries(ctx context.Context, fn func(entry *entity.AuditEntry) error) error
+	// ReencryptAuditEntries rewrites the diffs of up to limit entries with an
+	// ID above afterID under the primary key. It returns the last ID
+	// rewritten and the number of entries.
+	ReencryptAuditEntries(ctx context.Context, afterID uint, limit int) (uint, int, error)
+}
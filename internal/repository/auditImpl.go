@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"context"
+	"crud-customer/config"
+	"crud-customer/internal/entity"
+	"crud-customer/pkg/auth"
+	"crud-customer/pkg/reqctx"
+	"crud-customer/pkg/tenant"
+	"encoding/json"
+	"errors"
+	"gorm.io/gorm"
+	"reflect"
+	"time"
+)
+
+// auditActorSystem is recorded for mutations made outside a request, such as
+// seeding or maintenance commands.
+const auditActorSystem = "system"
+
+type auditImpl struct {
+	db  *gorm.DB
+	cfg *config.Config
+}
+
+func (a *auditImpl) GetAuditEntriesByCustomerID(ctx context.Context, customerID uint) ([]*entity.AuditEntry, error) {
+	var entries []*entity.AuditEntry
+	result := a.db.WithContext(ctx).
+		Where("tenant_id = ? AND customer_id = ?", tenant.FromContext(ctx), customerID).
+		Order("id").Find(&entries)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return entries, nil
+}
+
+func (a *auditImpl) ScanAuditEntries(ctx context.Context, fn func(entry *entity.AuditEntry) error) error {
+	return a.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		rows, err := tx.Model(&entity.AuditEntry{}).Order("id").Rows()
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var entry entity.AuditEntry
+			if err := tx.ScanRows(rows, &entry); err != nil {
+				return err
+			}
+			if err := fn(&entry); err != nil {
+				return err
+			}
+		}
+		return rows.Err()
+	})
+}
+
+func (a *auditImpl) ReencryptAuditEntries(ctx context.Context, afterID uint, limit int) (uint, int, error) {
+	var entries []*entity.AuditEntry
+	err := a.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id > ?", afterID).Order("id").Limit(limit).Find(&entries).Error; err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			// The append-only triggers allow rewriting diff and nothing else.
+			if err := tx.Model(entry).Select("diff").Updates(entry).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil || len(entries) == 0 {
+		return afterID, 0, err
+	}
+	return entries[len(entries)-1].ID, len(entries), nil
+}
+
+type auditChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// addAuditEntry records a customer mutation on tx, chained to the last
+// entry. It must be called inside the transaction that performs the
+// mutation; the mutation holds SQLite's write lock, so no other entry can be
+// appended between reading the last hash and inserting. before is nil for a
+// create and after is nil for a delete.
+func addAuditEntry(ctx context.Context, tx *gorm.DB, action string, before *entity.Customer, after *entity.Customer) error {
+	diff, err := customerDiff(before, after)
+	if err != nil {
+		return err
+	}
+
+	var last entity.AuditEntry
+	err = tx.Select("hash").Order("id DESC").Take(&last).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	customer := after
+	if customer == nil {
+		customer = before
+	}
+	actor := auditActorSystem
+	if principal, ok := auth.PrincipalFromContext(ctx); ok && principal != nil {
+		actor = principal.Subject
+	}
+	entry := &entity.AuditEntry{
+		TenantID:   customer.TenantID,
+		CustomerID: customer.ID,
+		Action:     action,
+		Actor:      actor,
+		RequestID:  reqctx.RequestIDFromContext(ctx),
+		SourceIP:   reqctx.SourceIPFromContext(ctx),
+		Diff:       diff,
+		// Truncated to what survives a round trip through the database, so
+		// the hash can be recomputed from the stored entry.
+		CreatedAt: time.Now().UTC().Truncate(time.Microsecond),
+		PrevHash:  last.Hash,
+	}
+	entry.Hash = entry.ComputeHash()
+	return tx.Create(entry).Error
+}
+
+// customerDiff returns the JSON diff of the customer fields changed between
+// before and after, keyed by their JSON names.
+func customerDiff(before *entity.Customer, after *entity.Customer) (string, error) {
+	beforeFields, err := customerFields(before)
+	if err != nil {
+		return "", err
+	}
+	afterFields, err := customerFields(after)
+	if err != nil {
+		return "", err
+	}
+
+	diff := map[string]auditChange{}
+	for key, value := range beforeFields {
+		if !reflect.DeepEqual(value, afterFields[key]) {
+			diff[key] = auditChange{Before: value, After: afterFields[key]}
+		}
+	}
+	for key, value := range afterFields {
+		if _, ok := beforeFields[key]; !ok {
+			diff[key] = auditChange{After: value}
+		}
+	}
+	delete(diff, "id")
+
+	data, err := json.Marshal(diff)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func customerFields(customer *entity.Customer) (map[string]interface{}, error) {
+	fields := map[string]interface{}{}
+	if customer == nil {
+		return fields, nil
+	}
+	data, err := json.Marshal(customer)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+func NewAudit(db *gorm.DB, cfg *config.Config) Audit {
+	return &auditImpl{
+		db:  db,
+		cfg: cfg,
+	}
+}
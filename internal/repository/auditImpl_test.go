@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"context"
+	"crud-customer/config"
+	"crud-customer/internal/entity"
+	"crud-customer/pkg/auth"
+	"crud-customer/pkg/fieldcrypt"
+	"crud-customer/pkg/reqctx"
+	"crud-customer/pkg/tenant"
+	"crud-customer/util/testhelper"
+	"crud-customer/util/typehelper"
+	"fmt"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+	"os"
+	"strings"
+	"testing"
+)
+
+type AuditImplTestSuite struct {
+	suite.Suite
+	audit     Audit
+	customer  Customer
+	tmpDBFile *os.File
+	db        *gorm.DB
+	tx        *gorm.DB
+}
+
+func (s *AuditImplTestSuite) SetupSuite() {
+	f, err := os.CreateTemp("", "test.*.db")
+	if err != nil {
+		panic(err)
+	}
+	s.tmpDBFile = f
+	s.db = testhelper.NewMigratedDB(f.Name())
+}
+
+func (s *AuditImplTestSuite) TearDownSuite() {
+	os.Remove(s.tmpDBFile.Name())
+	s.db = nil
+}
+
+func (s *AuditImplTestSuite) SetupTest() {
+	s.tx = s.db.Begin()
+	s.audit = NewAudit(s.tx, &config.Config{})
+	s.customer = NewCustomer(s.tx, &config.Config{})
+}
+
+func (s *AuditImplTestSuite) TearDownTest() {
+	s.tx.Rollback()
+	s.audit = nil
+	s.customer = nil
+}
+
+func (s *AuditImplTestSuite) requestContext(tenantID string) context.Context {
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{Type: auth.PrincipalTypeAPIKey, Subject: "api_key:1"})
+	ctx = reqctx.WithRequestID(ctx, "req-1")
+	ctx = reqctx.WithSourceIP(ctx, "10.0.0.1")
+	return tenant.WithTenant(ctx, tenantID)
+}
+
+func (s *AuditImplTestSuite) createUpdateDelete(ctx context.Context) uint {
+	id, err := s.customer.CreateCustomer(ctx, &entity.Customer{
+		Name: typehelper.GetPointer("John Doe"),
+		Age:  typehelper.GetPointer(uint(20)),
+	})
+	s.Require().NoError(err)
+	_, err = s.customer.UpdateCustomer(ctx, *id, &entity.Customer{
+		Name: typehelper.GetPointer("John Doe"),
+		Age:  typehelper.GetPointer(uint(21)),
+	})
+	s.Require().NoError(err)
+	s.Require().NoError(s.customer.DeleteCustomer(ctx, *id))
+	return *id
+}
+
+func (s *AuditImplTestSuite) TestGetAuditEntriesByCustomerIDSuccess() {
+	ctx := s.requestContext("acme")
+	id := s.createUpdateDelete(ctx)
+
+	got, err := s.audit.GetAuditEntriesByCustomerID(ctx, id)
+	s.NoError(err)
+	s.Len(got, 3)
+
+	s.Equal(entity.AuditActionCreate, got[0].Action)
+	s.Equal(`{"age":{"before":null,"after":20},"name":{"before":null,"after":"John Doe"}}`, got[0].Diff)
+	s.Equal(entity.AuditActionUpdate, got[1].Action)
+	s.Equal(`{"age":{"before":20,"after":21}}`, got[1].Diff)
+	s.Equal(entity.AuditActionDelete, got[2].Action)
+	s.Equal(`{"age":{"before":21,"after":null},"name":{"before":"John Doe","after":null}}`, got[2].Diff)
+
+	for i, entry := range got {
+		s.Equal("acme", entry.TenantID)
+		s.Equal("api_key:1", entry.Actor)
+		s.Equal("req-1", entry.RequestID)
+		s.Equal("10.0.0.1", entry.SourceIP)
+		s.Equal(entry.ComputeHash(), entry.Hash)
+		if i > 0 {
+			s.Equal(got[i-1].Hash, entry.PrevHash)
+		}
+	}
+	s.Empty(got[0].PrevHash)
+
+	got, err = s.audit.GetAuditEntriesByCustomerID(s.requestContext("globex"), id)
+	s.NoError(err)
+	s.Empty(got)
+}
+
+func (s *AuditImplTestSuite) TestRestoreAuditEntry() {
+	ctx := s.requestContext("acme")
+	id := s.createUpdateDelete(ctx)
+	_, err := s.customer.RestoreCustomer(ctx, id)
+	s.Require().NoError(err)
+
+	got, err := s.audit.GetAuditEntriesByCustomerID(ctx, id)
+	s.NoError(err)
+	s.Len(got, 4)
+	s.Equal(entity.AuditActionRestore, got[3].Action)
+	s.Equal(`{"age":{"before":null,"after":21},"name":{"before":null,"after":"John Doe"}}`, got[3].Diff)
+	s.Equal(got[2].Hash, got[3].PrevHash)
+}
+
+func (s *AuditImplTestSuite) TestAuditEntryWithoutRequest() {
+	_, err := s.customer.CreateCustomer(context.Background(), &entity.Customer{
+		Name: typehelper.GetPointer("John Doe"),
+		Age:  typehelper.GetPointer(uint(20)),
+	})
+	s.NoError(err)
+
+	var entry entity.AuditEntry
+	s.NoError(s.tx.First(&entry).Error)
+	s.Equal("system", entry.Actor)
+	s.Equal(tenant.DefaultTenant, entry.TenantID)
+	s.Empty(entry.RequestID)
+}
+
+func (s *AuditImplTestSuite) TestScanAuditEntriesSuccess() {
+	s.createUpdateDelete(s.requestContext("acme"))
+	s.createUpdateDelete(s.requestContext("globex"))
+
+	var got []uint
+	err := s.audit.ScanAuditEntries(context.Background(), func(entry *entity.AuditEntry) error {
+		got = append(got, entry.ID)
+		return nil
+	})
+	s.NoError(err)
+	s.Equal([]uint{1, 2, 3, 4, 5, 6}, got)
+}
+
+func (s *AuditImplTestSuite) TestScanAuditEntriesCallbackError() {
+	s.createUpdateDelete(s.requestContext("acme"))
+
+	err := s.audit.ScanAuditEntries(context.Background(), func(entry *entity.AuditEntry) error {
+		return fmt.Errorf("error")
+	})
+	s.Error(err)
+}
+
+func (s *AuditImplTestSuite) TestAppendOnly() {
+	s.createUpdateDelete(s.requestContext("acme"))
+
+	s.ErrorContains(s.tx.Exec("UPDATE audit_entries SET actor = 'mallory' WHERE id = 1").Error, "append-only")
+	s.ErrorContains(s.tx.Exec("DELETE FROM audit_entries WHERE id = 3").Error, "append-only")
+}
+
+func (s *AuditImplTestSuite) TestReencryptAuditEntriesSuccess() {
+	ctx := s.requestContext("acme")
+	id := s.createUpdateDelete(ctx)
+
+	fieldcrypt.SetKeyring(testhelper.NewKeyring("k1", "k1"))
+	defer fieldcrypt.SetKeyring(nil)
+
+	lastID, n, err := s.audit.ReencryptAuditEntries(context.Background(), 0, 10)
+	s.NoError(err)
+	s.Equal(uint(3), lastID)
+	s.Equal(3, n)
+
+	var stored []string
+	s.NoError(s.tx.Raw("SELECT diff FROM audit_entries ORDER BY id").Scan(&stored).Error)
+	for _, diff := range stored {
+		s.True(strings.HasPrefix(diff, "enc2.k1."))
+	}
+
+	got, err := s.audit.GetAuditEntriesByCustomerID(ctx, id)
+	s.NoError(err)
+	for _, entry := range got {
+		s.Equal(entry.ComputeHash(), entry.Hash)
+	}
+}
+
+func TestAuditImplSuite(t *testing.T) {
+	suite.Run(t, new(AuditImplTestSuite))
+}
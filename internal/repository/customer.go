@@ -9,6 +9,29 @@ type Customer interface {
 	CreateCustomer(ctx context.Context, customer *entity.Customer) (*uint, error)
 	UpdateCustomer(ctx context.Context, id uint, customer *entity.Customer) (*entity.Customer, error)
 	GetCustomerByID(ctx context.Context, id uint) (*entity.Customer, error)
+	// GetCustomersByIDs returns the customers that exist among ids, in no
+	// particular order.
+	GetCustomersByIDs(ctx context.Context, ids []uint) ([]*entity.Customer, error)
+	// DeleteCustomer soft-deletes the customer, which is then hidden from
+	// every other method until restored.
 	DeleteCustomer(ctx context.Context, id uint) error
+	// RestoreCustomer undeletes the customer. It returns
+	// gorm.ErrRecordNotFound when there is no deleted customer with id.
+	RestoreCustomer(ctx context.Context, id uint) (*entity.Customer, error)
 	GetAllCustomer(ctx context.Context) ([]*entity.Customer, error)
+	// UpsertCustomerByExternalID creates the customer or updates the one with
+	// the same ExternalSource and ExternalID, reporting whether it was created.
+	// It returns ErrCustomerDeleted when that customer is deleted.
+	UpsertCustomerByExternalID(ctx context.Context, customer *entity.Customer) (*entity.Customer, bool, error)
+	// ExportCustomers calls fn for every customer matching filter, in ID
+	// order. Customers are read in pages after the last ID seen, all inside
+	// one read transaction, so the export sees a consistent snapshot with
+	// constant memory. With WAL journaling, writers go on during the export;
+	// without it, they wait for it to end.
+	ExportCustomers(ctx context.Context, filter entity.CustomerFilter, fn func(customer *entity.Customer) error) error
+	// ReencryptCustomers rewrites the encrypted fields of up to limit
+	// customers with an ID above afterID, in every tenant and deleted or
+	// not, under the primary key, and recomputes their blind indexes. It
+	// returns the last ID rewritten and the number of customers.
+	ReencryptCustomers(ctx context.Context, afterID uint, limit int) (uint, int, error)
 }
@@ -4,57 +4,269 @@ import (
 	"context"
 	"crud-customer/config"
 	"crud-customer/internal/entity"
+	"crud-customer/pkg/fieldcrypt"
+	"crud-customer/pkg/tenant"
+	"errors"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// exportPageSize is the number of customers ExportCustomers reads at once.
+const exportPageSize = 500
+
+// ErrCustomerDeleted is returned by UpsertCustomerByExternalID when the
+// customer with the external ID is deleted.
+var ErrCustomerDeleted = errors.New("customer is deleted")
+
 type customerImpl struct {
 	db  *gorm.DB
 	cfg *config.Config
 }
 
 func (c *customerImpl) CreateCustomer(ctx context.Context, customer *entity.Customer) (*uint, error) {
-	result := c.db.WithContext(ctx).Create(customer)
-	if result.Error != nil {
-		return nil, result.Error
+	customer.TenantID = tenant.FromContext(ctx)
+	customer.UpdateNameIndex()
+	err := c.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(customer).Error; err != nil {
+			return err
+		}
+		if err := addAuditEntry(ctx, tx, entity.AuditActionCreate, nil, customer); err != nil {
+			return err
+		}
+		return addOutboxEvent(tx, entity.EventCustomerCreated, customer)
+	})
+	if err != nil {
+		return nil, err
 	}
 	return &customer.ID, nil
 }
 
 func (c *customerImpl) UpdateCustomer(ctx context.Context, id uint, customer *entity.Customer) (*entity.Customer, error) {
-	result := c.db.WithContext(ctx).Raw("UPDATE customers set name = ?, age = ? where id = ? RETURNING *", customer.Name, customer.Age, id).Scan(&customer)
+	updated := &entity.Customer{TenantID: tenant.FromContext(ctx), Name: customer.Name, Age: customer.Age}
+	updated.UpdateNameIndex()
+	err := c.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		before := &entity.Customer{}
+		if err := tx.Scopes(scopeTenant(ctx)).First(before, id).Error; err != nil {
+			return err
+		}
 
-	if result.Error != nil {
-		return nil, result.Error
+		// Updating from a struct rather than a map, so Name goes through
+		// its serializer.
+		result := tx.Model(updated).Clauses(clause.Returning{}).Scopes(scopeTenant(ctx)).Where("id = ?", id).
+			Select("name", "name_index", "age").Updates(updated)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		if err := addAuditEntry(ctx, tx, entity.AuditActionUpdate, before, updated); err != nil {
+			return err
+		}
+		return addOutboxEvent(tx, entity.EventCustomerUpdated, updated)
+	})
+	if err != nil {
+		return nil, err
 	}
-	return customer, nil
+	return updated, nil
 }
 
 func (c *customerImpl) GetCustomerByID(ctx context.Context, id uint) (*entity.Customer, error) {
 	var customer entity.Customer
-	result := c.db.WithContext(ctx).First(&customer, id)
+	result := c.db.WithContext(ctx).Scopes(scopeTenant(ctx)).First(&customer, id)
 	if result.Error != nil {
 		return nil, result.Error
 	}
 	return &customer, nil
 }
 
-func (c *customerImpl) DeleteCustomer(ctx context.Context, id uint) error {
-	result := c.db.WithContext(ctx).Delete(&entity.Customer{}, id)
+func (c *customerImpl) GetCustomersByIDs(ctx context.Context, ids []uint) ([]*entity.Customer, error) {
+	var customers []*entity.Customer
+	result := c.db.WithContext(ctx).Scopes(scopeTenant(ctx)).Where("id IN ?", ids).Find(&customers)
 	if result.Error != nil {
-		return result.Error
+		return nil, result.Error
+	}
+	return customers, nil
+}
+
+func (c *customerImpl) DeleteCustomer(ctx context.Context, id uint) error {
+	return c.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		before := &entity.Customer{TenantID: tenant.FromContext(ctx)}
+		result := tx.Clauses(clause.Returning{}).Scopes(scopeTenant(ctx)).Delete(before, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		if err := addAuditEntry(ctx, tx, entity.AuditActionDelete, before, nil); err != nil {
+			return err
+		}
+		return addOutboxEvent(tx, entity.EventCustomerDeleted, &entity.Customer{ID: id, TenantID: tenant.FromContext(ctx)})
+	})
+}
+
+func (c *customerImpl) RestoreCustomer(ctx context.Context, id uint) (*entity.Customer, error) {
+	restored := &entity.Customer{}
+	err := c.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Scopes(scopeTenant(ctx)).Where("deleted_at IS NOT NULL").First(restored, id).Error; err != nil {
+			return err
+		}
+		result := tx.Unscoped().Model(restored).Scopes(scopeTenant(ctx)).Update("deleted_at", nil)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		if err := addAuditEntry(ctx, tx, entity.AuditActionRestore, nil, restored); err != nil {
+			return err
+		}
+		return addOutboxEvent(tx, entity.EventCustomerRestored, restored)
+	})
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	return restored, nil
 }
 
 func (c *customerImpl) GetAllCustomer(ctx context.Context) ([]*entity.Customer, error) {
 	var customers []*entity.Customer
-	result := c.db.WithContext(ctx).Find(&customers)
+	result := c.db.WithContext(ctx).Scopes(scopeTenant(ctx)).Find(&customers)
 	if result.Error != nil {
 		return nil, result.Error
 	}
 	return customers, nil
 }
 
+func (c *customerImpl) UpsertCustomerByExternalID(ctx context.Context, customer *entity.Customer) (*entity.Customer, bool, error) {
+	customer.TenantID = tenant.FromContext(ctx)
+	customer.UpdateNameIndex()
+	created := false
+	err := c.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// The insert takes SQLite's write lock, so nothing can slip in
+		// between it and the update below.
+		result := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "tenant_id"}, {Name: "external_source"}, {Name: "external_id"}},
+			DoNothing: true,
+		}).Create(customer)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 1 {
+			created = true
+			if err := addAuditEntry(ctx, tx, entity.AuditActionCreate, nil, customer); err != nil {
+				return err
+			}
+			return addOutboxEvent(tx, entity.EventCustomerCreated, customer)
+		}
+
+		// A deleted customer still holds its external ID, and has to be
+		// restored before it can be upserted.
+		before := &entity.Customer{}
+		if err := tx.Unscoped().Scopes(scopeTenant(ctx)).
+			Where("external_source = ? AND external_id = ?", customer.ExternalSource, customer.ExternalID).
+			First(before).Error; err != nil {
+			return err
+		}
+		if before.DeletedAt.Valid {
+			return ErrCustomerDeleted
+		}
+		result = tx.Model(customer).Clauses(clause.Returning{}).Scopes(scopeTenant(ctx)).
+			Where("external_source = ? AND external_id = ?", customer.ExternalSource, customer.ExternalID).
+			Select("name", "name_index", "age").Updates(customer)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		if err := addAuditEntry(ctx, tx, entity.AuditActionUpdate, before, customer); err != nil {
+			return err
+		}
+		return addOutboxEvent(tx, entity.EventCustomerUpdated, customer)
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return customer, created, nil
+}
+
+func (c *customerImpl) ExportCustomers(ctx context.Context, filter entity.CustomerFilter, fn func(customer *entity.Customer) error) error {
+	return c.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var lastID uint
+		for {
+			var customers []*entity.Customer
+			err := tx.Scopes(scopeTenant(ctx), filterCustomers(filter)).
+				Where("id > ?", lastID).Order("id").Limit(exportPageSize).Find(&customers).Error
+			if err != nil {
+				return err
+			}
+			for _, customer := range customers {
+				if err := fn(customer); err != nil {
+					return err
+				}
+			}
+			if len(customers) < exportPageSize {
+				return nil
+			}
+			lastID = customers[len(customers)-1].ID
+		}
+	})
+}
+
+func (c *customerImpl) ReencryptCustomers(ctx context.Context, afterID uint, limit int) (uint, int, error) {
+	var customers []*entity.Customer
+	err := c.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Deleted customers are rewritten too, so they can still be read once
+		// restored.
+		if err := tx.Unscoped().Where("id > ?", afterID).Order("id").Limit(limit).Find(&customers).Error; err != nil {
+			return err
+		}
+		for _, customer := range customers {
+			customer.UpdateNameIndex()
+			if err := tx.Unscoped().Model(customer).Select("name", "name_index").Updates(customer).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil || len(customers) == 0 {
+		return afterID, 0, err
+	}
+	return customers[len(customers)-1].ID, len(customers), nil
+}
+
+// scopeTenant restricts a query to the tenant of ctx. Every customer query
+// goes through it, so rows of other tenants can be neither read nor written.
+func scopeTenant(ctx context.Context) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("customers.tenant_id = ?", tenant.FromContext(ctx))
+	}
+}
+
+func filterCustomers(filter entity.CustomerFilter) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if filter.Name != nil {
+			// Rows written before encryption was enabled have no index until
+			// rotate-keys backfills it, and still hold their name as plaintext.
+			name := fieldcrypt.EscapePlaintext(*filter.Name)
+			if index := fieldcrypt.BlindIndex(*filter.Name, entity.CustomerNameIndexPurpose); index != nil {
+				db = db.Where("name_index = ? OR (name_index IS NULL AND name = ?)", *index, name)
+			} else {
+				db = db.Where("name = ?", name)
+			}
+		}
+		if filter.MinAge != nil {
+			db = db.Where("age >= ?", *filter.MinAge)
+		}
+		if filter.MaxAge != nil {
+			db = db.Where("age <= ?", *filter.MaxAge)
+		}
+		return db
+	}
+}
+
 func NewCustomer(db *gorm.DB, cfg *config.Config) Customer {
 	return &customerImpl{
 		db:  db,
@@ -4,11 +4,16 @@ import (
 	"context"
 	"crud-customer/config"
 	"crud-customer/internal/entity"
+	"crud-customer/pkg/fieldcrypt"
+	"crud-customer/pkg/tenant"
+	"crud-customer/util/testhelper"
 	"crud-customer/util/typehelper"
-	"github.com/glebarez/sqlite"
+	"fmt"
 	"github.com/stretchr/testify/suite"
 	"gorm.io/gorm"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -26,14 +31,7 @@ func (s *CustomerImplTestSuite) SetupSuite() {
 		panic(err)
 	}
 	s.tmpDBFile = f
-	db, err := gorm.Open(sqlite.Open(f.Name()), &gorm.Config{})
-	if err != nil {
-		panic(err)
-	}
-	s.db = db
-	if err := s.db.AutoMigrate(&entity.Customer{}); err != nil {
-		panic(err)
-	}
+	s.db = testhelper.NewMigratedDB(f.Name())
 }
 
 func (s *CustomerImplTestSuite) TearDownSuite() {
@@ -85,9 +83,10 @@ func (s *CustomerImplTestSuite) TestUpdateCustomerSuccess() {
 	}
 
 	want := &entity.Customer{
-		ID:   1,
-		Name: typehelper.GetPointer("John Dee"),
-		Age:  typehelper.GetPointer(uint(20)),
+		ID:       1,
+		TenantID: tenant.DefaultTenant,
+		Name:     typehelper.GetPointer("John Dee"),
+		Age:      typehelper.GetPointer(uint(20)),
 	}
 
 	got, err := s.customer.UpdateCustomer(context.Background(), 1, &entity.Customer{
@@ -125,9 +124,10 @@ func (s *CustomerImplTestSuite) TestGetCustomerByIDSuccess() {
 	}
 
 	want := &entity.Customer{
-		ID:   1,
-		Name: typehelper.GetPointer("John Doe"),
-		Age:  typehelper.GetPointer(uint(20)),
+		ID:       1,
+		TenantID: tenant.DefaultTenant,
+		Name:     typehelper.GetPointer("John Doe"),
+		Age:      typehelper.GetPointer(uint(20)),
 	}
 
 	got, err := s.customer.GetCustomerByID(context.Background(), 1)
@@ -141,6 +141,25 @@ func (s *CustomerImplTestSuite) TestGetCustomerByIDError() {
 	s.Nil(got)
 }
 
+func (s *CustomerImplTestSuite) TestGetCustomersByIDsSuccess() {
+	for _, name := range []string{"John Doe", "Jane Doe"} {
+		if err := s.tx.Create(&entity.Customer{Name: typehelper.GetPointer(name), Age: typehelper.GetPointer(uint(20))}).Error; err != nil {
+			panic(err)
+		}
+	}
+
+	got, err := s.customer.GetCustomersByIDs(context.Background(), []uint{2, 3})
+	s.NoError(err)
+	s.Equal([]*entity.Customer{
+		{
+			ID:       2,
+			TenantID: tenant.DefaultTenant,
+			Name:     typehelper.GetPointer("Jane Doe"),
+			Age:      typehelper.GetPointer(uint(20)),
+		},
+	}, got)
+}
+
 func (s *CustomerImplTestSuite) TestDeleteCustomerSuccess() {
 	result := s.tx.Create(&entity.Customer{
 		Name: typehelper.GetPointer("John Doe"),
@@ -152,6 +171,98 @@ func (s *CustomerImplTestSuite) TestDeleteCustomerSuccess() {
 
 	err := s.customer.DeleteCustomer(context.Background(), 1)
 	s.NoError(err)
+
+	_, err = s.customer.GetCustomerByID(context.Background(), 1)
+	s.ErrorIs(err, gorm.ErrRecordNotFound)
+	got, err := s.customer.GetAllCustomer(context.Background())
+	s.NoError(err)
+	s.Empty(got)
+	var deleted entity.Customer
+	s.NoError(s.tx.Unscoped().First(&deleted, 1).Error)
+	s.True(deleted.DeletedAt.Valid)
+}
+
+func (s *CustomerImplTestSuite) TestRestoreCustomerSuccess() {
+	id, err := s.customer.CreateCustomer(context.Background(), &entity.Customer{
+		Name: typehelper.GetPointer("John Doe"),
+		Age:  typehelper.GetPointer(uint(20)),
+	})
+	s.Require().NoError(err)
+	s.Require().NoError(s.customer.DeleteCustomer(context.Background(), *id))
+
+	got, err := s.customer.RestoreCustomer(context.Background(), *id)
+	s.NoError(err)
+	s.Equal(*id, got.ID)
+	s.Equal("John Doe", *got.Name)
+	s.False(got.DeletedAt.Valid)
+
+	got, err = s.customer.GetCustomerByID(context.Background(), *id)
+	s.NoError(err)
+	s.Equal(uint(20), *got.Age)
+
+	var events []entity.OutboxEvent
+	s.NoError(s.tx.Order("id").Find(&events).Error)
+	s.Len(events, 3)
+	s.Equal(entity.EventCustomerRestored, events[2].EventType)
+	s.JSONEq(`{"id":1,"name":"John Doe","age":20}`, events[2].Payload)
+}
+
+func (s *CustomerImplTestSuite) TestRestoreCustomerError() {
+	id, err := s.customer.CreateCustomer(context.Background(), &entity.Customer{
+		Name: typehelper.GetPointer("John Doe"),
+		Age:  typehelper.GetPointer(uint(20)),
+	})
+	s.Require().NoError(err)
+
+	// Customers that are not deleted, do not exist or belong to another
+	// tenant cannot be restored.
+	_, err = s.customer.RestoreCustomer(context.Background(), *id)
+	s.ErrorIs(err, gorm.ErrRecordNotFound)
+	_, err = s.customer.RestoreCustomer(context.Background(), 99)
+	s.ErrorIs(err, gorm.ErrRecordNotFound)
+	s.Require().NoError(s.customer.DeleteCustomer(context.Background(), *id))
+	_, err = s.customer.RestoreCustomer(tenant.WithTenant(context.Background(), "globex"), *id)
+	s.ErrorIs(err, gorm.ErrRecordNotFound)
+
+	var count int64
+	s.NoError(s.tx.Model(&entity.OutboxEvent{}).Where("event_type = ?", entity.EventCustomerRestored).Count(&count).Error)
+	s.Zero(count)
+}
+
+func (s *CustomerImplTestSuite) TestMutationsWriteOutboxEvents() {
+	id, err := s.customer.CreateCustomer(context.Background(), &entity.Customer{
+		Name: typehelper.GetPointer("John Doe"),
+		Age:  typehelper.GetPointer(uint(20)),
+	})
+	s.NoError(err)
+	_, err = s.customer.UpdateCustomer(context.Background(), *id, &entity.Customer{
+		Name: typehelper.GetPointer("John Dee"),
+		Age:  typehelper.GetPointer(uint(21)),
+	})
+	s.NoError(err)
+	s.NoError(s.customer.DeleteCustomer(context.Background(), *id))
+
+	var events []entity.OutboxEvent
+	s.NoError(s.tx.Order("id").Find(&events).Error)
+	s.Len(events, 3)
+	s.Equal([]string{entity.EventCustomerCreated, entity.EventCustomerUpdated, entity.EventCustomerDeleted},
+		[]string{events[0].EventType, events[1].EventType, events[2].EventType})
+	for _, event := range events {
+		s.Equal(entity.AggregateTypeCustomer, event.AggregateType)
+		s.Equal(*id, event.AggregateID)
+	}
+	s.JSONEq(`{"id":1,"name":"John Dee","age":21}`, events[1].Payload)
+}
+
+func (s *CustomerImplTestSuite) TestCreateCustomerErrorWritesNoOutboxEvent() {
+	_, err := s.customer.CreateCustomer(context.Background(), &entity.Customer{
+		Name: typehelper.GetPointer("John Doe"),
+	})
+	s.Error(err)
+
+	var count int64
+	s.NoError(s.tx.Model(&entity.OutboxEvent{}).Count(&count).Error)
+	s.Zero(count)
 }
 
 func (s *CustomerImplTestSuite) TestGetAllCustomerSuccess() {
@@ -165,9 +276,10 @@ func (s *CustomerImplTestSuite) TestGetAllCustomerSuccess() {
 
 	want := []*entity.Customer{
 		{
-			ID:   1,
-			Name: typehelper.GetPointer("John Doe"),
-			Age:  typehelper.GetPointer(uint(20)),
+			ID:       1,
+			TenantID: tenant.DefaultTenant,
+			Name:     typehelper.GetPointer("John Doe"),
+			Age:      typehelper.GetPointer(uint(20)),
 		},
 	}
 
@@ -176,6 +288,420 @@ func (s *CustomerImplTestSuite) TestGetAllCustomerSuccess() {
 	s.Equal(want, got)
 }
 
+func (s *CustomerImplTestSuite) TestUpsertCustomerByExternalIDSuccess() {
+	got, created, err := s.customer.UpsertCustomerByExternalID(context.Background(), &entity.Customer{
+		Name:           typehelper.GetPointer("John Doe"),
+		Age:            typehelper.GetPointer(uint(20)),
+		ExternalSource: typehelper.GetPointer("crm"),
+		ExternalID:     typehelper.GetPointer("A-1"),
+	})
+	s.NoError(err)
+	s.True(created)
+	s.Equal(uint(1), got.ID)
+
+	want := &entity.Customer{
+		ID:             1,
+		TenantID:       tenant.DefaultTenant,
+		Name:           typehelper.GetPointer("John Dee"),
+		Age:            typehelper.GetPointer(uint(21)),
+		ExternalSource: typehelper.GetPointer("crm"),
+		ExternalID:     typehelper.GetPointer("A-1"),
+	}
+	got, created, err = s.customer.UpsertCustomerByExternalID(context.Background(), &entity.Customer{
+		Name:           typehelper.GetPointer("John Dee"),
+		Age:            typehelper.GetPointer(uint(21)),
+		ExternalSource: typehelper.GetPointer("crm"),
+		ExternalID:     typehelper.GetPointer("A-1"),
+	})
+	s.NoError(err)
+	s.False(created)
+	s.Equal(want, got)
+
+	var count int64
+	s.NoError(s.tx.Model(&entity.Customer{}).Count(&count).Error)
+	s.Equal(int64(1), count)
+
+	var events []entity.OutboxEvent
+	s.NoError(s.tx.Order("id").Find(&events).Error)
+	s.Len(events, 2)
+	s.Equal(entity.EventCustomerCreated, events[0].EventType)
+	s.Equal(entity.EventCustomerUpdated, events[1].EventType)
+}
+
+func (s *CustomerImplTestSuite) TestUpsertCustomerByExternalIDError() {
+	got, created, err := s.customer.UpsertCustomerByExternalID(context.Background(), &entity.Customer{
+		Name:           typehelper.GetPointer("John Doe"),
+		ExternalSource: typehelper.GetPointer("crm"),
+		ExternalID:     typehelper.GetPointer("A-1"),
+	})
+	s.Error(err)
+	s.False(created)
+	s.Nil(got)
+}
+
+func (s *CustomerImplTestSuite) TestUpsertCustomerByExternalIDDeleted() {
+	customer := func() *entity.Customer {
+		return &entity.Customer{
+			Name:           typehelper.GetPointer("John Doe"),
+			Age:            typehelper.GetPointer(uint(20)),
+			ExternalSource: typehelper.GetPointer("crm"),
+			ExternalID:     typehelper.GetPointer("A-1"),
+		}
+	}
+	got, _, err := s.customer.UpsertCustomerByExternalID(context.Background(), customer())
+	s.Require().NoError(err)
+	s.Require().NoError(s.customer.DeleteCustomer(context.Background(), got.ID))
+
+	_, created, err := s.customer.UpsertCustomerByExternalID(context.Background(), customer())
+	s.ErrorIs(err, ErrCustomerDeleted)
+	s.False(created)
+
+	_, err = s.customer.RestoreCustomer(context.Background(), got.ID)
+	s.Require().NoError(err)
+	_, created, err = s.customer.UpsertCustomerByExternalID(context.Background(), customer())
+	s.NoError(err)
+	s.False(created)
+}
+
+func (s *CustomerImplTestSuite) TestExportCustomersSuccess() {
+	for _, c := range []struct {
+		name string
+		age  uint
+	}{{"John Doe", 20}, {"Jane Doe", 40}, {"Jim Beam", 30}} {
+		if err := s.tx.Create(&entity.Customer{Name: typehelper.GetPointer(c.name), Age: typehelper.GetPointer(c.age)}).Error; err != nil {
+			panic(err)
+		}
+	}
+
+	var got []string
+	err := s.customer.ExportCustomers(context.Background(), entity.CustomerFilter{
+		Name:   typehelper.GetPointer("Jane Doe"),
+		MinAge: typehelper.GetPointer(uint(25)),
+	}, func(customer *entity.Customer) error {
+		got = append(got, *customer.Name)
+		return nil
+	})
+	s.NoError(err)
+	s.Equal([]string{"Jane Doe"}, got)
+
+	got = nil
+	err = s.customer.ExportCustomers(context.Background(), entity.CustomerFilter{}, func(customer *entity.Customer) error {
+		got = append(got, *customer.Name)
+		return nil
+	})
+	s.NoError(err)
+	s.Equal([]string{"John Doe", "Jane Doe", "Jim Beam"}, got)
+}
+
+func (s *CustomerImplTestSuite) TestExportCustomersPages() {
+	customers := make([]*entity.Customer, 2*exportPageSize+1)
+	for i := range customers {
+		customers[i] = &entity.Customer{Name: typehelper.GetPointer("John Doe"), Age: typehelper.GetPointer(uint(i % 100))}
+	}
+	s.NoError(s.tx.CreateInBatches(customers, 100).Error)
+
+	var ids []uint
+	err := s.customer.ExportCustomers(context.Background(), entity.CustomerFilter{}, func(customer *entity.Customer) error {
+		ids = append(ids, customer.ID)
+		return nil
+	})
+	s.NoError(err)
+	s.Len(ids, len(customers))
+	for i := 1; i < len(ids); i++ {
+		s.Less(ids[i-1], ids[i])
+	}
+
+	ids = nil
+	err = s.customer.ExportCustomers(context.Background(), entity.CustomerFilter{MaxAge: typehelper.GetPointer(uint(0))}, func(customer *entity.Customer) error {
+		ids = append(ids, customer.ID)
+		return nil
+	})
+	s.NoError(err)
+	s.Len(ids, 11)
+}
+
+func (s *CustomerImplTestSuite) TestExportCustomersSnapshot() {
+	// Writers use connections of their own, which needs WAL journaling to
+	// go on while the export holds its read transaction.
+	db := testhelper.NewMigratedDB(filepath.Join(s.T().TempDir(), "test.db") + "?_pragma=journal_mode(WAL)")
+	s.T().Cleanup(func() {
+		sqlDB, _ := db.DB()
+		sqlDB.Close()
+	})
+	customer := NewCustomer(db, &config.Config{})
+	customers := make([]*entity.Customer, 2*exportPageSize+1)
+	for i := range customers {
+		customers[i] = &entity.Customer{Name: typehelper.GetPointer("John Doe"), Age: typehelper.GetPointer(uint(20))}
+	}
+	s.Require().NoError(db.CreateInBatches(customers, 100).Error)
+
+	var got []*entity.Customer
+	err := customer.ExportCustomers(context.Background(), entity.CustomerFilter{}, func(exported *entity.Customer) error {
+		got = append(got, exported)
+		if len(got) > 1 {
+			return nil
+		}
+		// Changes committed after the first page is read, to customers of
+		// later pages, are not part of the export.
+		if _, err := customer.CreateCustomer(context.Background(), &entity.Customer{Name: typehelper.GetPointer("Jane Doe"), Age: typehelper.GetPointer(uint(30))}); err != nil {
+			return err
+		}
+		last := customers[len(customers)-1]
+		if _, err := customer.UpdateCustomer(context.Background(), last.ID, &entity.Customer{Name: typehelper.GetPointer("Jim Beam"), Age: typehelper.GetPointer(uint(40))}); err != nil {
+			return err
+		}
+		return customer.DeleteCustomer(context.Background(), customers[exportPageSize].ID)
+	})
+	s.Require().NoError(err)
+	s.Len(got, len(customers))
+	for i, exported := range got {
+		s.Equal(customers[i].ID, exported.ID)
+		s.Equal("John Doe", *exported.Name)
+		s.Equal(uint(20), *exported.Age)
+	}
+
+	// The changes were committed.
+	var count int64
+	s.NoError(db.Model(&entity.Customer{}).Count(&count).Error)
+	s.Equal(int64(len(customers)), count)
+}
+
+func (s *CustomerImplTestSuite) TestExportCustomersCallbackError() {
+	if err := s.tx.Create(&entity.Customer{Name: typehelper.GetPointer("John Doe"), Age: typehelper.GetPointer(uint(20))}).Error; err != nil {
+		panic(err)
+	}
+
+	err := s.customer.ExportCustomers(context.Background(), entity.CustomerFilter{}, func(customer *entity.Customer) error {
+		return fmt.Errorf("error")
+	})
+	s.Error(err)
+}
+
+func (s *CustomerImplTestSuite) TestTenantIsolation() {
+	ctxA := tenant.WithTenant(context.Background(), "tenant-a")
+	ctxB := tenant.WithTenant(context.Background(), "tenant-b")
+
+	idA, err := s.customer.CreateCustomer(ctxA, &entity.Customer{
+		Name:           typehelper.GetPointer("John Doe"),
+		Age:            typehelper.GetPointer(uint(20)),
+		ExternalSource: typehelper.GetPointer("crm"),
+		ExternalID:     typehelper.GetPointer("A-1"),
+	})
+	s.NoError(err)
+
+	s.Run("GetCustomerByID", func() {
+		got, err := s.customer.GetCustomerByID(ctxB, *idA)
+		s.ErrorIs(err, gorm.ErrRecordNotFound)
+		s.Nil(got)
+	})
+
+	s.Run("GetCustomersByIDs", func() {
+		got, err := s.customer.GetCustomersByIDs(ctxB, []uint{*idA})
+		s.NoError(err)
+		s.Empty(got)
+	})
+
+	s.Run("GetAllCustomer", func() {
+		got, err := s.customer.GetAllCustomer(ctxB)
+		s.NoError(err)
+		s.Empty(got)
+	})
+
+	s.Run("ExportCustomers", func() {
+		called := false
+		err := s.customer.ExportCustomers(ctxB, entity.CustomerFilter{}, func(customer *entity.Customer) error {
+			called = true
+			return nil
+		})
+		s.NoError(err)
+		s.False(called)
+	})
+
+	s.Run("UpdateCustomer", func() {
+		got, err := s.customer.UpdateCustomer(ctxB, *idA, &entity.Customer{
+			Name: typehelper.GetPointer("Mallory"),
+			Age:  typehelper.GetPointer(uint(99)),
+		})
+		s.ErrorIs(err, gorm.ErrRecordNotFound)
+		s.Nil(got)
+	})
+
+	s.Run("DeleteCustomer", func() {
+		s.NoError(s.customer.DeleteCustomer(ctxB, *idA))
+		got, err := s.customer.GetCustomerByID(ctxA, *idA)
+		s.NoError(err)
+		s.Equal(*idA, got.ID)
+	})
+
+	s.Run("UpsertCustomerByExternalID", func() {
+		got, created, err := s.customer.UpsertCustomerByExternalID(ctxB, &entity.Customer{
+			Name:           typehelper.GetPointer("Jane Doe"),
+			Age:            typehelper.GetPointer(uint(30)),
+			ExternalSource: typehelper.GetPointer("crm"),
+			ExternalID:     typehelper.GetPointer("A-1"),
+		})
+		s.NoError(err)
+		s.True(created)
+		s.NotEqual(*idA, got.ID)
+		s.Equal("tenant-b", got.TenantID)
+	})
+
+	got, err := s.customer.GetCustomerByID(ctxA, *idA)
+	s.NoError(err)
+	s.Equal(&entity.Customer{
+		ID:             *idA,
+		TenantID:       "tenant-a",
+		Name:           typehelper.GetPointer("John Doe"),
+		Age:            typehelper.GetPointer(uint(20)),
+		ExternalSource: typehelper.GetPointer("crm"),
+		ExternalID:     typehelper.GetPointer("A-1"),
+	}, got)
+
+	var events []entity.OutboxEvent
+	s.NoError(s.tx.Order("id").Find(&events).Error)
+	s.Len(events, 2)
+	s.Equal("tenant-a", events[0].TenantID)
+	s.Equal("tenant-b", events[1].TenantID)
+}
+
+func (s *CustomerImplTestSuite) TestEncryptedName() {
+	fieldcrypt.SetKeyring(testhelper.NewKeyring("k1", "k1"))
+	defer fieldcrypt.SetKeyring(nil)
+
+	id, err := s.customer.CreateCustomer(context.Background(), &entity.Customer{
+		Name: typehelper.GetPointer("John Doe"),
+		Age:  typehelper.GetPointer(uint(20)),
+	})
+	s.NoError(err)
+	_, err = s.customer.UpdateCustomer(context.Background(), *id, &entity.Customer{
+		Name: typehelper.GetPointer("John Dee"),
+		Age:  typehelper.GetPointer(uint(21)),
+	})
+	s.NoError(err)
+
+	var stored string
+	s.NoError(s.tx.Raw("SELECT name FROM customers WHERE id = ?", *id).Scan(&stored).Error)
+	s.True(strings.HasPrefix(stored, "enc2.k1."))
+	s.NotContains(stored, "John Dee")
+
+	got, err := s.customer.GetCustomerByID(context.Background(), *id)
+	s.NoError(err)
+	s.Equal("John Dee", *got.Name)
+
+	var payloads []string
+	s.NoError(s.tx.Raw("SELECT payload FROM outbox_events ORDER BY id").Scan(&payloads).Error)
+	s.Len(payloads, 2)
+	s.NotContains(payloads[1], "John Dee")
+
+	var names []string
+	err = s.customer.ExportCustomers(context.Background(), entity.CustomerFilter{Name: typehelper.GetPointer("John Dee")}, func(customer *entity.Customer) error {
+		names = append(names, *customer.Name)
+		return nil
+	})
+	s.NoError(err)
+	s.Equal([]string{"John Dee"}, names)
+}
+
+func (s *CustomerImplTestSuite) TestReencryptCustomers() {
+	for _, name := range []string{"John Doe", "Jane Doe", "Jim Beam"} {
+		_, err := s.customer.CreateCustomer(context.Background(), &entity.Customer{Name: typehelper.GetPointer(name), Age: typehelper.GetPointer(uint(20))})
+		s.NoError(err)
+	}
+	// Deleted customers are rewritten too.
+	s.NoError(s.customer.DeleteCustomer(context.Background(), 2))
+	// Rows written before encryption was enabled are plaintext.
+	fieldcrypt.SetKeyring(testhelper.NewKeyring("k1", "k1", "k2"))
+	defer fieldcrypt.SetKeyring(nil)
+
+	lastID, n, err := s.customer.ReencryptCustomers(context.Background(), 0, 2)
+	s.NoError(err)
+	s.Equal(uint(2), lastID)
+	s.Equal(2, n)
+
+	fieldcrypt.SetKeyring(testhelper.NewKeyring("k2", "k1", "k2"))
+	lastID, n, err = s.customer.ReencryptCustomers(context.Background(), 1, 2)
+	s.NoError(err)
+	s.Equal(uint(3), lastID)
+	s.Equal(2, n)
+
+	lastID, n, err = s.customer.ReencryptCustomers(context.Background(), 3, 2)
+	s.NoError(err)
+	s.Equal(uint(3), lastID)
+	s.Zero(n)
+
+	var stored []string
+	s.NoError(s.tx.Raw("SELECT name FROM customers ORDER BY id").Scan(&stored).Error)
+	s.True(strings.HasPrefix(stored[0], "enc2.k1."))
+	s.True(strings.HasPrefix(stored[1], "enc2.k2."))
+	s.True(strings.HasPrefix(stored[2], "enc2.k2."))
+
+	var names []string
+	err = s.customer.ExportCustomers(context.Background(), entity.CustomerFilter{Name: typehelper.GetPointer("Jim Beam")}, func(customer *entity.Customer) error {
+		names = append(names, *customer.Name)
+		return nil
+	})
+	s.NoError(err)
+	s.Equal([]string{"Jim Beam"}, names)
+}
+
+func (s *CustomerImplTestSuite) TestFilterNameWithoutIndex() {
+	_, err := s.customer.CreateCustomer(context.Background(), &entity.Customer{Name: typehelper.GetPointer("John Doe"), Age: typehelper.GetPointer(uint(20))})
+	s.NoError(err)
+	// Enabled after the row was written, which has no index until
+	// rotate-keys backfills it.
+	fieldcrypt.SetKeyring(testhelper.NewKeyring("k1", "k1"))
+	defer fieldcrypt.SetKeyring(nil)
+	_, err = s.customer.CreateCustomer(context.Background(), &entity.Customer{Name: typehelper.GetPointer("John Doe"), Age: typehelper.GetPointer(uint(30))})
+	s.NoError(err)
+
+	var ages []uint
+	err = s.customer.ExportCustomers(context.Background(), entity.CustomerFilter{Name: typehelper.GetPointer("John Doe")}, func(customer *entity.Customer) error {
+		ages = append(ages, *customer.Age)
+		return nil
+	})
+	s.NoError(err)
+	s.Equal([]uint{20, 30}, ages)
+}
+
+func (s *CustomerImplTestSuite) TestEncryptedNameBoundToTenant() {
+	fieldcrypt.SetKeyring(testhelper.NewKeyring("k1", "k1"))
+	defer fieldcrypt.SetKeyring(nil)
+	ctxA := tenant.WithTenant(context.Background(), "tenant-a")
+	ctxB := tenant.WithTenant(context.Background(), "tenant-b")
+
+	idA, err := s.customer.CreateCustomer(ctxA, &entity.Customer{Name: typehelper.GetPointer("John Doe"), Age: typehelper.GetPointer(uint(20))})
+	s.NoError(err)
+	idB, err := s.customer.CreateCustomer(ctxB, &entity.Customer{Name: typehelper.GetPointer("Jane Roe"), Age: typehelper.GetPointer(uint(30))})
+	s.NoError(err)
+
+	// A ciphertext copied to a row of another tenant does not decrypt.
+	s.NoError(s.tx.Exec("UPDATE customers SET name = (SELECT name FROM customers WHERE id = ?) WHERE id = ?", *idA, *idB).Error)
+	_, err = s.customer.GetCustomerByID(ctxB, *idB)
+	s.ErrorContains(err, "fieldcrypt: error decrypting value")
+
+	got, err := s.customer.GetCustomerByID(ctxA, *idA)
+	s.NoError(err)
+	s.Equal("John Doe", *got.Name)
+}
+
+func (s *CustomerImplTestSuite) TestPlaintextLikeAnEnvelope() {
+	name := "enc2.k1.not.encrypted"
+	id, err := s.customer.CreateCustomer(context.Background(), &entity.Customer{Name: typehelper.GetPointer(name), Age: typehelper.GetPointer(uint(20))})
+	s.NoError(err)
+
+	got, err := s.customer.GetCustomerByID(context.Background(), *id)
+	s.NoError(err)
+	s.Equal(name, *got.Name)
+
+	var ids []uint
+	err = s.customer.ExportCustomers(context.Background(), entity.CustomerFilter{Name: typehelper.GetPointer(name)}, func(customer *entity.Customer) error {
+		ids = append(ids, customer.ID)
+		return nil
+	})
+	s.NoError(err)
+	s.Equal([]uint{*id}, ids)
+}
+
 func TestCustomerImplSuite(t *testing.T) {
 	suite.Run(t, new(CustomerImplTestSuite))
 }
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"crud-customer/internal/entity"
+	mockrepo "crud-customer/mocks/internal_/repository"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+	"gorm.io/gorm"
+	"testing"
+)
+
+type CustomerTracingTestSuite struct {
+	suite.Suite
+	recorder     *tracetest.SpanRecorder
+	mockCustomer *mockrepo.Customer
+	customer     Customer
+}
+
+func (s *CustomerTracingTestSuite) SetupTest() {
+	s.recorder = tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(s.recorder)))
+	s.mockCustomer = mockrepo.NewCustomer(s.T())
+	s.customer = NewCustomerTracing(s.mockCustomer)
+}
+
+func (s *CustomerTracingTestSuite) TearDownTest() {
+	otel.SetTracerProvider(noop.NewTracerProvider())
+	s.recorder = nil
+	s.mockCustomer = nil
+	s.customer = nil
+}
+
+func (s *CustomerTracingTestSuite) TestSpans() {
+	s.mockCustomer.EXPECT().GetAllCustomer(mock.Anything).Return([]*entity.Customer{{ID: 1}}, nil)
+	s.mockCustomer.EXPECT().GetCustomerByID(mock.Anything, uint(2)).Return(nil, gorm.ErrRecordNotFound)
+
+	customers, err := s.customer.GetAllCustomer(context.Background())
+	s.NoError(err)
+	s.Len(customers, 1)
+	_, err = s.customer.GetCustomerByID(context.Background(), 2)
+	s.ErrorIs(err, gorm.ErrRecordNotFound)
+
+	spans := s.recorder.Ended()
+	s.Require().Len(spans, 2)
+	s.Equal("repository.Customer/GetAllCustomer", spans[0].Name())
+	s.Equal(codes.Unset, spans[0].Status().Code)
+	s.Equal("repository.Customer/GetCustomerByID", spans[1].Name())
+	s.Equal(codes.Error, spans[1].Status().Code)
+}
+
+func TestCustomerTracingSuite(t *testing.T) {
+	suite.Run(t, new(CustomerTracingTestSuite))
+}
@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+	"crud-customer/internal/entity"
+	"time"
+)
+
+type Outbox interface {
+	// GetPendingEvents returns up to limit events neither dispatched nor
+	// dead-lettered, by ID, leaving out the aggregates whose failed event
+	// is not due for a retry yet.
+	GetPendingEvents(ctx context.Context, limit int) ([]*entity.OutboxEvent, error)
+	MarkDispatched(ctx context.Context, id uint) error
+	// MarkFailed records a failed delivery, to be retried from retryAt.
+	MarkFailed(ctx context.Context, id uint, reason string, retryAt time.Time) error
+	// MarkDeadLettered records a failed delivery and stops retrying the
+	// event.
+	MarkDeadLettered(ctx context.Context, id uint, reason string) error
+	// ReencryptEvents rewrites the payloads of up to limit events with an ID
+	// above afterID under the primary key. It returns the last ID rewritten
+	// and the number of events.
+	ReencryptEvents(ctx context.Context, afterID uint, limit int) (uint, int, error)
+}
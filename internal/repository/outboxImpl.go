@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"crud-customer/config"
+	"crud-customer/internal/entity"
+	"encoding/json"
+	"gorm.io/gorm"
+	"time"
+)
+
+type outboxImpl struct {
+	db  *gorm.DB
+	cfg *config.Config
+}
+
+func (o *outboxImpl) GetPendingEvents(ctx context.Context, limit int) ([]*entity.OutboxEvent, error) {
+	var events []*entity.OutboxEvent
+	// Times are compared as the strings SQLite stores, so they are all
+	// written in UTC.
+	blocked := o.db.Table("outbox_events AS blocked").Select("1").Where(
+		"blocked.aggregate_type = outbox_events.aggregate_type AND blocked.aggregate_id = outbox_events.aggregate_id"+
+			" AND blocked.dispatched_at IS NULL AND blocked.dead_lettered_at IS NULL AND blocked.next_attempt_at > ?",
+		time.Now().UTC())
+	result := o.db.WithContext(ctx).
+		Where("dispatched_at IS NULL AND dead_lettered_at IS NULL").
+		Where("NOT EXISTS (?)", blocked).
+		Order("id").Limit(limit).Find(&events)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return events, nil
+}
+
+func (o *outboxImpl) MarkDispatched(ctx context.Context, id uint) error {
+	result := o.db.WithContext(ctx).Model(&entity.OutboxEvent{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"dispatched_at": time.Now(),
+		"attempts":      gorm.Expr("attempts + 1"),
+		"last_error":    nil,
+	})
+	return result.Error
+}
+
+func (o *outboxImpl) MarkFailed(ctx context.Context, id uint, reason string, retryAt time.Time) error {
+	result := o.db.WithContext(ctx).Model(&entity.OutboxEvent{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"attempts":        gorm.Expr("attempts + 1"),
+		"last_error":      reason,
+		"next_attempt_at": retryAt.UTC(),
+	})
+	return result.Error
+}
+
+func (o *outboxImpl) MarkDeadLettered(ctx context.Context, id uint, reason string) error {
+	result := o.db.WithContext(ctx).Model(&entity.OutboxEvent{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"attempts":         gorm.Expr("attempts + 1"),
+		"last_error":       reason,
+		"next_attempt_at":  nil,
+		"dead_lettered_at": time.Now().UTC(),
+	})
+	return result.Error
+}
+
+func (o *outboxImpl) ReencryptEvents(ctx context.Context, afterID uint, limit int) (uint, int, error) {
+	var events []*entity.OutboxEvent
+	err := o.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id > ?", afterID).Order("id").Limit(limit).Find(&events).Error; err != nil {
+			return err
+		}
+		for _, event := range events {
+			if err := tx.Model(event).Select("payload").Updates(event).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil || len(events) == 0 {
+		return afterID, 0, err
+	}
+	return events[len(events)-1].ID, len(events), nil
+}
+
+// addOutboxEvent records a customer event on tx. It must be called inside the
+// transaction that performs the mutation so the event is committed or rolled
+// back together with it.
+func addOutboxEvent(tx *gorm.DB, eventType string, customer *entity.Customer) error {
+	payload, err := json.Marshal(customer)
+	if err != nil {
+		return err
+	}
+	return tx.Create(&entity.OutboxEvent{
+		TenantID:      customer.TenantID,
+		AggregateType: entity.AggregateTypeCustomer,
+		AggregateID:   customer.ID,
+		EventType:     eventType,
+		Payload:       string(payload),
+	}).Error
+}
+
+func NewOutbox(db *gorm.DB, cfg *config.Config) Outbox {
+	return &outboxImpl{
+		db:  db,
+		cfg: cfg,
+	}
+}
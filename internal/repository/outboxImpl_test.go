@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"crud-customer/config"
+	"crud-customer/internal/entity"
+	"crud-customer/pkg/fieldcrypt"
+	"crud-customer/util/testhelper"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+type OutboxImplTestSuite struct {
+	suite.Suite
+	outbox    Outbox
+	tmpDBFile *os.File
+	db        *gorm.DB
+	tx        *gorm.DB
+}
+
+func (s *OutboxImplTestSuite) SetupSuite() {
+	f, err := os.CreateTemp("", "test.*.db")
+	if err != nil {
+		panic(err)
+	}
+	s.tmpDBFile = f
+	s.db = testhelper.NewMigratedDB(f.Name())
+}
+
+func (s *OutboxImplTestSuite) TearDownSuite() {
+	os.Remove(s.tmpDBFile.Name())
+	s.db = nil
+}
+
+func (s *OutboxImplTestSuite) SetupTest() {
+	s.tx = s.db.Begin()
+	s.outbox = NewOutbox(s.tx, &config.Config{})
+	for i := uint(1); i <= 3; i++ {
+		if err := s.tx.Create(&entity.OutboxEvent{
+			AggregateType: entity.AggregateTypeCustomer,
+			AggregateID:   i,
+			EventType:     entity.EventCustomerCreated,
+			Payload:       "{}",
+		}).Error; err != nil {
+			panic(err)
+		}
+	}
+}
+
+func (s *OutboxImplTestSuite) TearDownTest() {
+	s.tx.Rollback()
+	s.outbox = nil
+}
+
+func (s *OutboxImplTestSuite) TestGetPendingEventsSuccess() {
+	got, err := s.outbox.GetPendingEvents(context.Background(), 2)
+	s.NoError(err)
+	s.Len(got, 2)
+	s.Equal(uint(1), got[0].ID)
+	s.Equal(uint(2), got[1].ID)
+}
+
+func (s *OutboxImplTestSuite) TestMarkDispatchedSuccess() {
+	s.NoError(s.outbox.MarkDispatched(context.Background(), 1))
+
+	got, err := s.outbox.GetPendingEvents(context.Background(), 10)
+	s.NoError(err)
+	s.Len(got, 2)
+	s.Equal(uint(2), got[0].ID)
+
+	var event entity.OutboxEvent
+	s.NoError(s.tx.First(&event, 1).Error)
+	s.NotNil(event.DispatchedAt)
+	s.Equal(uint(1), event.Attempts)
+}
+
+func (s *OutboxImplTestSuite) TestMarkFailedSuccess() {
+	s.NoError(s.outbox.MarkFailed(context.Background(), 1, "boom", time.Now().Add(time.Minute)))
+
+	var event entity.OutboxEvent
+	s.NoError(s.tx.First(&event, 1).Error)
+	s.Nil(event.DispatchedAt)
+	s.NotNil(event.NextAttemptAt)
+	s.Equal(uint(1), event.Attempts)
+	s.Equal("boom", *event.LastError)
+}
+
+func (s *OutboxImplTestSuite) TestGetPendingEventsSkipsAggregateWaitingForRetry() {
+	s.NoError(s.tx.Create(&entity.OutboxEvent{
+		AggregateType: entity.AggregateTypeCustomer,
+		AggregateID:   1,
+		EventType:     entity.EventCustomerUpdated,
+		Payload:       "{}",
+	}).Error)
+	s.NoError(s.outbox.MarkFailed(context.Background(), 1, "boom", time.Now().Add(time.Minute)))
+
+	got, err := s.outbox.GetPendingEvents(context.Background(), 2)
+	s.NoError(err)
+	s.Len(got, 2)
+	s.Equal(uint(2), got[0].ID)
+	s.Equal(uint(3), got[1].ID)
+}
+
+func (s *OutboxImplTestSuite) TestGetPendingEventsRetriesDueEvent() {
+	s.NoError(s.outbox.MarkFailed(context.Background(), 1, "boom", time.Now().Add(-time.Second)))
+
+	got, err := s.outbox.GetPendingEvents(context.Background(), 10)
+	s.NoError(err)
+	s.Len(got, 3)
+	s.Equal(uint(1), got[0].ID)
+}
+
+func (s *OutboxImplTestSuite) TestMarkDeadLetteredSuccess() {
+	s.NoError(s.outbox.MarkDeadLettered(context.Background(), 1, "boom"))
+
+	var event entity.OutboxEvent
+	s.NoError(s.tx.First(&event, 1).Error)
+	s.NotNil(event.DeadLetteredAt)
+	s.Nil(event.NextAttemptAt)
+	s.Equal(uint(1), event.Attempts)
+
+	got, err := s.outbox.GetPendingEvents(context.Background(), 10)
+	s.NoError(err)
+	s.Len(got, 2)
+	s.Equal(uint(2), got[0].ID)
+}
+
+func (s *OutboxImplTestSuite) TestReencryptEventsSuccess() {
+	fieldcrypt.SetKeyring(testhelper.NewKeyring("k1", "k1"))
+	defer fieldcrypt.SetKeyring(nil)
+
+	lastID, n, err := s.outbox.ReencryptEvents(context.Background(), 1, 10)
+	s.NoError(err)
+	s.Equal(uint(3), lastID)
+	s.Equal(2, n)
+
+	var stored []string
+	s.NoError(s.tx.Raw("SELECT payload FROM outbox_events ORDER BY id").Scan(&stored).Error)
+	s.Equal("{}", stored[0])
+	s.True(strings.HasPrefix(stored[1], "enc2.k1."))
+	s.True(strings.HasPrefix(stored[2], "enc2.k1."))
+
+	got, err := s.outbox.GetPendingEvents(context.Background(), 10)
+	s.NoError(err)
+	s.Equal("{}", got[2].Payload)
+}
+
+func TestOutboxImplSuite(t *testing.T) {
+	suite.Run(t, new(OutboxImplTestSuite))
+}
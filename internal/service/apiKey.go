@@ -0,0 +1,18 @@
+package service
+
+import (
+	"context"
+	"crud-customer/internal/entity"
+	"time"
+)
+
+type APIKey interface {
+	// CreateAPIKey stores a new key and returns it with the plaintext key,
+	// which cannot be recovered afterwards.
+	CreateAPIKey(ctx context.Context, name string, tenantID string, scopes []string, expiresAt *time.Time) (*entity.APIKey, string, error)
+	GetAllAPIKey(ctx context.Context) ([]*entity.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id uint) error
+	// Authenticate resolves a plaintext key to an active API key and records
+	// its use. Unknown, expired and revoked keys return auth.ErrInvalidCredentials.
+	Authenticate(ctx context.Context, key string) (*entity.APIKey, error)
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"crud-customer/config"
+	"crud-customer/internal/entity"
+	"crud-customer/internal/repository"
+	"crud-customer/pkg/auth"
+	"errors"
+	"gorm.io/gorm"
+	"log/slog"
+	"time"
+)
+
+// lastUsedResolution limits how often a busy key's last-used timestamp is
+// written back.
+const lastUsedResolution = time.Minute
+
+type apiKeyImpl struct {
+	apiKeyRepo repository.APIKey
+	cfg        *config.Config
+	now        func() time.Time
+}
+
+func (a *apiKeyImpl) CreateAPIKey(ctx context.Context, name string, tenantID string, scopes []string, expiresAt *time.Time) (*entity.APIKey, string, error) {
+	key, prefix, err := auth.GenerateAPIKey()
+	if err != nil {
+		return nil, "", err
+	}
+	apiKey := &entity.APIKey{
+		Name:      name,
+		TenantID:  tenantID,
+		Prefix:    prefix,
+		Hash:      auth.HashAPIKey(key),
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+	}
+
+	id, err := a.apiKeyRepo.CreateAPIKey(ctx, apiKey)
+	if err != nil {
+		return nil, "", err
+	}
+	apiKey.ID = *id
+	return apiKey, key, nil
+}
+
+func (a *apiKeyImpl) GetAllAPIKey(ctx context.Context) ([]*entity.APIKey, error) {
+	return a.apiKeyRepo.GetAllAPIKey(ctx)
+}
+
+func (a *apiKeyImpl) RevokeAPIKey(ctx context.Context, id uint) error {
+	return a.apiKeyRepo.RevokeAPIKey(ctx, id, a.now())
+}
+
+func (a *apiKeyImpl) Authenticate(ctx context.Context, key string) (*entity.APIKey, error) {
+	prefix, ok := auth.ParseAPIKeyPrefix(key)
+	if !ok {
+		return nil, auth.ErrInvalidCredentials
+	}
+	apiKey, err := a.apiKeyRepo.GetAPIKeyByPrefix(ctx, prefix)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, auth.ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := a.now()
+	if !auth.VerifyAPIKey(key, apiKey.Hash) || !apiKey.IsActive(now) {
+		return nil, auth.ErrInvalidCredentials
+	}
+
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) >= lastUsedResolution {
+		if err := a.apiKeyRepo.UpdateLastUsed(ctx, apiKey.ID, now); err != nil {
+			slog.WarnContext(ctx, "error updating last used time of api key", "api_key_id", apiKey.ID, "error", err)
+		} else {
+			apiKey.LastUsedAt = &now
+		}
+	}
+	return apiKey, nil
+}
+
+func NewAPIKey(cfg *config.Config, apiKeyRepo repository.APIKey) APIKey {
+	return &apiKeyImpl{
+		apiKeyRepo: apiKeyRepo,
+		cfg:        cfg,
+		now:        time.Now,
+	}
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"crud-customer/config"
+	"crud-customer/internal/entity"
+	mockrepo "crud-customer/mocks/internal_/repository"
+	"crud-customer/pkg/auth"
+	"crud-customer/util/typehelper"
+	"fmt"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+type APIKeyImplTestSuite struct {
+	suite.Suite
+	mockAPIKeyRepo *mockrepo.APIKey
+	apiKey         APIKey
+	now            time.Time
+}
+
+func (s *APIKeyImplTestSuite) SetupTest() {
+	s.mockAPIKeyRepo = mockrepo.NewAPIKey(s.T())
+	s.now = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	s.apiKey = &apiKeyImpl{
+		apiKeyRepo: s.mockAPIKeyRepo,
+		cfg:        &config.Config{},
+		now:        func() time.Time { return s.now },
+	}
+}
+
+func (s *APIKeyImplTestSuite) TearDownTest() {
+	s.mockAPIKeyRepo = nil
+	s.apiKey = nil
+}
+
+func (s *APIKeyImplTestSuite) TestCreateAPIKeySuccess() {
+	var stored *entity.APIKey
+	s.mockAPIKeyRepo.EXPECT().CreateAPIKey(mock.Anything, mock.Anything).
+		Run(func(ctx context.Context, apiKey *entity.APIKey) { stored = apiKey }).
+		Return(typehelper.GetPointer(uint(1)), nil)
+
+	got, key, err := s.apiKey.CreateAPIKey(context.Background(), "ci", "acme", []string{"customers:read"}, nil)
+	s.NoError(err)
+	s.Equal(uint(1), got.ID)
+	prefix, ok := auth.ParseAPIKeyPrefix(key)
+	s.True(ok)
+	s.Equal(prefix, stored.Prefix)
+	s.Equal("acme", stored.TenantID)
+	s.NotContains(stored.Hash, key)
+	s.True(auth.VerifyAPIKey(key, stored.Hash))
+}
+
+func (s *APIKeyImplTestSuite) TestCreateAPIKeyError() {
+	s.mockAPIKeyRepo.EXPECT().CreateAPIKey(mock.Anything, mock.Anything).Return(nil, fmt.Errorf("error"))
+
+	got, key, err := s.apiKey.CreateAPIKey(context.Background(), "ci", "", nil, nil)
+	s.Error(err)
+	s.Nil(got)
+	s.Empty(key)
+}
+
+func (s *APIKeyImplTestSuite) TestAuthenticateSuccess() {
+	key, prefix, err := auth.GenerateAPIKey()
+	s.NoError(err)
+	stored := &entity.APIKey{ID: 1, Prefix: prefix, Hash: auth.HashAPIKey(key)}
+	s.mockAPIKeyRepo.EXPECT().GetAPIKeyByPrefix(mock.Anything, prefix).Return(stored, nil)
+	s.mockAPIKeyRepo.EXPECT().UpdateLastUsed(mock.Anything, uint(1), s.now).Return(nil)
+
+	got, err := s.apiKey.Authenticate(context.Background(), key)
+	s.NoError(err)
+	s.Equal(uint(1), got.ID)
+	s.Equal(s.now, *got.LastUsedAt)
+}
+
+func (s *APIKeyImplTestSuite) TestAuthenticateSecretWithUnderscore() {
+	key := "cck_0123456789ab_abc_def-ghi"
+	stored := &entity.APIKey{ID: 1, Prefix: "0123456789ab", Hash: auth.HashAPIKey(key)}
+	s.mockAPIKeyRepo.EXPECT().GetAPIKeyByPrefix(mock.Anything, "0123456789ab").Return(stored, nil)
+	s.mockAPIKeyRepo.EXPECT().UpdateLastUsed(mock.Anything, uint(1), s.now).Return(nil)
+
+	got, err := s.apiKey.Authenticate(context.Background(), key)
+	s.NoError(err)
+	s.Equal(uint(1), got.ID)
+}
+
+func (s *APIKeyImplTestSuite) TestAuthenticateSkipsRecentLastUsed() {
+	key, prefix, err := auth.GenerateAPIKey()
+	s.NoError(err)
+	lastUsed := s.now.Add(-time.Second)
+	stored := &entity.APIKey{ID: 1, Prefix: prefix, Hash: auth.HashAPIKey(key), LastUsedAt: &lastUsed}
+	s.mockAPIKeyRepo.EXPECT().GetAPIKeyByPrefix(mock.Anything, prefix).Return(stored, nil)
+
+	_, err = s.apiKey.Authenticate(context.Background(), key)
+	s.NoError(err)
+}
+
+func (s *APIKeyImplTestSuite) TestAuthenticateInvalidCredentials() {
+	key, prefix, err := auth.GenerateAPIKey()
+	s.NoError(err)
+	expired := s.now.Add(-time.Hour)
+
+	testCases := []struct {
+		name   string
+		key    string
+		stored *entity.APIKey
+		err    error
+	}{
+		{name: "malformed key", key: "nope"},
+		{name: "unknown prefix", key: key, err: gorm.ErrRecordNotFound},
+		{name: "wrong secret", key: key + "x", stored: &entity.APIKey{ID: 1, Prefix: prefix, Hash: auth.HashAPIKey(key)}},
+		{name: "expired", key: key, stored: &entity.APIKey{ID: 1, Prefix: prefix, Hash: auth.HashAPIKey(key), ExpiresAt: &expired}},
+		{name: "revoked", key: key, stored: &entity.APIKey{ID: 1, Prefix: prefix, Hash: auth.HashAPIKey(key), RevokedAt: &expired}},
+	}
+	for _, tt := range testCases {
+		s.Run(tt.name, func() {
+			s.mockAPIKeyRepo = mockrepo.NewAPIKey(s.T())
+			s.apiKey.(*apiKeyImpl).apiKeyRepo = s.mockAPIKeyRepo
+			if tt.stored != nil || tt.err != nil {
+				s.mockAPIKeyRepo.EXPECT().GetAPIKeyByPrefix(mock.Anything, prefix).Return(tt.stored, tt.err)
+			}
+
+			got, err := s.apiKey.Authenticate(context.Background(), tt.key)
+			s.ErrorIs(err, auth.ErrInvalidCredentials)
+			s.Nil(got)
+		})
+	}
+}
+
+func (s *APIKeyImplTestSuite) TestRevokeAPIKeySuccess() {
+	s.mockAPIKeyRepo.EXPECT().RevokeAPIKey(mock.Anything, uint(1), s.now).Return(nil)
+
+	err := s.apiKey.RevokeAPIKey(context.Background(), 1)
+	s.NoError(err)
+}
+
+func TestAPIKeyImplTestSuite(t *testing.T) {
+	suite.Run(t, new(APIKeyImplTestSuite))
+}
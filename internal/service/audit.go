@@ -0,0 +1,14 @@
+package service
+
+import (
+	"context"
+	"crud-customer/internal/entity"
+)
+
+type Audit interface {
+	GetAuditEntriesByCustomerID(ctx context.Context, customerID uint) ([]*entity.AuditEntry, error)
+	// VerifyAuditLog walks the audit log of every tenant and checks its hash
+	// chain. It returns the number of entries that verified; at the first
+	// entry that does not, the error wraps ErrAuditChainBroken.
+	VerifyAuditLog(ctx context.Context) (int, error)
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"crud-customer/config"
+	"crud-customer/internal/entity"
+	"crud-customer/internal/repository"
+	"errors"
+	"fmt"
+)
+
+var ErrAuditChainBroken = errors.New("audit chain broken")
+
+type auditImpl struct {
+	auditRepo repository.Audit
+	cfg       *config.Config
+}
+
+func (a *auditImpl) GetAuditEntriesByCustomerID(ctx context.Context, customerID uint) ([]*entity.AuditEntry, error) {
+	return a.auditRepo.GetAuditEntriesByCustomerID(ctx, customerID)
+}
+
+func (a *auditImpl) VerifyAuditLog(ctx context.Context) (int, error) {
+	verified := 0
+	prevHash := ""
+	err := a.auditRepo.ScanAuditEntries(ctx, func(entry *entity.AuditEntry) error {
+		if entry.PrevHash != prevHash {
+			return fmt.Errorf("%w at entry %d: previous hash does not match, an entry before it was removed or altered", ErrAuditChainBroken, entry.ID)
+		}
+		if entry.ComputeHash() != entry.Hash {
+			return fmt.Errorf("%w at entry %d: hash does not match its content", ErrAuditChainBroken, entry.ID)
+		}
+		prevHash = entry.Hash
+		verified++
+		return nil
+	})
+	return verified, err
+}
+
+func NewAudit(cfg *config.Config, auditRepo repository.Audit) Audit {
+	return &auditImpl{
+		auditRepo: auditRepo,
+		cfg:       cfg,
+	}
+}
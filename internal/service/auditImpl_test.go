@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"crud-customer/config"
+	"crud-customer/internal/entity"
+	mockrepo "crud-customer/mocks/internal_/repository"
+	"fmt"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+type AuditImplTestSuite struct {
+	suite.Suite
+	mockAuditRepo *mockrepo.Audit
+	audit         Audit
+}
+
+func (s *AuditImplTestSuite) SetupTest() {
+	s.mockAuditRepo = mockrepo.NewAudit(s.T())
+	s.audit = NewAudit(&config.Config{}, s.mockAuditRepo)
+}
+
+func (s *AuditImplTestSuite) TearDownTest() {
+	s.mockAuditRepo = nil
+	s.audit = nil
+}
+
+// auditChain returns n correctly chained entries.
+func auditChain(n int) []*entity.AuditEntry {
+	var entries []*entity.AuditEntry
+	prevHash := ""
+	for i := 1; i <= n; i++ {
+		entry := &entity.AuditEntry{
+			ID:         uint(i),
+			TenantID:   "default",
+			CustomerID: 1,
+			Action:     entity.AuditActionUpdate,
+			Actor:      "api_key:1",
+			Diff:       fmt.Sprintf(`{"age":{"before":%d,"after":%d}}`, i, i+1),
+			CreatedAt:  time.Date(2024, 1, 1, 0, 0, i, 0, time.UTC),
+			PrevHash:   prevHash,
+		}
+		entry.Hash = entry.ComputeHash()
+		prevHash = entry.Hash
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func (s *AuditImplTestSuite) expectScan(entries []*entity.AuditEntry) {
+	s.mockAuditRepo.EXPECT().ScanAuditEntries(mock.Anything, mock.Anything).RunAndReturn(
+		func(ctx context.Context, fn func(*entity.AuditEntry) error) error {
+			for _, entry := range entries {
+				if err := fn(entry); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+}
+
+func (s *AuditImplTestSuite) TestGetAuditEntriesByCustomerIDSuccess() {
+	want := auditChain(2)
+	s.mockAuditRepo.EXPECT().GetAuditEntriesByCustomerID(mock.Anything, uint(1)).Return(want, nil)
+
+	got, err := s.audit.GetAuditEntriesByCustomerID(context.Background(), 1)
+	s.NoError(err)
+	s.Equal(want, got)
+}
+
+func (s *AuditImplTestSuite) TestVerifyAuditLogSuccess() {
+	s.expectScan(auditChain(3))
+
+	got, err := s.audit.VerifyAuditLog(context.Background())
+	s.NoError(err)
+	s.Equal(3, got)
+}
+
+func (s *AuditImplTestSuite) TestVerifyAuditLogAlteredEntry() {
+	entries := auditChain(3)
+	entries[1].Diff = `{"age":{"before":2,"after":99}}`
+	s.expectScan(entries)
+
+	got, err := s.audit.VerifyAuditLog(context.Background())
+	s.ErrorIs(err, ErrAuditChainBroken)
+	s.ErrorContains(err, "entry 2: hash does not match")
+	s.Equal(1, got)
+}
+
+func (s *AuditImplTestSuite) TestVerifyAuditLogRemovedEntry() {
+	entries := auditChain(3)
+	s.expectScan([]*entity.AuditEntry{entries[0], entries[2]})
+
+	got, err := s.audit.VerifyAuditLog(context.Background())
+	s.ErrorIs(err, ErrAuditChainBroken)
+	s.ErrorContains(err, "entry 3: previous hash does not match")
+	s.Equal(1, got)
+}
+
+func (s *AuditImplTestSuite) TestVerifyAuditLogError() {
+	s.mockAuditRepo.EXPECT().ScanAuditEntries(mock.Anything, mock.Anything).Return(fmt.Errorf("error"))
+
+	_, err := s.audit.VerifyAuditLog(context.Background())
+	s.Error(err)
+	s.NotErrorIs(err, ErrAuditChainBroken)
+}
+
+func TestAuditImplSuite(t *testing.T) {
+	suite.Run(t, new(AuditImplTestSuite))
+}
@@ -3,12 +3,30 @@ package service
 import (
 	"context"
 	"crud-customer/internal/entity"
+	"crud-customer/internal/repository"
+	"errors"
+)
+
+var (
+	// ErrCustomerNotFound is returned by RestoreCustomer when there is no
+	// deleted customer with the ID.
+	ErrCustomerNotFound = errors.New("customer not found")
+	// ErrCustomerDeleted is returned by UpsertCustomerByExternalID when the
+	// customer with the external ID is deleted. It has to be restored first.
+	ErrCustomerDeleted = repository.ErrCustomerDeleted
 )
 
 type Customer interface {
 	CreateCustomer(ctx context.Context, name string, age uint) (*entity.Customer, error)
 	UpdateCustomer(ctx context.Context, id uint, customer *entity.Customer) (*entity.Customer, error)
 	GetCustomerByID(ctx context.Context, id uint) (*entity.Customer, error)
+	// BatchGetCustomers returns the customers found for ids in request order
+	// along with the IDs that do not exist. Duplicate IDs are returned once.
+	BatchGetCustomers(ctx context.Context, ids []uint) ([]*entity.Customer, []uint, error)
+	// DeleteCustomer soft-deletes the customer, so it can be restored.
 	DeleteCustomer(ctx context.Context, id uint) error
+	RestoreCustomer(ctx context.Context, id uint) (*entity.Customer, error)
 	GetAllCustomer(ctx context.Context) ([]*entity.Customer, error)
+	UpsertCustomerByExternalID(ctx context.Context, source string, externalID string, name string, age uint) (*entity.Customer, bool, error)
+	ExportCustomers(ctx context.Context, filter entity.CustomerFilter, fn func(customer *entity.Customer) error) error
 }
@@ -5,6 +5,9 @@ import (
 	"crud-customer/config"
 	"crud-customer/internal/entity"
 	"crud-customer/internal/repository"
+	"errors"
+	"gorm.io/gorm"
+	"log/slog"
 )
 
 type customerImpl struct {
@@ -23,6 +26,7 @@ func (c *customerImpl) CreateCustomer(ctx context.Context, name string, age uint
 		return nil, err
 	}
 	customer.ID = *id
+	slog.InfoContext(ctx, "customer created", "customer_id", customer.ID)
 	return customer, nil
 }
 
@@ -31,6 +35,7 @@ func (c *customerImpl) UpdateCustomer(ctx context.Context, id uint, customer *en
 	if err != nil {
 		return nil, err
 	}
+	slog.InfoContext(ctx, "customer updated", "customer_id", id)
 	return customer, nil
 }
 
@@ -42,8 +47,55 @@ func (c *customerImpl) GetCustomerByID(ctx context.Context, id uint) (*entity.Cu
 	return customer, nil
 }
 
+func (c *customerImpl) BatchGetCustomers(ctx context.Context, ids []uint) ([]*entity.Customer, []uint, error) {
+	var uniqueIDs []uint
+	seen := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			uniqueIDs = append(uniqueIDs, id)
+		}
+	}
+
+	customers, err := c.customerRepo.GetCustomersByIDs(ctx, uniqueIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	byID := make(map[uint]*entity.Customer, len(customers))
+	for _, customer := range customers {
+		byID[customer.ID] = customer
+	}
+
+	found := make([]*entity.Customer, 0, len(uniqueIDs))
+	missing := make([]uint, 0)
+	for _, id := range uniqueIDs {
+		if customer, ok := byID[id]; ok {
+			found = append(found, customer)
+		} else {
+			missing = append(missing, id)
+		}
+	}
+	return found, missing, nil
+}
+
 func (c *customerImpl) DeleteCustomer(ctx context.Context, id uint) error {
-	return c.customerRepo.DeleteCustomer(ctx, id)
+	if err := c.customerRepo.DeleteCustomer(ctx, id); err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "customer deleted", "customer_id", id)
+	return nil
+}
+
+func (c *customerImpl) RestoreCustomer(ctx context.Context, id uint) (*entity.Customer, error) {
+	customer, err := c.customerRepo.RestoreCustomer(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrCustomerNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	slog.InfoContext(ctx, "customer restored", "customer_id", id)
+	return customer, nil
 }
 
 func (c *customerImpl) GetAllCustomer(ctx context.Context) ([]*entity.Customer, error) {
@@ -54,6 +106,26 @@ func (c *customerImpl) GetAllCustomer(ctx context.Context) ([]*entity.Customer,
 	return customers, nil
 }
 
+func (c *customerImpl) UpsertCustomerByExternalID(ctx context.Context, source string, externalID string, name string, age uint) (*entity.Customer, bool, error) {
+	customer := &entity.Customer{
+		Name:           &name,
+		Age:            &age,
+		ExternalSource: &source,
+		ExternalID:     &externalID,
+	}
+
+	customer, created, err := c.customerRepo.UpsertCustomerByExternalID(ctx, customer)
+	if err != nil {
+		return nil, false, err
+	}
+	slog.InfoContext(ctx, "customer upserted", "customer_id", customer.ID, "external_source", source, "created", created)
+	return customer, created, nil
+}
+
+func (c *customerImpl) ExportCustomers(ctx context.Context, filter entity.CustomerFilter, fn func(customer *entity.Customer) error) error {
+	return c.customerRepo.ExportCustomers(ctx, filter, fn)
+}
+
 func NewCustomer(cfg *config.Config, customerRepo repository.Customer) Customer {
 	return &customerImpl{
 		customerRepo: customerRepo,
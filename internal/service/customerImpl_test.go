@@ -1,14 +1,20 @@
 package service
 
 import (
+	"bytes"
 	"context"
 	"crud-customer/config"
 	"crud-customer/internal/entity"
 	mockrepo "crud-customer/mocks/internal_/repository"
+	"crud-customer/pkg/logging"
+	"crud-customer/pkg/reqctx"
 	"crud-customer/util/typehelper"
+	"encoding/json"
 	"fmt"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+	"log/slog"
 	"testing"
 )
 
@@ -56,6 +62,24 @@ func (s *CustomerImplTestSuite) TestCreateCustomerError() {
 	s.Nil(got)
 }
 
+func (s *CustomerImplTestSuite) TestCreateCustomerLogsRequestID() {
+	var buf bytes.Buffer
+	defaultLogger := slog.Default()
+	logging.Setup(&buf, slog.LevelInfo)
+	defer slog.SetDefault(defaultLogger)
+	s.mockCustomerRepo.EXPECT().CreateCustomer(mock.Anything, mock.Anything).Return(typehelper.GetPointer(uint(1)), nil)
+
+	_, err := s.customer.CreateCustomer(reqctx.WithRequestID(context.Background(), "req-1"), "John Doe", 20)
+	s.NoError(err)
+
+	var record map[string]interface{}
+	s.NoError(json.Unmarshal(buf.Bytes(), &record))
+	s.Equal("customer created", record["msg"])
+	s.Equal("req-1", record["request_id"])
+	s.Equal(float64(1), record["customer_id"])
+	s.NotContains(buf.String(), "John Doe")
+}
+
 func (s *CustomerImplTestSuite) TestUpdateCustomerSuccess() {
 	customer := &entity.Customer{
 		ID:   1,
@@ -108,6 +132,27 @@ func (s *CustomerImplTestSuite) TestGetCustomerByIDError() {
 	s.Nil(got)
 }
 
+func (s *CustomerImplTestSuite) TestBatchGetCustomersSuccess() {
+	customer1 := &entity.Customer{ID: 1, Name: typehelper.GetPointer("John Doe"), Age: typehelper.GetPointer(uint(20))}
+	customer3 := &entity.Customer{ID: 3, Name: typehelper.GetPointer("Jane Doe"), Age: typehelper.GetPointer(uint(30))}
+
+	s.mockCustomerRepo.EXPECT().GetCustomersByIDs(mock.Anything, []uint{3, 2, 1}).Return([]*entity.Customer{customer1, customer3}, nil)
+
+	got, missing, err := s.customer.BatchGetCustomers(context.Background(), []uint{3, 2, 3, 1})
+	s.NoError(err)
+	s.Equal([]*entity.Customer{customer3, customer1}, got)
+	s.Equal([]uint{2}, missing)
+}
+
+func (s *CustomerImplTestSuite) TestBatchGetCustomersError() {
+	s.mockCustomerRepo.EXPECT().GetCustomersByIDs(mock.Anything, []uint{1}).Return(nil, fmt.Errorf("error"))
+
+	got, missing, err := s.customer.BatchGetCustomers(context.Background(), []uint{1})
+	s.Error(err)
+	s.Nil(got)
+	s.Nil(missing)
+}
+
 func (s *CustomerImplTestSuite) TestDeleteCustomerSuccess() {
 	s.mockCustomerRepo.EXPECT().DeleteCustomer(mock.Anything, uint(1)).Return(nil)
 
@@ -122,6 +167,30 @@ func (s *CustomerImplTestSuite) TestDeleteCustomerError() {
 	s.Error(err)
 }
 
+func (s *CustomerImplTestSuite) TestRestoreCustomerSuccess() {
+	want := &entity.Customer{
+		ID:   1,
+		Name: typehelper.GetPointer("John Doe"),
+		Age:  typehelper.GetPointer(uint(20)),
+	}
+	s.mockCustomerRepo.EXPECT().RestoreCustomer(mock.Anything, uint(1)).Return(want, nil)
+
+	got, err := s.customer.RestoreCustomer(context.Background(), 1)
+	s.NoError(err)
+	s.Equal(want, got)
+}
+
+func (s *CustomerImplTestSuite) TestRestoreCustomerError() {
+	s.mockCustomerRepo.EXPECT().RestoreCustomer(mock.Anything, uint(1)).Return(nil, gorm.ErrRecordNotFound).Once()
+	s.mockCustomerRepo.EXPECT().RestoreCustomer(mock.Anything, uint(2)).Return(nil, fmt.Errorf("error")).Once()
+
+	_, err := s.customer.RestoreCustomer(context.Background(), 1)
+	s.ErrorIs(err, ErrCustomerNotFound)
+	_, err = s.customer.RestoreCustomer(context.Background(), 2)
+	s.Error(err)
+	s.NotErrorIs(err, ErrCustomerNotFound)
+}
+
 func (s *CustomerImplTestSuite) TestGetAllCustomerSuccess() {
 	want := []*entity.Customer{
 		{
@@ -146,6 +215,56 @@ func (s *CustomerImplTestSuite) TestGetAllCustomerError() {
 	s.Nil(got)
 }
 
+func (s *CustomerImplTestSuite) TestUpsertCustomerByExternalIDSuccess() {
+	want := &entity.Customer{
+		ID:             1,
+		Name:           typehelper.GetPointer("John Doe"),
+		Age:            typehelper.GetPointer(uint(20)),
+		ExternalSource: typehelper.GetPointer("crm"),
+		ExternalID:     typehelper.GetPointer("A-1"),
+	}
+
+	s.mockCustomerRepo.EXPECT().UpsertCustomerByExternalID(mock.Anything, &entity.Customer{
+		Name:           typehelper.GetPointer("John Doe"),
+		Age:            typehelper.GetPointer(uint(20)),
+		ExternalSource: typehelper.GetPointer("crm"),
+		ExternalID:     typehelper.GetPointer("A-1"),
+	}).Return(want, true, nil)
+
+	got, created, err := s.customer.UpsertCustomerByExternalID(context.Background(), "crm", "A-1", "John Doe", 20)
+	s.NoError(err)
+	s.True(created)
+	s.Equal(want, got)
+}
+
+func (s *CustomerImplTestSuite) TestUpsertCustomerByExternalIDError() {
+	s.mockCustomerRepo.EXPECT().UpsertCustomerByExternalID(mock.Anything, mock.Anything).Return(nil, false, fmt.Errorf("error"))
+
+	got, created, err := s.customer.UpsertCustomerByExternalID(context.Background(), "crm", "A-1", "John Doe", 20)
+	s.Error(err)
+	s.False(created)
+	s.Nil(got)
+}
+
+func (s *CustomerImplTestSuite) TestExportCustomersSuccess() {
+	filter := entity.CustomerFilter{MinAge: typehelper.GetPointer(uint(18))}
+	s.mockCustomerRepo.EXPECT().ExportCustomers(mock.Anything, filter, mock.Anything).Return(nil)
+
+	err := s.customer.ExportCustomers(context.Background(), filter, func(customer *entity.Customer) error {
+		return nil
+	})
+	s.NoError(err)
+}
+
+func (s *CustomerImplTestSuite) TestExportCustomersError() {
+	s.mockCustomerRepo.EXPECT().ExportCustomers(mock.Anything, entity.CustomerFilter{}, mock.Anything).Return(fmt.Errorf("error"))
+
+	err := s.customer.ExportCustomers(context.Background(), entity.CustomerFilter{}, func(customer *entity.Customer) error {
+		return nil
+	})
+	s.Error(err)
+}
+
 func TestCustomerImplTestSuite(t *testing.T) {
 	suite.Run(t, new(CustomerImplTestSuite))
 }
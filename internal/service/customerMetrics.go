@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"crud-customer/internal/entity"
+	"crud-customer/pkg/metrics"
+)
+
+const customerServiceName = "customer"
+
+// customerMetrics counts the calls of every method of the wrapped Customer
+// service, by result.
+type customerMetrics struct {
+	next Customer
+}
+
+func (c *customerMetrics) CreateCustomer(ctx context.Context, name string, age uint) (*entity.Customer, error) {
+	customer, err := c.next.CreateCustomer(ctx, name, age)
+	metrics.ObserveServiceCall(customerServiceName, "CreateCustomer", err)
+	return customer, err
+}
+
+func (c *customerMetrics) UpdateCustomer(ctx context.Context, id uint, customer *entity.Customer) (*entity.Customer, error) {
+	updated, err := c.next.UpdateCustomer(ctx, id, customer)
+	metrics.ObserveServiceCall(customerServiceName, "UpdateCustomer", err)
+	return updated, err
+}
+
+func (c *customerMetrics) GetCustomerByID(ctx context.Context, id uint) (*entity.Customer, error) {
+	customer, err := c.next.GetCustomerByID(ctx, id)
+	metrics.ObserveServiceCall(customerServiceName, "GetCustomerByID", err)
+	return customer, err
+}
+
+func (c *customerMetrics) BatchGetCustomers(ctx context.Context, ids []uint) ([]*entity.Customer, []uint, error) {
+	customers, missingIDs, err := c.next.BatchGetCustomers(ctx, ids)
+	metrics.ObserveServiceCall(customerServiceName, "BatchGetCustomers", err)
+	return customers, missingIDs, err
+}
+
+func (c *customerMetrics) DeleteCustomer(ctx context.Context, id uint) error {
+	err := c.next.DeleteCustomer(ctx, id)
+	metrics.ObserveServiceCall(customerServiceName, "DeleteCustomer", err)
+	return err
+}
+
+func (c *customerMetrics) RestoreCustomer(ctx context.Context, id uint) (*entity.Customer, error) {
+	customer, err := c.next.RestoreCustomer(ctx, id)
+	metrics.ObserveServiceCall(customerServiceName, "RestoreCustomer", err)
+	return customer, err
+}
+
+func (c *customerMetrics) GetAllCustomer(ctx context.Context) ([]*entity.Customer, error) {
+	customers, err := c.next.GetAllCustomer(ctx)
+	metrics.ObserveServiceCall(customerServiceName, "GetAllCustomer", err)
+	return customers, err
+}
+
+func (c *customerMetrics) UpsertCustomerByExternalID(ctx context.Context, source string, externalID string, name string, age uint) (*entity.Customer, bool, error) {
+	customer, created, err := c.next.UpsertCustomerByExternalID(ctx, source, externalID, name, age)
+	metrics.ObserveServiceCall(customerServiceName, "UpsertCustomerByExternalID", err)
+	return customer, created, err
+}
+
+func (c *customerMetrics) ExportCustomers(ctx context.Context, filter entity.CustomerFilter, fn func(customer *entity.Customer) error) error {
+	err := c.next.ExportCustomers(ctx, filter, fn)
+	metrics.ObserveServiceCall(customerServiceName, "ExportCustomers", err)
+	return err
+}
+
+// NewCustomerMetrics wraps next so that its calls are counted.
+func NewCustomerMetrics(next Customer) Customer {
+	return &customerMetrics{next: next}
+}
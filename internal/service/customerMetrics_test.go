@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"crud-customer/internal/entity"
+	mockservice "crud-customer/mocks/internal_/service"
+	"crud-customer/pkg/metrics"
+	"crud-customer/util/typehelper"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+type CustomerMetricsTestSuite struct {
+	suite.Suite
+	mockCustomerService *mockservice.Customer
+	customer            Customer
+}
+
+func (s *CustomerMetricsTestSuite) SetupTest() {
+	s.mockCustomerService = mockservice.NewCustomer(s.T())
+	s.customer = NewCustomerMetrics(s.mockCustomerService)
+}
+
+func (s *CustomerMetricsTestSuite) TearDownTest() {
+	s.mockCustomerService = nil
+	s.customer = nil
+}
+
+func (s *CustomerMetricsTestSuite) callCount(method string, result string) float64 {
+	return testutil.ToFloat64(metrics.ServiceCallsTotal.WithLabelValues(customerServiceName, method, result))
+}
+
+func (s *CustomerMetricsTestSuite) TestCountsSuccess() {
+	want := &entity.Customer{ID: 1, Name: typehelper.GetPointer("John Doe"), Age: typehelper.GetPointer(uint(20))}
+	s.mockCustomerService.EXPECT().GetCustomerByID(mock.Anything, uint(1)).Return(want, nil)
+	before := s.callCount("GetCustomerByID", metrics.ResultOK)
+
+	got, err := s.customer.GetCustomerByID(context.Background(), 1)
+	s.NoError(err)
+	s.Equal(want, got)
+	s.Equal(before+1, s.callCount("GetCustomerByID", metrics.ResultOK))
+}
+
+func (s *CustomerMetricsTestSuite) TestCountsError() {
+	s.mockCustomerService.EXPECT().DeleteCustomer(mock.Anything, uint(1)).Return(fmt.Errorf("error"))
+	before := s.callCount("DeleteCustomer", metrics.ResultError)
+
+	err := s.customer.DeleteCustomer(context.Background(), 1)
+	s.Error(err)
+	s.Equal(before+1, s.callCount("DeleteCustomer", metrics.ResultError))
+}
+
+func (s *CustomerMetricsTestSuite) TestPassesResultsThrough() {
+	customers := []*entity.Customer{{ID: 1}}
+	s.mockCustomerService.EXPECT().BatchGetCustomers(mock.Anything, []uint{1, 2}).Return(customers, []uint{2}, nil)
+	s.mockCustomerService.EXPECT().UpsertCustomerByExternalID(mock.Anything, "crm", "42", "John Doe", uint(20)).Return(customers[0], true, nil)
+
+	got, missingIDs, err := s.customer.BatchGetCustomers(context.Background(), []uint{1, 2})
+	s.NoError(err)
+	s.Equal(customers, got)
+	s.Equal([]uint{2}, missingIDs)
+
+	customer, created, err := s.customer.UpsertCustomerByExternalID(context.Background(), "crm", "42", "John Doe", 20)
+	s.NoError(err)
+	s.Equal(customers[0], customer)
+	s.True(created)
+}
+
+func TestCustomerMetricsSuite(t *testing.T) {
+	suite.Run(t, new(CustomerMetricsTestSuite))
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"crud-customer/internal/entity"
+	"crud-customer/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const customerSpanPrefix = "service.Customer/"
+
+// customerTracing records a span for every method of the wrapped Customer
+// service.
+type customerTracing struct {
+	next Customer
+}
+
+func (c *customerTracing) CreateCustomer(ctx context.Context, name string, age uint) (*entity.Customer, error) {
+	ctx, span := tracing.Start(ctx, customerSpanPrefix+"CreateCustomer")
+	customer, err := c.next.CreateCustomer(ctx, name, age)
+	tracing.End(span, err)
+	return customer, err
+}
+
+func (c *customerTracing) UpdateCustomer(ctx context.Context, id uint, customer *entity.Customer) (*entity.Customer, error) {
+	ctx, span := tracing.Start(ctx, customerSpanPrefix+"UpdateCustomer")
+	span.SetAttributes(attribute.Int64("customer.id", int64(id)))
+	updated, err := c.next.UpdateCustomer(ctx, id, customer)
+	tracing.End(span, err)
+	return updated, err
+}
+
+func (c *customerTracing) GetCustomerByID(ctx context.Context, id uint) (*entity.Customer, error) {
+	ctx, span := tracing.Start(ctx, customerSpanPrefix+"GetCustomerByID")
+	span.SetAttributes(attribute.Int64("customer.id", int64(id)))
+	customer, err := c.next.GetCustomerByID(ctx, id)
+	tracing.End(span, err)
+	return customer, err
+}
+
+func (c *customerTracing) BatchGetCustomers(ctx context.Context, ids []uint) ([]*entity.Customer, []uint, error) {
+	ctx, span := tracing.Start(ctx, customerSpanPrefix+"BatchGetCustomers")
+	span.SetAttributes(attribute.Int("customer.ids", len(ids)))
+	customers, missingIDs, err := c.next.BatchGetCustomers(ctx, ids)
+	tracing.End(span, err)
+	return customers, missingIDs, err
+}
+
+func (c *customerTracing) DeleteCustomer(ctx context.Context, id uint) error {
+	ctx, span := tracing.Start(ctx, customerSpanPrefix+"DeleteCustomer")
+	span.SetAttributes(attribute.Int64("customer.id", int64(id)))
+	err := c.next.DeleteCustomer(ctx, id)
+	tracing.End(span, err)
+	return err
+}
+
+func (c *customerTracing) RestoreCustomer(ctx context.Context, id uint) (*entity.Customer, error) {
+	ctx, span := tracing.Start(ctx, customerSpanPrefix+"RestoreCustomer")
+	span.SetAttributes(attribute.Int64("customer.id", int64(id)))
+	customer, err := c.next.RestoreCustomer(ctx, id)
+	tracing.End(span, err)
+	return customer, err
+}
+
+func (c *customerTracing) GetAllCustomer(ctx context.Context) ([]*entity.Customer, error) {
+	ctx, span := tracing.Start(ctx, customerSpanPrefix+"GetAllCustomer")
+	customers, err := c.next.GetAllCustomer(ctx)
+	tracing.End(span, err)
+	return customers, err
+}
+
+func (c *customerTracing) UpsertCustomerByExternalID(ctx context.Context, source string, externalID string, name string, age uint) (*entity.Customer, bool, error) {
+	ctx, span := tracing.Start(ctx, customerSpanPrefix+"UpsertCustomerByExternalID")
+	span.SetAttributes(attribute.String("customer.external_source", source))
+	customer, created, err := c.next.UpsertCustomerByExternalID(ctx, source, externalID, name, age)
+	tracing.End(span, err)
+	return customer, created, err
+}
+
+func (c *customerTracing) ExportCustomers(ctx context.Context, filter entity.CustomerFilter, fn func(customer *entity.Customer) error) error {
+	ctx, span := tracing.Start(ctx, customerSpanPrefix+"ExportCustomers")
+	err := c.next.ExportCustomers(ctx, filter, fn)
+	tracing.End(span, err)
+	return err
+}
+
+// NewCustomerTracing wraps next so that its calls are traced.
+func NewCustomerTracing(next Customer) Customer {
+	return &customerTracing{next: next}
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"crud-customer/internal/entity"
+	mockservice "crud-customer/mocks/internal_/service"
+	"fmt"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+	"testing"
+)
+
+type CustomerTracingTestSuite struct {
+	suite.Suite
+	recorder            *tracetest.SpanRecorder
+	mockCustomerService *mockservice.Customer
+	customer            Customer
+}
+
+func (s *CustomerTracingTestSuite) SetupTest() {
+	s.recorder = tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(s.recorder)))
+	s.mockCustomerService = mockservice.NewCustomer(s.T())
+	s.customer = NewCustomerTracing(s.mockCustomerService)
+}
+
+func (s *CustomerTracingTestSuite) TearDownTest() {
+	otel.SetTracerProvider(noop.NewTracerProvider())
+	s.recorder = nil
+	s.mockCustomerService = nil
+	s.customer = nil
+}
+
+func (s *CustomerTracingTestSuite) TestSpanWrapsCall() {
+	want := &entity.Customer{ID: 1}
+	s.mockCustomerService.EXPECT().GetCustomerByID(mock.Anything, uint(1)).
+		RunAndReturn(func(ctx context.Context, id uint) (*entity.Customer, error) {
+			// The wrapped service runs inside the span.
+			s.True(trace.SpanFromContext(ctx).SpanContext().IsValid())
+			return want, nil
+		})
+
+	got, err := s.customer.GetCustomerByID(context.Background(), 1)
+	s.NoError(err)
+	s.Equal(want, got)
+
+	spans := s.recorder.Ended()
+	s.Require().Len(spans, 1)
+	s.Equal("service.Customer/GetCustomerByID", spans[0].Name())
+	s.Equal(codes.Unset, spans[0].Status().Code)
+}
+
+func (s *CustomerTracingTestSuite) TestSpanRecordsError() {
+	s.mockCustomerService.EXPECT().DeleteCustomer(mock.Anything, uint(1)).Return(fmt.Errorf("error"))
+
+	err := s.customer.DeleteCustomer(context.Background(), 1)
+	s.Error(err)
+
+	spans := s.recorder.Ended()
+	s.Require().Len(spans, 1)
+	s.Equal("service.Customer/DeleteCustomer", spans[0].Name())
+	s.Equal(codes.Error, spans[0].Status().Code)
+	s.Equal("error", spans[0].Status().Description)
+}
+
+func TestCustomerTracingSuite(t *testing.T) {
+	suite.Run(t, new(CustomerTracingTestSuite))
+}
@@ -0,0 +1,12 @@
+package service
+
+import "context"
+
+type KeyRotation interface {
+	// RotateKeys re-encrypts every customer, outbox event and audit entry
+	// under the
+	// primary key of the keyring, batchSize rows per transaction. progress,
+	// if set, is called after each batch with the table and the number of
+	// rows re-encrypted so far.
+	RotateKeys(ctx context.Context, batchSize int, progress func(table string, done int)) error
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"crud-customer/config"
+	"crud-customer/internal/repository"
+	"fmt"
+)
+
+type keyRotationImpl struct {
+	customerRepo repository.Customer
+	outboxRepo   repository.Outbox
+	auditRepo    repository.Audit
+	cfg          *config.Config
+}
+
+func (k *keyRotationImpl) RotateKeys(ctx context.Context, batchSize int, progress func(table string, done int)) error {
+	if err := reencryptInBatches(ctx, "customers", batchSize, k.customerRepo.ReencryptCustomers, progress); err != nil {
+		return err
+	}
+	if err := reencryptInBatches(ctx, "outbox_events", batchSize, k.outboxRepo.ReencryptEvents, progress); err != nil {
+		return err
+	}
+	return reencryptInBatches(ctx, "audit_entries", batchSize, k.auditRepo.ReencryptAuditEntries, progress)
+}
+
+// reencryptInBatches walks a table in ID order. Each batch commits on its
+// own, so an interrupted rotation can simply be run again.
+func reencryptInBatches(ctx context.Context, table string, batchSize int,
+	reencrypt func(ctx context.Context, afterID uint, limit int) (uint, int, error),
+	progress func(table string, done int)) error {
+	var afterID uint
+	done := 0
+	for {
+		lastID, n, err := reencrypt(ctx, afterID, batchSize)
+		if err != nil {
+			return fmt.Errorf("error re-encrypting %s after id %d: %w", table, afterID, err)
+		}
+		done += n
+		afterID = lastID
+		if n > 0 && progress != nil {
+			progress(table, done)
+		}
+		if n < batchSize {
+			return nil
+		}
+	}
+}
+
+func NewKeyRotation(cfg *config.Config, customerRepo repository.Customer, outboxRepo repository.Outbox, auditRepo repository.Audit) KeyRotation {
+	return &keyRotationImpl{
+		customerRepo: customerRepo,
+		outboxRepo:   outboxRepo,
+		auditRepo:    auditRepo,
+		cfg:          cfg,
+	}
+}
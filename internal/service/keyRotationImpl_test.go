@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"crud-customer/config"
+	mockrepo "crud-customer/mocks/internal_/repository"
+	"fmt"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+type KeyRotationImplTestSuite struct {
+	suite.Suite
+	mockCustomerRepo *mockrepo.Customer
+	mockOutboxRepo   *mockrepo.Outbox
+	mockAuditRepo    *mockrepo.Audit
+	keyRotation      KeyRotation
+}
+
+func (s *KeyRotationImplTestSuite) SetupTest() {
+	s.mockCustomerRepo = mockrepo.NewCustomer(s.T())
+	s.mockOutboxRepo = mockrepo.NewOutbox(s.T())
+	s.mockAuditRepo = mockrepo.NewAudit(s.T())
+	s.keyRotation = NewKeyRotation(&config.Config{}, s.mockCustomerRepo, s.mockOutboxRepo, s.mockAuditRepo)
+}
+
+func (s *KeyRotationImplTestSuite) TearDownTest() {
+	s.mockCustomerRepo = nil
+	s.mockOutboxRepo = nil
+	s.mockAuditRepo = nil
+	s.keyRotation = nil
+}
+
+func (s *KeyRotationImplTestSuite) TestRotateKeysSuccess() {
+	s.mockCustomerRepo.EXPECT().ReencryptCustomers(mock.Anything, uint(0), 2).Return(uint(2), 2, nil)
+	s.mockCustomerRepo.EXPECT().ReencryptCustomers(mock.Anything, uint(2), 2).Return(uint(5), 1, nil)
+	s.mockOutboxRepo.EXPECT().ReencryptEvents(mock.Anything, uint(0), 2).Return(uint(0), 0, nil)
+	s.mockAuditRepo.EXPECT().ReencryptAuditEntries(mock.Anything, uint(0), 2).Return(uint(1), 1, nil)
+
+	var got []string
+	err := s.keyRotation.RotateKeys(context.Background(), 2, func(table string, done int) {
+		got = append(got, fmt.Sprintf("%s:%d", table, done))
+	})
+	s.NoError(err)
+	s.Equal([]string{"customers:2", "customers:3", "audit_entries:1"}, got)
+}
+
+func (s *KeyRotationImplTestSuite) TestRotateKeysError() {
+	s.mockCustomerRepo.EXPECT().ReencryptCustomers(mock.Anything, uint(0), 2).Return(uint(2), 2, nil)
+	s.mockCustomerRepo.EXPECT().ReencryptCustomers(mock.Anything, uint(2), 2).Return(uint(2), 0, fmt.Errorf("error"))
+
+	err := s.keyRotation.RotateKeys(context.Background(), 2, nil)
+	s.ErrorContains(err, "error re-encrypting customers after id 2")
+}
+
+func TestKeyRotationImplSuite(t *testing.T) {
+	suite.Run(t, new(KeyRotationImplTestSuite))
+}
@@ -0,0 +1,311 @@
+// Code generated by mockery v2.44.2. DO NOT EDIT.
+
+package repository
+
+import (
+	context "context"
+	entity "crud-customer/internal/entity"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// APIKey is an autogenerated mock type for the APIKey type
+type APIKey struct {
+	mock.Mock
+}
+
+type APIKey_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *APIKey) EXPECT() *APIKey_Expecter {
+	return &APIKey_Expecter{mock: &_m.Mock}
+}
+
+// CreateAPIKey provides a mock function with given fields: ctx, apiKey
+func (_m *APIKey) CreateAPIKey(ctx context.Context, apiKey *entity.APIKey) (*uint, error) {
+	ret := _m.Called(ctx, apiKey)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateAPIKey")
+	}
+
+	var r0 *uint
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.APIKey) (*uint, error)); ok {
+		return rf(ctx, apiKey)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.APIKey) *uint); ok {
+		r0 = rf(ctx, apiKey)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*uint)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *entity.APIKey) error); ok {
+		r1 = rf(ctx, apiKey)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// APIKey_CreateAPIKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateAPIKey'
+type APIKey_CreateAPIKey_Call struct {
+	*mock.Call
+}
+
+// CreateAPIKey is a helper method to define mock.On call
+//   - ctx context.Context
+//   - apiKey *entity.APIKey
+func (_e *APIKey_Expecter) CreateAPIKey(ctx interface{}, apiKey interface{}) *APIKey_CreateAPIKey_Call {
+	return &APIKey_CreateAPIKey_Call{Call: _e.mock.On("CreateAPIKey", ctx, apiKey)}
+}
+
+func (_c *APIKey_CreateAPIKey_Call) Run(run func(ctx context.Context, apiKey *entity.APIKey)) *APIKey_CreateAPIKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*entity.APIKey))
+	})
+	return _c
+}
+
+func (_c *APIKey_CreateAPIKey_Call) Return(_a0 *uint, _a1 error) *APIKey_CreateAPIKey_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *APIKey_CreateAPIKey_Call) RunAndReturn(run func(context.Context, *entity.APIKey) (*uint, error)) *APIKey_CreateAPIKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetAPIKeyByPrefix provides a mock function with given fields: ctx, prefix
+func (_m *APIKey) GetAPIKeyByPrefix(ctx context.Context, prefix string) (*entity.APIKey, error) {
+	ret := _m.Called(ctx, prefix)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAPIKeyByPrefix")
+	}
+
+	var r0 *entity.APIKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*entity.APIKey, error)); ok {
+		return rf(ctx, prefix)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *entity.APIKey); ok {
+		r0 = rf(ctx, prefix)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.APIKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, prefix)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// APIKey_GetAPIKeyByPrefix_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetAPIKeyByPrefix'
+type APIKey_GetAPIKeyByPrefix_Call struct {
+	*mock.Call
+}
+
+// GetAPIKeyByPrefix is a helper method to define mock.On call
+//   - ctx context.Context
+//   - prefix string
+func (_e *APIKey_Expecter) GetAPIKeyByPrefix(ctx interface{}, prefix interface{}) *APIKey_GetAPIKeyByPrefix_Call {
+	return &APIKey_GetAPIKeyByPrefix_Call{Call: _e.mock.On("GetAPIKeyByPrefix", ctx, prefix)}
+}
+
+func (_c *APIKey_GetAPIKeyByPrefix_Call) Run(run func(ctx context.Context, prefix string)) *APIKey_GetAPIKeyByPrefix_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *APIKey_GetAPIKeyByPrefix_Call) Return(_a0 *entity.APIKey, _a1 error) *APIKey_GetAPIKeyByPrefix_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *APIKey_GetAPIKeyByPrefix_Call) RunAndReturn(run func(context.Context, string) (*entity.APIKey, error)) *APIKey_GetAPIKeyByPrefix_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetAllAPIKey provides a mock function with given fields: ctx
+func (_m *APIKey) GetAllAPIKey(ctx context.Context) ([]*entity.APIKey, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAllAPIKey")
+	}
+
+	var r0 []*entity.APIKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]*entity.APIKey, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []*entity.APIKey); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*entity.APIKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// APIKey_GetAllAPIKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetAllAPIKey'
+type APIKey_GetAllAPIKey_Call struct {
+	*mock.Call
+}
+
+// GetAllAPIKey is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *APIKey_Expecter) GetAllAPIKey(ctx interface{}) *APIKey_GetAllAPIKey_Call {
+	return &APIKey_GetAllAPIKey_Call{Call: _e.mock.On("GetAllAPIKey", ctx)}
+}
+
+func (_c *APIKey_GetAllAPIKey_Call) Run(run func(ctx context.Context)) *APIKey_GetAllAPIKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *APIKey_GetAllAPIKey_Call) Return(_a0 []*entity.APIKey, _a1 error) *APIKey_GetAllAPIKey_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *APIKey_GetAllAPIKey_Call) RunAndReturn(run func(context.Context) ([]*entity.APIKey, error)) *APIKey_GetAllAPIKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RevokeAPIKey provides a mock function with given fields: ctx, id, revokedAt
+func (_m *APIKey) RevokeAPIKey(ctx context.Context, id uint, revokedAt time.Time) error {
+	ret := _m.Called(ctx, id, revokedAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeAPIKey")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, time.Time) error); ok {
+		r0 = rf(ctx, id, revokedAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// APIKey_RevokeAPIKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RevokeAPIKey'
+type APIKey_RevokeAPIKey_Call struct {
+	*mock.Call
+}
+
+// RevokeAPIKey is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id uint
+//   - revokedAt time.Time
+func (_e *APIKey_Expecter) RevokeAPIKey(ctx interface{}, id interface{}, revokedAt interface{}) *APIKey_RevokeAPIKey_Call {
+	return &APIKey_RevokeAPIKey_Call{Call: _e.mock.On("RevokeAPIKey", ctx, id, revokedAt)}
+}
+
+func (_c *APIKey_RevokeAPIKey_Call) Run(run func(ctx context.Context, id uint, revokedAt time.Time)) *APIKey_RevokeAPIKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uint), args[2].(time.Time))
+	})
+	return _c
+}
+
+func (_c *APIKey_RevokeAPIKey_Call) Return(_a0 error) *APIKey_RevokeAPIKey_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *APIKey_RevokeAPIKey_Call) RunAndReturn(run func(context.Context, uint, time.Time) error) *APIKey_RevokeAPIKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateLastUsed provides a mock function with given fields: ctx, id, lastUsedAt
+func (_m *APIKey) UpdateLastUsed(ctx context.Context, id uint, lastUsedAt time.Time) error {
+	ret := _m.Called(ctx, id, lastUsedAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateLastUsed")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, time.Time) error); ok {
+		r0 = rf(ctx, id, lastUsedAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// APIKey_UpdateLastUsed_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateLastUsed'
+type APIKey_UpdateLastUsed_Call struct {
+	*mock.Call
+}
+
+// UpdateLastUsed is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id uint
+//   - lastUsedAt time.Time
+func (_e *APIKey_Expecter) UpdateLastUsed(ctx interface{}, id interface{}, lastUsedAt interface{}) *APIKey_UpdateLastUsed_Call {
+	return &APIKey_UpdateLastUsed_Call{Call: _e.mock.On("UpdateLastUsed", ctx, id, lastUsedAt)}
+}
+
+func (_c *APIKey_UpdateLastUsed_Call) Run(run func(ctx context.Context, id uint, lastUsedAt time.Time)) *APIKey_UpdateLastUsed_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uint), args[2].(time.Time))
+	})
+	return _c
+}
+
+func (_c *APIKey_UpdateLastUsed_Call) Return(_a0 error) *APIKey_UpdateLastUsed_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *APIKey_UpdateLastUsed_Call) RunAndReturn(run func(context.Context, uint, time.Time) error) *APIKey_UpdateLastUsed_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewAPIKey creates a new instance of APIKey. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewAPIKey(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *APIKey {
+	mock := &APIKey{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
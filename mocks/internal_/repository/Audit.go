@@ -0,0 +1,208 @@
+// Code generated by mockery v2.44.2. DO NOT EDIT.
+
+package repository
+
+import (
+	context "context"
+	entity "crud-customer/internal/entity"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Audit is an autogenerated mock type for the Audit type
+type Audit struct {
+	mock.Mock
+}
+
+type Audit_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *Audit) EXPECT() *Audit_Expecter {
+	return &Audit_Expecter{mock: &_m.Mock}
+}
+
+// GetAuditEntriesByCustomerID provides a mock function with given fields: ctx, customerID
+func (_m *Audit) GetAuditEntriesByCustomerID(ctx context.Context, customerID uint) ([]*entity.AuditEntry, error) {
+	ret := _m.Called(ctx, customerID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAuditEntriesByCustomerID")
+	}
+
+	var r0 []*entity.AuditEntry
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) ([]*entity.AuditEntry, error)); ok {
+		return rf(ctx, customerID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint) []*entity.AuditEntry); ok {
+		r0 = rf(ctx, customerID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*entity.AuditEntry)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(ctx, customerID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Audit_GetAuditEntriesByCustomerID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetAuditEntriesByCustomerID'
+type Audit_GetAuditEntriesByCustomerID_Call struct {
+	*mock.Call
+}
+
+// GetAuditEntriesByCustomerID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - customerID uint
+func (_e *Audit_Expecter) GetAuditEntriesByCustomerID(ctx interface{}, customerID interface{}) *Audit_GetAuditEntriesByCustomerID_Call {
+	return &Audit_GetAuditEntriesByCustomerID_Call{Call: _e.mock.On("GetAuditEntriesByCustomerID", ctx, customerID)}
+}
+
+func (_c *Audit_GetAuditEntriesByCustomerID_Call) Run(run func(ctx context.Context, customerID uint)) *Audit_GetAuditEntriesByCustomerID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uint))
+	})
+	return _c
+}
+
+func (_c *Audit_GetAuditEntriesByCustomerID_Call) Return(_a0 []*entity.AuditEntry, _a1 error) *Audit_GetAuditEntriesByCustomerID_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Audit_GetAuditEntriesByCustomerID_Call) RunAndReturn(run func(context.Context, uint) ([]*entity.AuditEntry, error)) *Audit_GetAuditEntriesByCustomerID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ReencryptAuditEntries provides a mock function with given fields: ctx, afterID, limit
+func (_m *Audit) ReencryptAuditEntries(ctx context.Context, afterID uint, limit int) (uint, int, error) {
+	ret := _m.Called(ctx, afterID, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReencryptAuditEntries")
+	}
+
+	var r0 uint
+	var r1 int
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, int) (uint, int, error)); ok {
+		return rf(ctx, afterID, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint, int) uint); ok {
+		r0 = rf(ctx, afterID, limit)
+	} else {
+		r0 = ret.Get(0).(uint)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint, int) int); ok {
+		r1 = rf(ctx, afterID, limit)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, uint, int) error); ok {
+		r2 = rf(ctx, afterID, limit)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// Audit_ReencryptAuditEntries_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ReencryptAuditEntries'
+type Audit_ReencryptAuditEntries_Call struct {
+	*mock.Call
+}
+
+// ReencryptAuditEntries is a helper method to define mock.On call
+//   - ctx context.Context
+//   - afterID uint
+//   - limit int
+func (_e *Audit_Expecter) ReencryptAuditEntries(ctx interface{}, afterID interface{}, limit interface{}) *Audit_ReencryptAuditEntries_Call {
+	return &Audit_ReencryptAuditEntries_Call{Call: _e.mock.On("ReencryptAuditEntries", ctx, afterID, limit)}
+}
+
+func (_c *Audit_ReencryptAuditEntries_Call) Run(run func(ctx context.Context, afterID uint, limit int)) *Audit_ReencryptAuditEntries_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uint), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *Audit_ReencryptAuditEntries_Call) Return(_a0 uint, _a1 int, _a2 error) *Audit_ReencryptAuditEntries_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *Audit_ReencryptAuditEntries_Call) RunAndReturn(run func(context.Context, uint, int) (uint, int, error)) *Audit_ReencryptAuditEntries_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ScanAuditEntries provides a mock function with given fields: ctx, fn
+func (_m *Audit) ScanAuditEntries(ctx context.Context, fn func(*entity.AuditEntry) error) error {
+	ret := _m.Called(ctx, fn)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ScanAuditEntries")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, func(*entity.AuditEntry) error) error); ok {
+		r0 = rf(ctx, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Audit_ScanAuditEntries_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ScanAuditEntries'
+type Audit_ScanAuditEntries_Call struct {
+	*mock.Call
+}
+
+// ScanAuditEntries is a helper method to define mock.On call
+//   - ctx context.Context
+//   - fn func(*entity.AuditEntry) error
+func (_e *Audit_Expecter) ScanAuditEntries(ctx interface{}, fn interface{}) *Audit_ScanAuditEntries_Call {
+	return &Audit_ScanAuditEntries_Call{Call: _e.mock.On("ScanAuditEntries", ctx, fn)}
+}
+
+func (_c *Audit_ScanAuditEntries_Call) Run(run func(ctx context.Context, fn func(*entity.AuditEntry) error)) *Audit_ScanAuditEntries_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(func(*entity.AuditEntry) error))
+	})
+	return _c
+}
+
+func (_c *Audit_ScanAuditEntries_Call) Return(_a0 error) *Audit_ScanAuditEntries_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Audit_ScanAuditEntries_Call) RunAndReturn(run func(context.Context, func(*entity.AuditEntry) error) error) *Audit_ScanAuditEntries_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewAudit creates a new instance of Audit. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewAudit(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Audit {
+	mock := &Audit{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -128,6 +128,54 @@ func (_c *Customer_DeleteCustomer_Call) RunAndReturn(run func(context.Context, u
 	return _c
 }
 
+// ExportCustomers provides a mock function with given fields: ctx, filter, fn
+func (_m *Customer) ExportCustomers(ctx context.Context, filter entity.CustomerFilter, fn func(*entity.Customer) error) error {
+	ret := _m.Called(ctx, filter, fn)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExportCustomers")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, entity.CustomerFilter, func(*entity.Customer) error) error); ok {
+		r0 = rf(ctx, filter, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Customer_ExportCustomers_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ExportCustomers'
+type Customer_ExportCustomers_Call struct {
+	*mock.Call
+}
+
+// ExportCustomers is a helper method to define mock.On call
+//   - ctx context.Context
+//   - filter entity.CustomerFilter
+//   - fn func(*entity.Customer) error
+func (_e *Customer_Expecter) ExportCustomers(ctx interface{}, filter interface{}, fn interface{}) *Customer_ExportCustomers_Call {
+	return &Customer_ExportCustomers_Call{Call: _e.mock.On("ExportCustomers", ctx, filter, fn)}
+}
+
+func (_c *Customer_ExportCustomers_Call) Run(run func(ctx context.Context, filter entity.CustomerFilter, fn func(*entity.Customer) error)) *Customer_ExportCustomers_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(entity.CustomerFilter), args[2].(func(*entity.Customer) error))
+	})
+	return _c
+}
+
+func (_c *Customer_ExportCustomers_Call) Return(_a0 error) *Customer_ExportCustomers_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Customer_ExportCustomers_Call) RunAndReturn(run func(context.Context, entity.CustomerFilter, func(*entity.Customer) error) error) *Customer_ExportCustomers_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetAllCustomer provides a mock function with given fields: ctx
 func (_m *Customer) GetAllCustomer(ctx context.Context) ([]*entity.Customer, error) {
 	ret := _m.Called(ctx)
@@ -245,6 +293,189 @@ func (_c *Customer_GetCustomerByID_Call) RunAndReturn(run func(context.Context,
 	return _c
 }
 
+// GetCustomersByIDs provides a mock function with given fields: ctx, ids
+func (_m *Customer) GetCustomersByIDs(ctx context.Context, ids []uint) ([]*entity.Customer, error) {
+	ret := _m.Called(ctx, ids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCustomersByIDs")
+	}
+
+	var r0 []*entity.Customer
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []uint) ([]*entity.Customer, error)); ok {
+		return rf(ctx, ids)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []uint) []*entity.Customer); ok {
+		r0 = rf(ctx, ids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*entity.Customer)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []uint) error); ok {
+		r1 = rf(ctx, ids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Customer_GetCustomersByIDs_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetCustomersByIDs'
+type Customer_GetCustomersByIDs_Call struct {
+	*mock.Call
+}
+
+// GetCustomersByIDs is a helper method to define mock.On call
+//   - ctx context.Context
+//   - ids []uint
+func (_e *Customer_Expecter) GetCustomersByIDs(ctx interface{}, ids interface{}) *Customer_GetCustomersByIDs_Call {
+	return &Customer_GetCustomersByIDs_Call{Call: _e.mock.On("GetCustomersByIDs", ctx, ids)}
+}
+
+func (_c *Customer_GetCustomersByIDs_Call) Run(run func(ctx context.Context, ids []uint)) *Customer_GetCustomersByIDs_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]uint))
+	})
+	return _c
+}
+
+func (_c *Customer_GetCustomersByIDs_Call) Return(_a0 []*entity.Customer, _a1 error) *Customer_GetCustomersByIDs_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Customer_GetCustomersByIDs_Call) RunAndReturn(run func(context.Context, []uint) ([]*entity.Customer, error)) *Customer_GetCustomersByIDs_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ReencryptCustomers provides a mock function with given fields: ctx, afterID, limit
+func (_m *Customer) ReencryptCustomers(ctx context.Context, afterID uint, limit int) (uint, int, error) {
+	ret := _m.Called(ctx, afterID, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReencryptCustomers")
+	}
+
+	var r0 uint
+	var r1 int
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, int) (uint, int, error)); ok {
+		return rf(ctx, afterID, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint, int) uint); ok {
+		r0 = rf(ctx, afterID, limit)
+	} else {
+		r0 = ret.Get(0).(uint)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint, int) int); ok {
+		r1 = rf(ctx, afterID, limit)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, uint, int) error); ok {
+		r2 = rf(ctx, afterID, limit)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// Customer_ReencryptCustomers_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ReencryptCustomers'
+type Customer_ReencryptCustomers_Call struct {
+	*mock.Call
+}
+
+// ReencryptCustomers is a helper method to define mock.On call
+//   - ctx context.Context
+//   - afterID uint
+//   - limit int
+func (_e *Customer_Expecter) ReencryptCustomers(ctx interface{}, afterID interface{}, limit interface{}) *Customer_ReencryptCustomers_Call {
+	return &Customer_ReencryptCustomers_Call{Call: _e.mock.On("ReencryptCustomers", ctx, afterID, limit)}
+}
+
+func (_c *Customer_ReencryptCustomers_Call) Run(run func(ctx context.Context, afterID uint, limit int)) *Customer_ReencryptCustomers_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uint), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *Customer_ReencryptCustomers_Call) Return(_a0 uint, _a1 int, _a2 error) *Customer_ReencryptCustomers_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *Customer_ReencryptCustomers_Call) RunAndReturn(run func(context.Context, uint, int) (uint, int, error)) *Customer_ReencryptCustomers_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RestoreCustomer provides a mock function with given fields: ctx, id
+func (_m *Customer) RestoreCustomer(ctx context.Context, id uint) (*entity.Customer, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RestoreCustomer")
+	}
+
+	var r0 *entity.Customer
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) (*entity.Customer, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint) *entity.Customer); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.Customer)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Customer_RestoreCustomer_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RestoreCustomer'
+type Customer_RestoreCustomer_Call struct {
+	*mock.Call
+}
+
+// RestoreCustomer is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id uint
+func (_e *Customer_Expecter) RestoreCustomer(ctx interface{}, id interface{}) *Customer_RestoreCustomer_Call {
+	return &Customer_RestoreCustomer_Call{Call: _e.mock.On("RestoreCustomer", ctx, id)}
+}
+
+func (_c *Customer_RestoreCustomer_Call) Run(run func(ctx context.Context, id uint)) *Customer_RestoreCustomer_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uint))
+	})
+	return _c
+}
+
+func (_c *Customer_RestoreCustomer_Call) Return(_a0 *entity.Customer, _a1 error) *Customer_RestoreCustomer_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Customer_RestoreCustomer_Call) RunAndReturn(run func(context.Context, uint) (*entity.Customer, error)) *Customer_RestoreCustomer_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // UpdateCustomer provides a mock function with given fields: ctx, id, customer
 func (_m *Customer) UpdateCustomer(ctx context.Context, id uint, customer *entity.Customer) (*entity.Customer, error) {
 	ret := _m.Called(ctx, id, customer)
@@ -305,6 +536,72 @@ func (_c *Customer_UpdateCustomer_Call) RunAndReturn(run func(context.Context, u
 	return _c
 }
 
+// UpsertCustomerByExternalID provides a mock function with given fields: ctx, customer
+func (_m *Customer) UpsertCustomerByExternalID(ctx context.Context, customer *entity.Customer) (*entity.Customer, bool, error) {
+	ret := _m.Called(ctx, customer)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpsertCustomerByExternalID")
+	}
+
+	var r0 *entity.Customer
+	var r1 bool
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.Customer) (*entity.Customer, bool, error)); ok {
+		return rf(ctx, customer)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.Customer) *entity.Customer); ok {
+		r0 = rf(ctx, customer)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.Customer)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *entity.Customer) bool); ok {
+		r1 = rf(ctx, customer)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, *entity.Customer) error); ok {
+		r2 = rf(ctx, customer)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// Customer_UpsertCustomerByExternalID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpsertCustomerByExternalID'
+type Customer_UpsertCustomerByExternalID_Call struct {
+	*mock.Call
+}
+
+// UpsertCustomerByExternalID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - customer *entity.Customer
+func (_e *Customer_Expecter) UpsertCustomerByExternalID(ctx interface{}, customer interface{}) *Customer_UpsertCustomerByExternalID_Call {
+	return &Customer_UpsertCustomerByExternalID_Call{Call: _e.mock.On("UpsertCustomerByExternalID", ctx, customer)}
+}
+
+func (_c *Customer_UpsertCustomerByExternalID_Call) Run(run func(ctx context.Context, customer *entity.Customer)) *Customer_UpsertCustomerByExternalID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*entity.Customer))
+	})
+	return _c
+}
+
+func (_c *Customer_UpsertCustomerByExternalID_Call) Return(_a0 *entity.Customer, _a1 bool, _a2 error) *Customer_UpsertCustomerByExternalID_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *Customer_UpsertCustomerByExternalID_Call) RunAndReturn(run func(context.Context, *entity.Customer) (*entity.Customer, bool, error)) *Customer_UpsertCustomerByExternalID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // NewCustomer creates a new instance of Customer. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewCustomer(t interface {
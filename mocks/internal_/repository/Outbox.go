@@ -0,0 +1,307 @@
+// Code generated by mockery v2.44.2. DO NOT EDIT.
+
+package repository
+
+import (
+	context "context"
+	entity "crud-customer/internal/entity"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// Outbox is an autogenerated mock type for the Outbox type
+type Outbox struct {
+	mock.Mock
+}
+
+type Outbox_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *Outbox) EXPECT() *Outbox_Expecter {
+	return &Outbox_Expecter{mock: &_m.Mock}
+}
+
+// GetPendingEvents provides a mock function with given fields: ctx, limit
+func (_m *Outbox) GetPendingEvents(ctx context.Context, limit int) ([]*entity.OutboxEvent, error) {
+	ret := _m.Called(ctx, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPendingEvents")
+	}
+
+	var r0 []*entity.OutboxEvent
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]*entity.OutboxEvent, error)); ok {
+		return rf(ctx, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []*entity.OutboxEvent); ok {
+		r0 = rf(ctx, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*entity.OutboxEvent)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Outbox_GetPendingEvents_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetPendingEvents'
+type Outbox_GetPendingEvents_Call struct {
+	*mock.Call
+}
+
+// GetPendingEvents is a helper method to define mock.On call
+//   - ctx context.Context
+//   - limit int
+func (_e *Outbox_Expecter) GetPendingEvents(ctx interface{}, limit interface{}) *Outbox_GetPendingEvents_Call {
+	return &Outbox_GetPendingEvents_Call{Call: _e.mock.On("GetPendingEvents", ctx, limit)}
+}
+
+func (_c *Outbox_GetPendingEvents_Call) Run(run func(ctx context.Context, limit int)) *Outbox_GetPendingEvents_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *Outbox_GetPendingEvents_Call) Return(_a0 []*entity.OutboxEvent, _a1 error) *Outbox_GetPendingEvents_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Outbox_GetPendingEvents_Call) RunAndReturn(run func(context.Context, int) ([]*entity.OutboxEvent, error)) *Outbox_GetPendingEvents_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// MarkDeadLettered provides a mock function with given fields: ctx, id, reason
+func (_m *Outbox) MarkDeadLettered(ctx context.Context, id uint, reason string) error {
+	ret := _m.Called(ctx, id, reason)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MarkDeadLettered")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, string) error); ok {
+		r0 = rf(ctx, id, reason)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Outbox_MarkDeadLettered_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'MarkDeadLettered'
+type Outbox_MarkDeadLettered_Call struct {
+	*mock.Call
+}
+
+// MarkDeadLettered is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id uint
+//   - reason string
+func (_e *Outbox_Expecter) MarkDeadLettered(ctx interface{}, id interface{}, reason interface{}) *Outbox_MarkDeadLettered_Call {
+	return &Outbox_MarkDeadLettered_Call{Call: _e.mock.On("MarkDeadLettered", ctx, id, reason)}
+}
+
+func (_c *Outbox_MarkDeadLettered_Call) Run(run func(ctx context.Context, id uint, reason string)) *Outbox_MarkDeadLettered_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uint), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *Outbox_MarkDeadLettered_Call) Return(_a0 error) *Outbox_MarkDeadLettered_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Outbox_MarkDeadLettered_Call) RunAndReturn(run func(context.Context, uint, string) error) *Outbox_MarkDeadLettered_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// MarkDispatched provides a mock function with given fields: ctx, id
+func (_m *Outbox) MarkDispatched(ctx context.Context, id uint) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MarkDispatched")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Outbox_MarkDispatched_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'MarkDispatched'
+type Outbox_MarkDispatched_Call struct {
+	*mock.Call
+}
+
+// MarkDispatched is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id uint
+func (_e *Outbox_Expecter) MarkDispatched(ctx interface{}, id interface{}) *Outbox_MarkDispatched_Call {
+	return &Outbox_MarkDispatched_Call{Call: _e.mock.On("MarkDispatched", ctx, id)}
+}
+
+func (_c *Outbox_MarkDispatched_Call) Run(run func(ctx context.Context, id uint)) *Outbox_MarkDispatched_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uint))
+	})
+	return _c
+}
+
+func (_c *Outbox_MarkDispatched_Call) Return(_a0 error) *Outbox_MarkDispatched_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Outbox_MarkDispatched_Call) RunAndReturn(run func(context.Context, uint) error) *Outbox_MarkDispatched_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// MarkFailed provides a mock function with given fields: ctx, id, reason, retryAt
+func (_m *Outbox) MarkFailed(ctx context.Context, id uint, reason string, retryAt time.Time) error {
+	ret := _m.Called(ctx, id, reason, retryAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MarkFailed")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, string, time.Time) error); ok {
+		r0 = rf(ctx, id, reason, retryAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Outbox_MarkFailed_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'MarkFailed'
+type Outbox_MarkFailed_Call struct {
+	*mock.Call
+}
+
+// MarkFailed is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id uint
+//   - reason string
+//   - retryAt time.Time
+func (_e *Outbox_Expecter) MarkFailed(ctx interface{}, id interface{}, reason interface{}, retryAt interface{}) *Outbox_MarkFailed_Call {
+	return &Outbox_MarkFailed_Call{Call: _e.mock.On("MarkFailed", ctx, id, reason, retryAt)}
+}
+
+func (_c *Outbox_MarkFailed_Call) Run(run func(ctx context.Context, id uint, reason string, retryAt time.Time)) *Outbox_MarkFailed_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uint), args[2].(string), args[3].(time.Time))
+	})
+	return _c
+}
+
+func (_c *Outbox_MarkFailed_Call) Return(_a0 error) *Outbox_MarkFailed_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Outbox_MarkFailed_Call) RunAndReturn(run func(context.Context, uint, string, time.Time) error) *Outbox_MarkFailed_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ReencryptEvents provides a mock function with given fields: ctx, afterID, limit
+func (_m *Outbox) ReencryptEvents(ctx context.Context, afterID uint, limit int) (uint, int, error) {
+	ret := _m.Called(ctx, afterID, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReencryptEvents")
+	}
+
+	var r0 uint
+	var r1 int
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, int) (uint, int, error)); ok {
+		return rf(ctx, afterID, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint, int) uint); ok {
+		r0 = rf(ctx, afterID, limit)
+	} else {
+		r0 = ret.Get(0).(uint)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint, int) int); ok {
+		r1 = rf(ctx, afterID, limit)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, uint, int) error); ok {
+		r2 = rf(ctx, afterID, limit)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// Outbox_ReencryptEvents_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ReencryptEvents'
+type Outbox_ReencryptEvents_Call struct {
+	*mock.Call
+}
+
+// ReencryptEvents is a helper method to define mock.On call
+//   - ctx context.Context
+//   - afterID uint
+//   - limit int
+func (_e *Outbox_Expecter) ReencryptEvents(ctx interface{}, afterID interface{}, limit interface{}) *Outbox_ReencryptEvents_Call {
+	return &Outbox_ReencryptEvents_Call{Call: _e.mock.On("ReencryptEvents", ctx, afterID, limit)}
+}
+
+func (_c *Outbox_ReencryptEvents_Call) Run(run func(ctx context.Context, afterID uint, limit int)) *Outbox_ReencryptEvents_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uint), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *Outbox_ReencryptEvents_Call) Return(_a0 uint, _a1 int, _a2 error) *Outbox_ReencryptEvents_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *Outbox_ReencryptEvents_Call) RunAndReturn(run func(context.Context, uint, int) (uint, int, error)) *Outbox_ReencryptEvents_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewOutbox creates a new instance of Outbox. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewOutbox(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Outbox {
+	mock := &Outbox{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,272 @@
+// Code generated by mockery v2.44.2. DO NOT EDIT.
+
+package service
+
+import (
+	context "context"
+	entity "crud-customer/internal/entity"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// APIKey is an autogenerated mock type for the APIKey type
+type APIKey struct {
+	mock.Mock
+}
+
+type APIKey_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *APIKey) EXPECT() *APIKey_Expecter {
+	return &APIKey_Expecter{mock: &_m.Mock}
+}
+
+// Authenticate provides a mock function with given fields: ctx, key
+func (_m *APIKey) Authenticate(ctx context.Context, key string) (*entity.APIKey, error) {
+	ret := _m.Called(ctx, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Authenticate")
+	}
+
+	var r0 *entity.APIKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*entity.APIKey, error)); ok {
+		return rf(ctx, key)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *entity.APIKey); ok {
+		r0 = rf(ctx, key)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.APIKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, key)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// APIKey_Authenticate_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Authenticate'
+type APIKey_Authenticate_Call struct {
+	*mock.Call
+}
+
+// Authenticate is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key string
+func (_e *APIKey_Expecter) Authenticate(ctx interface{}, key interface{}) *APIKey_Authenticate_Call {
+	return &APIKey_Authenticate_Call{Call: _e.mock.On("Authenticate", ctx, key)}
+}
+
+func (_c *APIKey_Authenticate_Call) Run(run func(ctx context.Context, key string)) *APIKey_Authenticate_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *APIKey_Authenticate_Call) Return(_a0 *entity.APIKey, _a1 error) *APIKey_Authenticate_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *APIKey_Authenticate_Call) RunAndReturn(run func(context.Context, string) (*entity.APIKey, error)) *APIKey_Authenticate_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateAPIKey provides a mock function with given fields: ctx, name, tenantID, scopes, expiresAt
+func (_m *APIKey) CreateAPIKey(ctx context.Context, name string, tenantID string, scopes []string, expiresAt *time.Time) (*entity.APIKey, string, error) {
+	ret := _m.Called(ctx, name, tenantID, scopes, expiresAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateAPIKey")
+	}
+
+	var r0 *entity.APIKey
+	var r1 string
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, []string, *time.Time) (*entity.APIKey, string, error)); ok {
+		return rf(ctx, name, tenantID, scopes, expiresAt)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, []string, *time.Time) *entity.APIKey); ok {
+		r0 = rf(ctx, name, tenantID, scopes, expiresAt)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.APIKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, []string, *time.Time) string); ok {
+		r1 = rf(ctx, name, tenantID, scopes, expiresAt)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, string, []string, *time.Time) error); ok {
+		r2 = rf(ctx, name, tenantID, scopes, expiresAt)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// APIKey_CreateAPIKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateAPIKey'
+type APIKey_CreateAPIKey_Call struct {
+	*mock.Call
+}
+
+// CreateAPIKey is a helper method to define mock.On call
+//   - ctx context.Context
+//   - name string
+//   - tenantID string
+//   - scopes []string
+//   - expiresAt *time.Time
+func (_e *APIKey_Expecter) CreateAPIKey(ctx interface{}, name interface{}, tenantID interface{}, scopes interface{}, expiresAt interface{}) *APIKey_CreateAPIKey_Call {
+	return &APIKey_CreateAPIKey_Call{Call: _e.mock.On("CreateAPIKey", ctx, name, tenantID, scopes, expiresAt)}
+}
+
+func (_c *APIKey_CreateAPIKey_Call) Run(run func(ctx context.Context, name string, tenantID string, scopes []string, expiresAt *time.Time)) *APIKey_CreateAPIKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].([]string), args[4].(*time.Time))
+	})
+	return _c
+}
+
+func (_c *APIKey_CreateAPIKey_Call) Return(_a0 *entity.APIKey, _a1 string, _a2 error) *APIKey_CreateAPIKey_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *APIKey_CreateAPIKey_Call) RunAndReturn(run func(context.Context, string, string, []string, *time.Time) (*entity.APIKey, string, error)) *APIKey_CreateAPIKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetAllAPIKey provides a mock function with given fields: ctx
+func (_m *APIKey) GetAllAPIKey(ctx context.Context) ([]*entity.APIKey, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAllAPIKey")
+	}
+
+	var r0 []*entity.APIKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]*entity.APIKey, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []*entity.APIKey); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*entity.APIKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// APIKey_GetAllAPIKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetAllAPIKey'
+type APIKey_GetAllAPIKey_Call struct {
+	*mock.Call
+}
+
+// GetAllAPIKey is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *APIKey_Expecter) GetAllAPIKey(ctx interface{}) *APIKey_GetAllAPIKey_Call {
+	return &APIKey_GetAllAPIKey_Call{Call: _e.mock.On("GetAllAPIKey", ctx)}
+}
+
+func (_c *APIKey_GetAllAPIKey_Call) Run(run func(ctx context.Context)) *APIKey_GetAllAPIKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *APIKey_GetAllAPIKey_Call) Return(_a0 []*entity.APIKey, _a1 error) *APIKey_GetAllAPIKey_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *APIKey_GetAllAPIKey_Call) RunAndReturn(run func(context.Context) ([]*entity.APIKey, error)) *APIKey_GetAllAPIKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RevokeAPIKey provides a mock function with given fields: ctx, id
+func (_m *APIKey) RevokeAPIKey(ctx context.Context, id uint) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeAPIKey")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// APIKey_RevokeAPIKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RevokeAPIKey'
+type APIKey_RevokeAPIKey_Call struct {
+	*mock.Call
+}
+
+// RevokeAPIKey is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id uint
+func (_e *APIKey_Expecter) RevokeAPIKey(ctx interface{}, id interface{}) *APIKey_RevokeAPIKey_Call {
+	return &APIKey_RevokeAPIKey_Call{Call: _e.mock.On("RevokeAPIKey", ctx, id)}
+}
+
+func (_c *APIKey_RevokeAPIKey_Call) Run(run func(ctx context.Context, id uint)) *APIKey_RevokeAPIKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uint))
+	})
+	return _c
+}
+
+func (_c *APIKey_RevokeAPIKey_Call) Return(_a0 error) *APIKey_RevokeAPIKey_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *APIKey_RevokeAPIKey_Call) RunAndReturn(run func(context.Context, uint) error) *APIKey_RevokeAPIKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewAPIKey creates a new instance of APIKey. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewAPIKey(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *APIKey {
+	mock := &APIKey{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,152 @@
+// Code generated by mockery v2.44.2. DO NOT EDIT.
+
+package service
+
+import (
+	context "context"
+	entity "crud-customer/internal/entity"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Audit is an autogenerated mock type for the Audit type
+type Audit struct {
+	mock.Mock
+}
+
+type Audit_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *Audit) EXPECT() *Audit_Expecter {
+	return &Audit_Expecter{mock: &_m.Mock}
+}
+
+// GetAuditEntriesByCustomerID provides a mock function with given fields: ctx, customerID
+func (_m *Audit) GetAuditEntriesByCustomerID(ctx context.Context, customerID uint) ([]*entity.AuditEntry, error) {
+	ret := _m.Called(ctx, customerID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAuditEntriesByCustomerID")
+	}
+
+	var r0 []*entity.AuditEntry
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) ([]*entity.AuditEntry, error)); ok {
+		return rf(ctx, customerID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint) []*entity.AuditEntry); ok {
+		r0 = rf(ctx, customerID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*entity.AuditEntry)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(ctx, customerID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Audit_GetAuditEntriesByCustomerID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetAuditEntriesByCustomerID'
+type Audit_GetAuditEntriesByCustomerID_Call struct {
+	*mock.Call
+}
+
+// GetAuditEntriesByCustomerID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - customerID uint
+func (_e *Audit_Expecter) GetAuditEntriesByCustomerID(ctx interface{}, customerID interface{}) *Audit_GetAuditEntriesByCustomerID_Call {
+	return &Audit_GetAuditEntriesByCustomerID_Call{Call: _e.mock.On("GetAuditEntriesByCustomerID", ctx, customerID)}
+}
+
+func (_c *Audit_GetAuditEntriesByCustomerID_Call) Run(run func(ctx context.Context, customerID uint)) *Audit_GetAuditEntriesByCustomerID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uint))
+	})
+	return _c
+}
+
+func (_c *Audit_GetAuditEntriesByCustomerID_Call) Return(_a0 []*entity.AuditEntry, _a1 error) *Audit_GetAuditEntriesByCustomerID_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Audit_GetAuditEntriesByCustomerID_Call) RunAndReturn(run func(context.Context, uint) ([]*entity.AuditEntry, error)) *Audit_GetAuditEntriesByCustomerID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// VerifyAuditLog provides a mock function with given fields: ctx
+func (_m *Audit) VerifyAuditLog(ctx context.Context) (int, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for VerifyAuditLog")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (int, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) int); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Audit_VerifyAuditLog_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'VerifyAuditLog'
+type Audit_VerifyAuditLog_Call struct {
+	*mock.Call
+}
+
+// VerifyAuditLog is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *Audit_Expecter) VerifyAuditLog(ctx interface{}) *Audit_VerifyAuditLog_Call {
+	return &Audit_VerifyAuditLog_Call{Call: _e.mock.On("VerifyAuditLog", ctx)}
+}
+
+func (_c *Audit_VerifyAuditLog_Call) Run(run func(ctx context.Context)) *Audit_VerifyAuditLog_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *Audit_VerifyAuditLog_Call) Return(_a0 int, _a1 error) *Audit_VerifyAuditLog_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Audit_VerifyAuditLog_Call) RunAndReturn(run func(context.Context) (int, error)) *Audit_VerifyAuditLog_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewAudit creates a new instance of Audit. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewAudit(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Audit {
+	mock := &Audit{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
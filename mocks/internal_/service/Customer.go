@@ -22,6 +22,74 @@ func (_m *Customer) EXPECT() *Customer_Expecter {
 	return &Customer_Expecter{mock: &_m.Mock}
 }
 
+// BatchGetCustomers provides a mock function with given fields: ctx, ids
+func (_m *Customer) BatchGetCustomers(ctx context.Context, ids []uint) ([]*entity.Customer, []uint, error) {
+	ret := _m.Called(ctx, ids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for BatchGetCustomers")
+	}
+
+	var r0 []*entity.Customer
+	var r1 []uint
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, []uint) ([]*entity.Customer, []uint, error)); ok {
+		return rf(ctx, ids)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []uint) []*entity.Customer); ok {
+		r0 = rf(ctx, ids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*entity.Customer)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []uint) []uint); ok {
+		r1 = rf(ctx, ids)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([]uint)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, []uint) error); ok {
+		r2 = rf(ctx, ids)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// Customer_BatchGetCustomers_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'BatchGetCustomers'
+type Customer_BatchGetCustomers_Call struct {
+	*mock.Call
+}
+
+// BatchGetCustomers is a helper method to define mock.On call
+//   - ctx context.Context
+//   - ids []uint
+func (_e *Customer_Expecter) BatchGetCustomers(ctx interface{}, ids interface{}) *Customer_BatchGetCustomers_Call {
+	return &Customer_BatchGetCustomers_Call{Call: _e.mock.On("BatchGetCustomers", ctx, ids)}
+}
+
+func (_c *Customer_BatchGetCustomers_Call) Run(run func(ctx context.Context, ids []uint)) *Customer_BatchGetCustomers_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]uint))
+	})
+	return _c
+}
+
+func (_c *Customer_BatchGetCustomers_Call) Return(_a0 []*entity.Customer, _a1 []uint, _a2 error) *Customer_BatchGetCustomers_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *Customer_BatchGetCustomers_Call) RunAndReturn(run func(context.Context, []uint) ([]*entity.Customer, []uint, error)) *Customer_BatchGetCustomers_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // CreateCustomer provides a mock function with given fields: ctx, name, age
 func (_m *Customer) CreateCustomer(ctx context.Context, name string, age uint) (*entity.Customer, error) {
 	ret := _m.Called(ctx, name, age)
@@ -129,6 +197,54 @@ func (_c *Customer_DeleteCustomer_Call) RunAndReturn(run func(context.Context, u
 	return _c
 }
 
+// ExportCustomers provides a mock function with given fields: ctx, filter, fn
+func (_m *Customer) ExportCustomers(ctx context.Context, filter entity.CustomerFilter, fn func(*entity.Customer) error) error {
+	ret := _m.Called(ctx, filter, fn)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExportCustomers")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, entity.CustomerFilter, func(*entity.Customer) error) error); ok {
+		r0 = rf(ctx, filter, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Customer_ExportCustomers_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ExportCustomers'
+type Customer_ExportCustomers_Call struct {
+	*mock.Call
+}
+
+// ExportCustomers is a helper method to define mock.On call
+//   - ctx context.Context
+//   - filter entity.CustomerFilter
+//   - fn func(*entity.Customer) error
+func (_e *Customer_Expecter) ExportCustomers(ctx interface{}, filter interface{}, fn interface{}) *Customer_ExportCustomers_Call {
+	return &Customer_ExportCustomers_Call{Call: _e.mock.On("ExportCustomers", ctx, filter, fn)}
+}
+
+func (_c *Customer_ExportCustomers_Call) Run(run func(ctx context.Context, filter entity.CustomerFilter, fn func(*entity.Customer) error)) *Customer_ExportCustomers_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(entity.CustomerFilter), args[2].(func(*entity.Customer) error))
+	})
+	return _c
+}
+
+func (_c *Customer_ExportCustomers_Call) Return(_a0 error) *Customer_ExportCustomers_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Customer_ExportCustomers_Call) RunAndReturn(run func(context.Context, entity.CustomerFilter, func(*entity.Customer) error) error) *Customer_ExportCustomers_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetAllCustomer provides a mock function with given fields: ctx
 func (_m *Customer) GetAllCustomer(ctx context.Context) ([]*entity.Customer, error) {
 	ret := _m.Called(ctx)
@@ -246,6 +362,65 @@ func (_c *Customer_GetCustomerByID_Call) RunAndReturn(run func(context.Context,
 	return _c
 }
 
+// RestoreCustomer provides a mock function with given fields: ctx, id
+func (_m *Customer) RestoreCustomer(ctx context.Context, id uint) (*entity.Customer, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RestoreCustomer")
+	}
+
+	var r0 *entity.Customer
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) (*entity.Customer, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint) *entity.Customer); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.Customer)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Customer_RestoreCustomer_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RestoreCustomer'
+type Customer_RestoreCustomer_Call struct {
+	*mock.Call
+}
+
+// RestoreCustomer is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id uint
+func (_e *Customer_Expecter) RestoreCustomer(ctx interface{}, id interface{}) *Customer_RestoreCustomer_Call {
+	return &Customer_RestoreCustomer_Call{Call: _e.mock.On("RestoreCustomer", ctx, id)}
+}
+
+func (_c *Customer_RestoreCustomer_Call) Run(run func(ctx context.Context, id uint)) *Customer_RestoreCustomer_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uint))
+	})
+	return _c
+}
+
+func (_c *Customer_RestoreCustomer_Call) Return(_a0 *entity.Customer, _a1 error) *Customer_RestoreCustomer_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Customer_RestoreCustomer_Call) RunAndReturn(run func(context.Context, uint) (*entity.Customer, error)) *Customer_RestoreCustomer_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // UpdateCustomer provides a mock function with given fields: ctx, id, customer
 func (_m *Customer) UpdateCustomer(ctx context.Context, id uint, customer *entity.Customer) (*entity.Customer, error) {
 	ret := _m.Called(ctx, id, customer)
@@ -306,6 +481,75 @@ func (_c *Customer_UpdateCustomer_Call) RunAndReturn(run func(context.Context, u
 	return _c
 }
 
+// UpsertCustomerByExternalID provides a mock function with given fields: ctx, source, externalID, name, age
+func (_m *Customer) UpsertCustomerByExternalID(ctx context.Context, source string, externalID string, name string, age uint) (*entity.Customer, bool, error) {
+	ret := _m.Called(ctx, source, externalID, name, age)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpsertCustomerByExternalID")
+	}
+
+	var r0 *entity.Customer
+	var r1 bool
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, uint) (*entity.Customer, bool, error)); ok {
+		return rf(ctx, source, externalID, name, age)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, uint) *entity.Customer); ok {
+		r0 = rf(ctx, source, externalID, name, age)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.Customer)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string, uint) bool); ok {
+		r1 = rf(ctx, source, externalID, name, age)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, string, string, uint) error); ok {
+		r2 = rf(ctx, source, externalID, name, age)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// Customer_UpsertCustomerByExternalID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpsertCustomerByExternalID'
+type Customer_UpsertCustomerByExternalID_Call struct {
+	*mock.Call
+}
+
+// UpsertCustomerByExternalID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - source string
+//   - externalID string
+//   - name string
+//   - age uint
+func (_e *Customer_Expecter) UpsertCustomerByExternalID(ctx interface{}, source interface{}, externalID interface{}, name interface{}, age interface{}) *Customer_UpsertCustomerByExternalID_Call {
+	return &Customer_UpsertCustomerByExternalID_Call{Call: _e.mock.On("UpsertCustomerByExternalID", ctx, source, externalID, name, age)}
+}
+
+func (_c *Customer_UpsertCustomerByExternalID_Call) Run(run func(ctx context.Context, source string, externalID string, name string, age uint)) *Customer_UpsertCustomerByExternalID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(string), args[4].(uint))
+	})
+	return _c
+}
+
+func (_c *Customer_UpsertCustomerByExternalID_Call) Return(_a0 *entity.Customer, _a1 bool, _a2 error) *Customer_UpsertCustomerByExternalID_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *Customer_UpsertCustomerByExternalID_Call) RunAndReturn(run func(context.Context, string, string, string, uint) (*entity.Customer, bool, error)) *Customer_UpsertCustomerByExternalID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // NewCustomer creates a new instance of Customer. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewCustomer(t interface {
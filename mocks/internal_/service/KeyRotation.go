@@ -0,0 +1,84 @@
+// Code generated by mockery v2.44.2. DO NOT EDIT.
+
+package service
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// KeyRotation is an autogenerated mock type for the KeyRotation type
+type KeyRotation struct {
+	mock.Mock
+}
+
+type KeyRotation_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *KeyRotation) EXPECT() *KeyRotation_Expecter {
+	return &KeyRotation_Expecter{mock: &_m.Mock}
+}
+
+// RotateKeys provides a mock function with given fields: ctx, batchSize, progress
+func (_m *KeyRotation) RotateKeys(ctx context.Context, batchSize int, progress func(string, int)) error {
+	ret := _m.Called(ctx, batchSize, progress)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RotateKeys")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, func(string, int)) error); ok {
+		r0 = rf(ctx, batchSize, progress)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// KeyRotation_RotateKeys_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RotateKeys'
+type KeyRotation_RotateKeys_Call struct {
+	*mock.Call
+}
+
+// RotateKeys is a helper method to define mock.On call
+//   - ctx context.Context
+//   - batchSize int
+//   - progress func(string , int)
+func (_e *KeyRotation_Expecter) RotateKeys(ctx interface{}, batchSize interface{}, progress interface{}) *KeyRotation_RotateKeys_Call {
+	return &KeyRotation_RotateKeys_Call{Call: _e.mock.On("RotateKeys", ctx, batchSize, progress)}
+}
+
+func (_c *KeyRotation_RotateKeys_Call) Run(run func(ctx context.Context, batchSize int, progress func(string, int))) *KeyRotation_RotateKeys_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(func(string, int)))
+	})
+	return _c
+}
+
+func (_c *KeyRotation_RotateKeys_Call) Return(_a0 error) *KeyRotation_RotateKeys_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *KeyRotation_RotateKeys_Call) RunAndReturn(run func(context.Context, int, func(string, int)) error) *KeyRotation_RotateKeys_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewKeyRotation creates a new instance of KeyRotation. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewKeyRotation(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *KeyRotation {
+	mock := &KeyRotation{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,158 @@
+package admin
+
+import (
+	"crud-customer/pkg/logging"
+	"encoding/json"
+	"github.com/labstack/echo/v4"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"time"
+)
+
+type Options struct {
+	// Routes lists the routes of the API.
+	Routes func() []*echo.Route
+	// Config returns the effective configuration, dumped without the
+	// fields tagged secret:"true".
+	Config func() interface{}
+}
+
+var startedAt = time.Now()
+
+// NewServer returns the admin server, to be bound to a private address:
+//   - GET and PUT /loglevel read and change the level of the default logger
+//   - /debug/pprof/ serves the runtime profiles
+//   - GET /buildinfo describes the running binary
+//   - GET /routes lists the routes of the API
+//   - GET /config dumps the effective configuration without its secrets
+//
+// The endpoints are not authenticated, so binding them to anything but a
+// loopback address is warned about.
+func NewServer(addr string, options Options) *http.Server {
+	if host, _, err := net.SplitHostPort(addr); err != nil || !isLoopback(host) {
+		slog.Warn("admin server is not bound to localhost", "address", addr)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /loglevel", getLogLevel)
+	mux.HandleFunc("PUT /loglevel", setLogLevel)
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.HandleFunc("GET /buildinfo", getBuildInfo)
+	mux.HandleFunc("GET /routes", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, routes(options.Routes))
+	})
+	mux.HandleFunc("GET /config", func(w http.ResponseWriter, r *http.Request) {
+		var cfg interface{}
+		if options.Config != nil {
+			cfg = options.Config()
+		}
+		writeJSON(w, http.StatusOK, Sanitize(cfg))
+	})
+	return &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+}
+
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+type logLevel struct {
+	Level string `json:"level"`
+}
+
+func getLogLevel(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, logLevel{Level: logging.Level().String()})
+}
+
+func setLogLevel(w http.ResponseWriter, r *http.Request) {
+	var body logLevel
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1024)).Decode(&body); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid body"})
+		return
+	}
+	level, err := logging.ParseLevel(body.Level)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	previous := logging.Level()
+	logging.SetLevel(level)
+	slog.Warn("log level changed", "from", previous.String(), "to", level.String())
+	writeJSON(w, http.StatusOK, logLevel{Level: level.String()})
+}
+
+type buildInfo struct {
+	GoVersion string            `json:"go_version"`
+	Path      string            `json:"path"`
+	Version   string            `json:"version"`
+	Settings  map[string]string `json:"settings"`
+	StartedAt time.Time         `json:"started_at"`
+	Uptime    string            `json:"uptime"`
+}
+
+func getBuildInfo(w http.ResponseWriter, r *http.Request) {
+	info := buildInfo{
+		GoVersion: runtime.Version(),
+		Settings:  map[string]string{},
+		StartedAt: startedAt,
+		Uptime:    time.Since(startedAt).Round(time.Second).String(),
+	}
+	if build, ok := debug.ReadBuildInfo(); ok {
+		info.Path = build.Main.Path
+		info.Version = build.Main.Version
+		for _, setting := range build.Settings {
+			info.Settings[setting.Key] = setting.Value
+		}
+	}
+	writeJSON(w, http.StatusOK, info)
+}
+
+type route struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Name   string `json:"name"`
+}
+
+func routes(list func() []*echo.Route) []route {
+	result := make([]route, 0)
+	if list == nil {
+		return result
+	}
+	for _, r := range list() {
+		// Groups register catch-all routes answering 404.
+		if r.Method == echo.RouteNotFound {
+			continue
+		}
+		result = append(result, route{Method: r.Method, Path: r.Path, Name: r.Name})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Path != result[j].Path {
+			return result[i].Path < result[j].Path
+		}
+		return result[i].Method < result[j].Method
+	})
+	return result
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	_ = encoder.Encode(v)
+}
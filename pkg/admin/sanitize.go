@@ -0,0 +1,99 @@
+package admin
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+const redacted = "[REDACTED]"
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Sanitize returns v as maps, slices and scalars fit for JSON, keyed by the
+// mapstructure names of the fields. Non-empty values of fields tagged
+// secret:"true" are replaced with [REDACTED] and durations are written as
+// strings, the way they are configured.
+func Sanitize(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	return sanitize(reflect.ValueOf(v))
+}
+
+func sanitize(v reflect.Value) interface{} {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return sanitize(v.Elem())
+	case reflect.Struct:
+		result := make(map[string]interface{}, v.NumField())
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name, _, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+			if name == "" {
+				// Untagged fields are matched ignoring case and written
+				// in lower camel case in config.yaml.
+				name = strings.ToLower(field.Name[:1]) + field.Name[1:]
+			}
+			if field.Tag.Get("secret") == "true" && !v.Field(i).IsZero() {
+				result[name] = redacted
+				continue
+			}
+			result[name] = sanitize(v.Field(i))
+		}
+		return result
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		result := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			result[fmt.Sprint(iter.Key().Interface())] = sanitize(iter.Value())
+		}
+		return result
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		result := make([]interface{}, v.Len())
+		for i := range result {
+			result[i] = sanitize(v.Index(i))
+		}
+		return result
+	}
+	if v.Type() == durationType {
+		return time.Duration(v.Int()).String()
+	}
+	return v.Interface()
+}
+
+// Flatten returns the leaves of the maps returned by Sanitize under their
+// dotted keys, like server.rateLimit.groups.default.requests. Slices are
+// leaves.
+func Flatten(v interface{}) map[string]interface{} {
+	into := map[string]interface{}{}
+	flatten("", v, into)
+	return into
+}
+
+func flatten(prefix string, value interface{}, into map[string]interface{}) {
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		into[prefix] = value
+		return
+	}
+	for key, child := range m {
+		if prefix != "" {
+			key = fmt.Sprintf("%s.%s", prefix, key)
+		}
+		flatten(key, child, into)
+	}
+}
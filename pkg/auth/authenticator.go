@@ -0,0 +1,23 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+)
+
+var (
+	// ErrNoCredentials means the request carries no credentials the
+	// authenticator understands, so the next authenticator should be tried.
+	ErrNoCredentials      = errors.New("auth: no credentials")
+	ErrInvalidCredentials = errors.New("auth: invalid credentials")
+)
+
+type Authenticator interface {
+	Authenticate(r *http.Request) (*Principal, error)
+}
+
+type AuthenticatorFunc func(r *http.Request) (*Principal, error)
+
+func (f AuthenticatorFunc) Authenticate(r *http.Request) (*Principal, error) {
+	return f(r)
+}
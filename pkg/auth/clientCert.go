@@ -0,0 +1,28 @@
+package auth
+
+import "net/http"
+
+const PrincipalTypeClientCert = "client_cert"
+
+// NewClientCertAuthenticator authenticates requests by the client
+// certificate verified during the TLS handshake. The principal's subject is
+// the certificate's distinguished name and its roles are the certificate's
+// organizational units, which the rbac config maps to permissions.
+func NewClientCertAuthenticator() Authenticator {
+	return AuthenticatorFunc(func(r *http.Request) (*Principal, error) {
+		if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+			return nil, ErrNoCredentials
+		}
+		cert := r.TLS.VerifiedChains[0][0]
+		return &Principal{
+			Type:    PrincipalTypeClientCert,
+			Subject: "cert:" + cert.Subject.String(),
+			Roles:   cert.Subject.OrganizationalUnit,
+			Claims: map[string]interface{}{
+				"subject":     cert.Subject.String(),
+				"common_name": cert.Subject.CommonName,
+				"serial":      cert.SerialNumber.String(),
+			},
+		}, nil
+	})
+}
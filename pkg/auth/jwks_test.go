@@ -0,0 +1,96 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadJWKSFile(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	assert.NoError(t, err)
+	encryptionKey := rsaJWK("enc-1", &rsaKey.PublicKey)
+	encryptionKey["use"] = "enc"
+	offCurve := ecJWK("ec-2", &ecKey.PublicKey)
+	offCurve["y"] = base64.RawURLEncoding.EncodeToString(make([]byte, 48))
+	smallExponent := rsaJWK("rsa-2", &rsaKey.PublicKey)
+	smallExponent["e"] = "AQ"
+
+	testCases := []struct {
+		name     string
+		keys     []map[string]string
+		wantKids []string
+		wantErr  string
+	}{
+		{
+			name:     "rsa and ec keys",
+			keys:     []map[string]string{rsaJWK("rsa-1", &rsaKey.PublicKey), ecJWK("ec-1", &ecKey.PublicKey)},
+			wantKids: []string{"ec-1", "rsa-1"},
+		},
+		{
+			name:     "encryption keys skipped",
+			keys:     []map[string]string{rsaJWK("rsa-1", &rsaKey.PublicKey), encryptionKey},
+			wantKids: []string{"rsa-1"},
+		},
+		{
+			name:    "only encryption keys",
+			keys:    []map[string]string{encryptionKey},
+			wantErr: "jwks contains no signing keys",
+		},
+		{
+			name:    "point off the curve",
+			keys:    []map[string]string{offCurve},
+			wantErr: `error parsing jwks key 0 (kid "ec-2")`,
+		},
+		{
+			name:    "small exponent",
+			keys:    []map[string]string{smallExponent},
+			wantErr: "invalid exponent",
+		},
+		{
+			name:    "unsupported key type",
+			keys:    []map[string]string{{"kty": "oct", "kid": "hmac"}},
+			wantErr: `unsupported key type "oct"`,
+		},
+		{
+			name:    "unsupported curve",
+			keys:    []map[string]string{{"kty": "EC", "kid": "ec", "crv": "P-224"}},
+			wantErr: `unsupported curve "P-224"`,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "jwks.json")
+			writeJWKS(path, tt.keys...)
+			keys, err := LoadJWKSFile(path)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			var kids []string
+			for kid := range keys {
+				kids = append(kids, kid)
+			}
+			assert.ElementsMatch(t, tt.wantKids, kids)
+		})
+	}
+}
+
+func TestLoadJWKSFileInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	_, err := LoadJWKSFile(path)
+	assert.True(t, os.IsNotExist(err))
+
+	assert.NoError(t, os.WriteFile(path, []byte("not json"), 0o600))
+	_, err = LoadJWKSFile(path)
+	assert.ErrorContains(t, err, "error parsing jwks")
+}
@@ -0,0 +1,198 @@
+package auth
+
+import (
+	"crypto"
+	"errors"
+	"fmt"
+	"github.com/fsnotify/fsnotify"
+	"github.com/golang-jwt/jwt/v5"
+	"log/slog"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const PrincipalTypeJWT = "jwt"
+
+type JWTVerifierConfig struct {
+	JWKSFile   string
+	Issuer     string
+	Audience   string
+	ClockSkew  time.Duration
+	Algorithms []string
+	// RoleClaim names the claim holding the caller's roles, either a list or
+	// a space separated string.
+	RoleClaim string
+	// TenantClaim names the claim binding the caller to a tenant.
+	TenantClaim string
+}
+
+// JWTVerifier validates bearer tokens against the keys of a local JWKS file.
+// The file is watched and reloaded when it changes, so keys can be rotated
+// by replacing it; a file that fails to parse keeps the previous keys.
+type JWTVerifier struct {
+	cfg     JWTVerifierConfig
+	keys    atomic.Pointer[map[string]crypto.PublicKey]
+	parser  *jwt.Parser
+	watcher *fsnotify.Watcher
+}
+
+func (v *JWTVerifier) Verify(tokenString string) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+	_, err := v.parser.ParseWithClaims(tokenString, claims, v.keyFunc)
+	if err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+func (v *JWTVerifier) keyFunc(token *jwt.Token) (interface{}, error) {
+	keys := *v.keys.Load()
+	kid, _ := token.Header["kid"].(string)
+	if key, ok := keys[kid]; ok {
+		return key, nil
+	}
+	if kid == "" && len(keys) == 1 {
+		for _, key := range keys {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown key id %q", kid)
+}
+
+func (v *JWTVerifier) reload() error {
+	keys, err := LoadJWKSFile(v.cfg.JWKSFile)
+	if err != nil {
+		return err
+	}
+	v.keys.Store(&keys)
+	return nil
+}
+
+// watch reloads the JWKS whenever its directory changes. The directory is
+// watched rather than the file so atomic renames and Kubernetes ConfigMap
+// symlink swaps are picked up.
+func (v *JWTVerifier) watch() {
+	for {
+		select {
+		case event, ok := <-v.watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename|fsnotify.Remove) == 0 {
+				continue
+			}
+			name := filepath.Base(event.Name)
+			if name != filepath.Base(v.cfg.JWKSFile) && !strings.HasPrefix(name, "..") {
+				continue
+			}
+			if err := v.reload(); err != nil {
+				slog.Warn("keeping previous jwks, error reloading", "file", v.cfg.JWKSFile, "error", err)
+				continue
+			}
+			slog.Info("reloaded jwks", "file", v.cfg.JWKSFile)
+		case err, ok := <-v.watcher.Errors:
+			if !ok {
+				return
+			}
+			slog.Warn("error watching jwks", "file", v.cfg.JWKSFile, "error", err)
+		}
+	}
+}
+
+// Close stops watching the JWKS.
+func (v *JWTVerifier) Close() error {
+	return v.watcher.Close()
+}
+
+// NewJWTVerifier loads the JWKS and starts watching it until Close. Tokens
+// must be signed with one of the algorithms and carry the issuer and
+// audience, which are required: without them, tokens the same keys sign for
+// other services would be accepted.
+func NewJWTVerifier(cfg JWTVerifierConfig) (*JWTVerifier, error) {
+	if cfg.Issuer == "" || cfg.Audience == "" {
+		return nil, errors.New("jwt issuer and audience are required")
+	}
+	v := &JWTVerifier{
+		cfg: cfg,
+		parser: jwt.NewParser(
+			jwt.WithValidMethods(cfg.Algorithms),
+			jwt.WithLeeway(cfg.ClockSkew),
+			jwt.WithExpirationRequired(),
+			jwt.WithIssuedAt(),
+			jwt.WithIssuer(cfg.Issuer),
+			jwt.WithAudience(cfg.Audience),
+		),
+	}
+	if err := v.reload(); err != nil {
+		return nil, err
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	if err := watcher.Add(filepath.Dir(cfg.JWKSFile)); err != nil {
+		watcher.Close()
+		return nil, err
+	}
+	v.watcher = watcher
+	go v.watch()
+	return v, nil
+}
+
+// NewJWTAuthenticator authenticates requests carrying an
+// "Authorization: Bearer <jwt>" header.
+func NewJWTAuthenticator(verifier *JWTVerifier) Authenticator {
+	roleClaim := verifier.cfg.RoleClaim
+	tenantClaim := verifier.cfg.TenantClaim
+	return AuthenticatorFunc(func(r *http.Request) (*Principal, error) {
+		scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+		if !ok || !strings.EqualFold(scheme, "Bearer") {
+			return nil, ErrNoCredentials
+		}
+		claims, err := verifier.Verify(strings.TrimSpace(token))
+		if err != nil {
+			return nil, errors.Join(ErrInvalidCredentials, err)
+		}
+		subject, _ := claims.GetSubject()
+		principal := &Principal{
+			Type:    PrincipalTypeJWT,
+			Subject: subject,
+			Scopes:  scopesFromClaims(claims),
+			Roles:   stringsFromClaim(claims, roleClaim),
+			Claims:  claims,
+		}
+		if tenantClaim != "" {
+			principal.TenantID, _ = claims[tenantClaim].(string)
+		}
+		return principal, nil
+	})
+}
+
+// scopesFromClaims reads the space separated "scope" claim, or the "scp"
+// array some issuers use instead.
+func scopesFromClaims(claims jwt.MapClaims) []string {
+	if _, ok := claims["scope"]; ok {
+		return stringsFromClaim(claims, "scope")
+	}
+	return stringsFromClaim(claims, "scp")
+}
+
+func stringsFromClaim(claims jwt.MapClaims, name string) []string {
+	switch value := claims[name].(type) {
+	case string:
+		return strings.Fields(value)
+	case []interface{}:
+		var values []string
+		for _, v := range value {
+			if str, ok := v.(string); ok {
+				values = append(values, str)
+			}
+		}
+		return values
+	}
+	return nil
+}
@@ -0,0 +1,256 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/suite"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func rsaJWK(kid string, key *rsa.PublicKey) map[string]string {
+	return map[string]string{
+		"kty": "RSA",
+		"kid": kid,
+		"use": "sig",
+		"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func ecJWK(kid string, key *ecdsa.PublicKey) map[string]string {
+	size := (key.Curve.Params().BitSize + 7) / 8
+	return map[string]string{
+		"kty": "EC",
+		"kid": kid,
+		"crv": key.Curve.Params().Name,
+		"x":   base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, size))),
+		"y":   base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, size))),
+	}
+}
+
+func writeJWKS(path string, keys ...map[string]string) {
+	data, err := json.Marshal(map[string]interface{}{"keys": keys})
+	if err != nil {
+		panic(err)
+	}
+	// Written aside and renamed, as deployments replace the file.
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		panic(err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		panic(err)
+	}
+}
+
+type JWTVerifierTestSuite struct {
+	suite.Suite
+	dir      string
+	jwksFile string
+	rsaKey   *rsa.PrivateKey
+	ecKey    *ecdsa.PrivateKey
+	verifier *JWTVerifier
+}
+
+func (s *JWTVerifierTestSuite) SetupSuite() {
+	var err error
+	s.rsaKey, err = rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+	s.ecKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (s *JWTVerifierTestSuite) SetupTest() {
+	s.dir = s.T().TempDir()
+	s.jwksFile = filepath.Join(s.dir, "jwks.json")
+	writeJWKS(s.jwksFile, rsaJWK("rsa-1", &s.rsaKey.PublicKey), ecJWK("ec-1", &s.ecKey.PublicKey))
+	var err error
+	s.verifier, err = NewJWTVerifier(JWTVerifierConfig{
+		JWKSFile:    s.jwksFile,
+		Issuer:      "https://issuer.example.com",
+		Audience:    "crud-customer",
+		ClockSkew:   30 * time.Second,
+		Algorithms:  []string{"RS256", "ES256"},
+		RoleClaim:   "roles",
+		TenantClaim: "tenant_id",
+	})
+	s.Require().NoError(err)
+}
+
+func (s *JWTVerifierTestSuite) TearDownTest() {
+	s.verifier.Close()
+}
+
+func (s *JWTVerifierTestSuite) claims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub": "user-1",
+		"iss": "https://issuer.example.com",
+		"aud": "crud-customer",
+		"exp": time.Now().Add(time.Minute).Unix(),
+		"iat": time.Now().Unix(),
+	}
+}
+
+func (s *JWTVerifierTestSuite) sign(method jwt.SigningMethod, kid string, key interface{}, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	s.Require().NoError(err)
+	return signed
+}
+
+func (s *JWTVerifierTestSuite) TestVerifySuccess() {
+	claims, err := s.verifier.Verify(s.sign(jwt.SigningMethodRS256, "rsa-1", s.rsaKey, s.claims()))
+	s.NoError(err)
+	s.Equal("user-1", claims["sub"])
+
+	_, err = s.verifier.Verify(s.sign(jwt.SigningMethodES256, "ec-1", s.ecKey, s.claims()))
+	s.NoError(err)
+}
+
+func (s *JWTVerifierTestSuite) TestVerifyExpiry() {
+	claims := s.claims()
+	claims["exp"] = time.Now().Add(-time.Minute).Unix()
+	_, err := s.verifier.Verify(s.sign(jwt.SigningMethodRS256, "rsa-1", s.rsaKey, claims))
+	s.True(errors.Is(err, jwt.ErrTokenExpired))
+
+	claims = s.claims()
+	delete(claims, "exp")
+	_, err = s.verifier.Verify(s.sign(jwt.SigningMethodRS256, "rsa-1", s.rsaKey, claims))
+	s.True(errors.Is(err, jwt.ErrTokenRequiredClaimMissing))
+}
+
+func (s *JWTVerifierTestSuite) TestVerifyClockSkew() {
+	claims := s.claims()
+	claims["exp"] = time.Now().Add(-10 * time.Second).Unix()
+	_, err := s.verifier.Verify(s.sign(jwt.SigningMethodRS256, "rsa-1", s.rsaKey, claims))
+	s.NoError(err)
+
+	claims = s.claims()
+	claims["iat"] = time.Now().Add(10 * time.Second).Unix()
+	_, err = s.verifier.Verify(s.sign(jwt.SigningMethodRS256, "rsa-1", s.rsaKey, claims))
+	s.NoError(err)
+
+	claims["iat"] = time.Now().Add(time.Minute).Unix()
+	_, err = s.verifier.Verify(s.sign(jwt.SigningMethodRS256, "rsa-1", s.rsaKey, claims))
+	s.True(errors.Is(err, jwt.ErrTokenUsedBeforeIssued))
+}
+
+func (s *JWTVerifierTestSuite) TestVerifyWrongAlgorithm() {
+	_, err := s.verifier.Verify(s.sign(jwt.SigningMethodRS384, "rsa-1", s.rsaKey, s.claims()))
+	s.True(errors.Is(err, jwt.ErrTokenSignatureInvalid))
+
+	// An HMAC keyed with the public key must not pass for RS256.
+	publicKey, err := json.Marshal(rsaJWK("rsa-1", &s.rsaKey.PublicKey))
+	s.Require().NoError(err)
+	_, err = s.verifier.Verify(s.sign(jwt.SigningMethodHS256, "rsa-1", publicKey, s.claims()))
+	s.True(errors.Is(err, jwt.ErrTokenSignatureInvalid))
+
+	_, err = s.verifier.Verify(s.sign(jwt.SigningMethodNone, "rsa-1", jwt.UnsafeAllowNoneSignatureType, s.claims()))
+	s.Error(err)
+
+	// A key of another type under the kid of the token.
+	_, err = s.verifier.Verify(s.sign(jwt.SigningMethodES256, "rsa-1", s.ecKey, s.claims()))
+	s.Error(err)
+}
+
+func (s *JWTVerifierTestSuite) TestVerifyWrongIssuerOrAudience() {
+	claims := s.claims()
+	claims["iss"] = "https://other.example.com"
+	_, err := s.verifier.Verify(s.sign(jwt.SigningMethodRS256, "rsa-1", s.rsaKey, claims))
+	s.True(errors.Is(err, jwt.ErrTokenInvalidIssuer))
+
+	claims = s.claims()
+	claims["aud"] = []string{"other-service"}
+	_, err = s.verifier.Verify(s.sign(jwt.SigningMethodRS256, "rsa-1", s.rsaKey, claims))
+	s.True(errors.Is(err, jwt.ErrTokenInvalidAudience))
+
+	claims = s.claims()
+	delete(claims, "aud")
+	_, err = s.verifier.Verify(s.sign(jwt.SigningMethodRS256, "rsa-1", s.rsaKey, claims))
+	s.True(errors.Is(err, jwt.ErrTokenRequiredClaimMissing))
+}
+
+func (s *JWTVerifierTestSuite) TestVerifyUnknownKey() {
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	s.Require().NoError(err)
+	_, err = s.verifier.Verify(s.sign(jwt.SigningMethodRS256, "rsa-2", other, s.claims()))
+	s.ErrorContains(err, `unknown key id "rsa-2"`)
+
+	// Without a kid, only a JWKS of a single key is used.
+	_, err = s.verifier.Verify(s.sign(jwt.SigningMethodRS256, "", s.rsaKey, s.claims()))
+	s.ErrorContains(err, `unknown key id ""`)
+}
+
+func (s *JWTVerifierTestSuite) TestKeyRotation() {
+	rotated, err := rsa.GenerateKey(rand.Reader, 2048)
+	s.Require().NoError(err)
+	token := s.sign(jwt.SigningMethodRS256, "rsa-2", rotated, s.claims())
+
+	writeJWKS(s.jwksFile, rsaJWK("rsa-2", &rotated.PublicKey))
+	s.Eventually(func() bool {
+		_, err := s.verifier.Verify(token)
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	_, err = s.verifier.Verify(s.sign(jwt.SigningMethodRS256, "rsa-1", s.rsaKey, s.claims()))
+	s.ErrorContains(err, `unknown key id "rsa-1"`)
+
+	// A broken file keeps the keys loaded last.
+	s.Require().NoError(os.WriteFile(s.jwksFile, []byte("{"), 0o600))
+	time.Sleep(100 * time.Millisecond)
+	_, err = s.verifier.Verify(token)
+	s.NoError(err)
+}
+
+func (s *JWTVerifierTestSuite) TestNewJWTVerifierRequiresIssuerAndAudience() {
+	_, err := NewJWTVerifier(JWTVerifierConfig{JWKSFile: s.jwksFile, Issuer: "https://issuer.example.com"})
+	s.EqualError(err, "jwt issuer and audience are required")
+}
+
+func (s *JWTVerifierTestSuite) TestJWTAuthenticator() {
+	authenticator := NewJWTAuthenticator(s.verifier)
+	claims := s.claims()
+	claims["roles"] = []string{"support", "admin"}
+	claims["scope"] = "customers:read audit:read"
+	claims["tenant_id"] = "acme"
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer "+s.sign(jwt.SigningMethodRS256, "rsa-1", s.rsaKey, claims))
+	principal, err := authenticator.Authenticate(req)
+	s.NoError(err)
+	s.Equal(PrincipalTypeJWT, principal.Type)
+	s.Equal("user-1", principal.Subject)
+	s.Equal([]string{"support", "admin"}, principal.Roles)
+	s.Equal([]string{"customers:read", "audit:read"}, principal.Scopes)
+	s.Equal("acme", principal.TenantID)
+
+	req.Header.Set("Authorization", "Bearer nonsense")
+	_, err = authenticator.Authenticate(req)
+	s.True(errors.Is(err, ErrInvalidCredentials))
+
+	req.Header.Set("Authorization", "Basic dXNlcjpwYXNz")
+	_, err = authenticator.Authenticate(req)
+	s.True(errors.Is(err, ErrNoCredentials))
+}
+
+func TestJWTVerifierTestSuite(t *testing.T) {
+	suite.Run(t, new(JWTVerifierTestSuite))
+}
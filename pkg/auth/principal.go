@@ -0,0 +1,48 @@
+package auth
+
+import "context"
+
+const (
+	PrincipalTypeAPIKey = "api_key"
+)
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	Type    string
+	Subject string
+	Scopes  []string
+	Roles   []string
+	// TenantID is the tenant the credentials are bound to: "" for none,
+	// which acts on the default tenant, or "*" for any tenant.
+	TenantID string
+	Claims   map[string]interface{}
+}
+
+// HasScope reports whether the scopes of p grant scope, itself or through a
+// wildcard: "customers:*" grants every scope under "customers:" and "*"
+// grants every scope.
+func (p *Principal) HasScope(scope string) bool {
+	for _, s := range p.Scopes {
+		if permissionMatches(s, scope) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsScoped reports whether p is restricted to its scopes, which API keys
+// always are, with or without RBAC.
+func (p *Principal) IsScoped() bool {
+	return p.Type == PrincipalTypeAPIKey
+}
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*Principal)
+	return principal, ok
+}
@@ -0,0 +1,33 @@
+package auth
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPrincipal_HasScope(t *testing.T) {
+	testCases := []struct {
+		name   string
+		scopes []string
+		scope  string
+		want   bool
+	}{
+		{name: "exact", scopes: []string{"customers:read"}, scope: "customers:read", want: true},
+		{name: "other scope", scopes: []string{"customers:read"}, scope: "customers:write", want: false},
+		{name: "prefix wildcard", scopes: []string{"customers:*"}, scope: "customers:delete", want: true},
+		{name: "prefix wildcard other prefix", scopes: []string{"customers:*"}, scope: "audit:read", want: false},
+		{name: "wildcard", scopes: []string{"*"}, scope: "audit:read", want: true},
+		{name: "no scopes", scope: "customers:read", want: false},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Principal{Type: PrincipalTypeAPIKey, Scopes: tt.scopes}
+			assert.Equal(t, tt.want, p.HasScope(tt.scope))
+		})
+	}
+}
+
+func TestPrincipal_IsScoped(t *testing.T) {
+	assert.True(t, (&Principal{Type: PrincipalTypeAPIKey}).IsScoped())
+	assert.False(t, (&Principal{Type: PrincipalTypeJWT}).IsScoped())
+}
@@ -0,0 +1,54 @@
+package auth
+
+import "strings"
+
+// Authorizer resolves a principal's permissions from the role mapping in
+// config. Each role of the principal expands to the permissions of the
+// configured role of that name; a role that is not configured grants
+// nothing. Scopes, such as those of API keys, are permissions themselves.
+//
+// A permission ending in ":*" grants everything under its prefix and "*"
+// grants everything.
+type Authorizer struct {
+	roles map[string][]string
+}
+
+func (a *Authorizer) Permissions(principal *Principal) []string {
+	var permissions []string
+	for _, role := range principal.Roles {
+		permissions = append(permissions, a.roles[strings.ToLower(role)]...)
+	}
+	return append(permissions, principal.Scopes...)
+}
+
+func (a *Authorizer) IsAllowed(principal *Principal, permission string) bool {
+	if principal == nil {
+		return false
+	}
+	for _, granted := range a.Permissions(principal) {
+		if permissionMatches(granted, permission) {
+			return true
+		}
+	}
+	return false
+}
+
+func permissionMatches(granted string, permission string) bool {
+	if granted == "*" || granted == permission {
+		return true
+	}
+	if prefix, ok := strings.CutSuffix(granted, "*"); ok {
+		return strings.HasPrefix(permission, prefix)
+	}
+	return false
+}
+
+// NewAuthorizer builds an Authorizer from a role to permissions mapping.
+// Role names are matched case-insensitively because viper lowercases map keys.
+func NewAuthorizer(roles map[string][]string) *Authorizer {
+	normalized := make(map[string][]string, len(roles))
+	for role, permissions := range roles {
+		normalized[strings.ToLower(role)] = permissions
+	}
+	return &Authorizer{roles: normalized}
+}
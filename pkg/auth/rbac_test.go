@@ -0,0 +1,44 @@
+package auth
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestAuthorizer(t *testing.T) {
+	authorizer := NewAuthorizer(map[string][]string{
+		"Support": {"customers:read"},
+		"admin":   {"customers:*", "audit:read"},
+	})
+	testCases := []struct {
+		name       string
+		principal  *Principal
+		permission string
+		want       bool
+	}{
+		{name: "role", principal: &Principal{Roles: []string{"support"}}, permission: "customers:read", want: true},
+		{name: "role lacking the permission", principal: &Principal{Roles: []string{"support"}}, permission: "customers:write"},
+		{name: "role names are case-insensitive", principal: &Principal{Roles: []string{"SUPPORT"}}, permission: "customers:read", want: true},
+		{name: "wildcard role permission", principal: &Principal{Roles: []string{"admin"}}, permission: "customers:delete", want: true},
+		{name: "wildcard stops at its prefix", principal: &Principal{Roles: []string{"admin"}}, permission: "audit:write"},
+		{name: "any of the roles", principal: &Principal{Roles: []string{"support", "admin"}}, permission: "audit:read", want: true},
+		{name: "unknown role grants nothing", principal: &Principal{Roles: []string{"customers:read"}}, permission: "customers:read"},
+		{name: "wildcard role name grants nothing", principal: &Principal{Roles: []string{"*"}}, permission: "customers:read"},
+		{name: "scope is a permission", principal: &Principal{Type: PrincipalTypeAPIKey, Scopes: []string{"customers:read"}}, permission: "customers:read", want: true},
+		{name: "scope naming a role is not expanded", principal: &Principal{Type: PrincipalTypeAPIKey, Scopes: []string{"admin"}}, permission: "customers:read"},
+		{name: "wildcard scope", principal: &Principal{Type: PrincipalTypeAPIKey, Scopes: []string{"*"}}, permission: "audit:read", want: true},
+		{name: "no grants", principal: &Principal{}, permission: "customers:read"},
+		{name: "no principal", permission: "customers:read"},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, authorizer.IsAllowed(tt.principal, tt.permission))
+		})
+	}
+}
+
+func TestAuthorizerPermissions(t *testing.T) {
+	authorizer := NewAuthorizer(map[string][]string{"support": {"customers:read"}})
+	principal := &Principal{Roles: []string{"support", "unknown"}, Scopes: []string{"audit:read"}}
+	assert.Equal(t, []string{"customers:read", "audit:read"}, authorizer.Permissions(principal))
+}
@@ -0,0 +1,50 @@
+package codec
+
+import (
+	"errors"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strings"
+)
+
+// Binder extends echo's DefaultBinder with the request body formats in the
+// registry that echo cannot decode itself, such as MessagePack and CSV.
+type Binder struct {
+	echo.DefaultBinder
+	registry *Registry
+}
+
+func (b *Binder) Bind(i interface{}, c echo.Context) error {
+	req := c.Request()
+	ctype := req.Header.Get(echo.HeaderContentType)
+	if req.ContentLength == 0 || isNativeContentType(ctype) {
+		return b.DefaultBinder.Bind(i, c)
+	}
+	codec, ok := b.registry.Lookup(ctype)
+	if !ok {
+		return echo.ErrUnsupportedMediaType
+	}
+
+	if err := b.BindPathParams(c, i); err != nil {
+		return err
+	}
+	if err := codec.Decode(req.Body, i); err != nil {
+		if errors.Is(err, ErrUnsupported) {
+			return echo.NewHTTPError(http.StatusUnsupportedMediaType, err.Error()).SetInternal(err)
+		}
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+	}
+	return nil
+}
+
+func isNativeContentType(ctype string) bool {
+	return strings.HasPrefix(ctype, echo.MIMEApplicationJSON) ||
+		strings.HasPrefix(ctype, echo.MIMEApplicationXML) ||
+		strings.HasPrefix(ctype, echo.MIMETextXML) ||
+		strings.HasPrefix(ctype, echo.MIMEApplicationForm) ||
+		strings.HasPrefix(ctype, echo.MIMEMultipartForm)
+}
+
+func NewBinder(registry *Registry) *Binder {
+	return &Binder{registry: registry}
+}
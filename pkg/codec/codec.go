@@ -0,0 +1,17 @@
+package codec
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrUnsupported is returned by a Codec that cannot encode or decode the
+// given value, e.g. CSV for a nested document.
+var ErrUnsupported = errors.New("codec: unsupported value")
+
+type Codec interface {
+	// ContentType is the MIME type the codec is registered under.
+	ContentType() string
+	Encode(w io.Writer, v interface{}) error
+	Decode(r io.Reader, v interface{}) error
+}
@@ -0,0 +1,223 @@
+package codec
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var csvNumber = regexp.MustCompile(`^[-+]?[0-9]*\.?[0-9]+([eE][-+]?[0-9]+)?$`)
+
+// CSVMarshaler is implemented by values that pick their own CSV rows, such
+// as response envelopes that only want their data rendered.
+type CSVMarshaler interface {
+	MarshalCSV() ([][]string, error)
+}
+
+// CSVCodec renders a struct or a slice of structs as a header row followed by
+// one row per struct, using json tag names as column names. Cells a
+// spreadsheet would evaluate as formulas are escaped. Decoding fills a single
+// struct from a header row and one value row.
+type CSVCodec struct{}
+
+func (c *CSVCodec) ContentType() string {
+	return "text/csv"
+}
+
+func (c *CSVCodec) Encode(w io.Writer, v interface{}) error {
+	var records [][]string
+	var err error
+	if m, ok := v.(CSVMarshaler); ok {
+		records, err = m.MarshalCSV()
+	} else {
+		records, err = MarshalCSVRecords(v)
+	}
+	if err != nil {
+		return err
+	}
+	for _, record := range records {
+		for i, cell := range record {
+			record[i] = EscapeCSVFormula(cell)
+		}
+	}
+	writer := csv.NewWriter(w)
+	if err := writer.WriteAll(records); err != nil {
+		return err
+	}
+	return writer.Error()
+}
+
+func (c *CSVCodec) Decode(r io.Reader, v interface{}) error {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return err
+	}
+	if len(records) != 2 {
+		return fmt.Errorf("%w: expected a header and exactly one row, got %d rows", ErrUnsupported, len(records))
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Struct {
+		return ErrUnsupported
+	}
+	fields := csvFields(rv.Elem().Type())
+	for i, column := range records[0] {
+		index, ok := fields[column]
+		if !ok || i >= len(records[1]) {
+			continue
+		}
+		if err := setCSVValue(rv.Elem().Field(index), records[1][i]); err != nil {
+			return fmt.Errorf("column %s: %w", column, err)
+		}
+	}
+	return nil
+}
+
+// EscapeCSVFormula prefixes value with a single quote when a spreadsheet
+// would evaluate it as a formula: when it starts with '=', '+', '-', '@', a
+// tab or a carriage return. Numbers are left as they are.
+func EscapeCSVFormula(value string) string {
+	if value == "" || !strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return value
+	}
+	if csvNumber.MatchString(value) {
+		return value
+	}
+	return "'" + value
+}
+
+// MarshalCSVRecords converts a struct, a pointer to a struct or a slice of
+// either into CSV records with a header row.
+func MarshalCSVRecords(v interface{}) ([][]string, error) {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	var rows []reflect.Value
+	var elemType reflect.Type
+	switch rv.Kind() {
+	case reflect.Struct:
+		rows = []reflect.Value{rv}
+		elemType = rv.Type()
+	case reflect.Slice, reflect.Array:
+		elemType = rv.Type().Elem()
+		if elemType.Kind() == reflect.Pointer {
+			elemType = elemType.Elem()
+		}
+		for i := 0; i < rv.Len(); i++ {
+			rows = append(rows, reflect.Indirect(rv.Index(i)))
+		}
+	default:
+		return nil, ErrUnsupported
+	}
+	if elemType.Kind() != reflect.Struct {
+		return nil, ErrUnsupported
+	}
+
+	var header []string
+	var indexes []int
+	for i := 0; i < elemType.NumField(); i++ {
+		name, ok := csvColumn(elemType.Field(i))
+		if !ok {
+			continue
+		}
+		if !isCSVScalar(elemType.Field(i).Type) {
+			return nil, ErrUnsupported
+		}
+		header = append(header, name)
+		indexes = append(indexes, i)
+	}
+
+	records := [][]string{header}
+	for _, row := range rows {
+		record := make([]string, 0, len(indexes))
+		for _, i := range indexes {
+			field := reflect.Indirect(row.Field(i))
+			if !field.IsValid() {
+				record = append(record, "")
+				continue
+			}
+			record = append(record, fmt.Sprint(field.Interface()))
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func csvColumn(field reflect.StructField) (string, bool) {
+	if !field.IsExported() {
+		return "", false
+	}
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" {
+		return "", false
+	}
+	if name == "" {
+		name = field.Name
+	}
+	return name, true
+}
+
+func csvFields(t reflect.Type) map[string]int {
+	fields := map[string]int{}
+	for i := 0; i < t.NumField(); i++ {
+		if name, ok := csvColumn(t.Field(i)); ok {
+			fields[name] = i
+		}
+	}
+	return fields
+}
+
+func isCSVScalar(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	_, ok := reflect.New(t).Interface().(fmt.Stringer)
+	return ok
+}
+
+func setCSVValue(field reflect.Value, value string) error {
+	if field.Kind() == reflect.Pointer {
+		field.Set(reflect.New(field.Type().Elem()))
+		field = field.Elem()
+	}
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(n)
+	default:
+		return ErrUnsupported
+	}
+	return nil
+}
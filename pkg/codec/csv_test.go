@@ -0,0 +1,43 @@
+package codec
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestEscapeCSVFormula(t *testing.T) {
+	testCases := []struct {
+		value string
+		want  string
+	}{
+		{value: "John Doe", want: "John Doe"},
+		{value: "", want: ""},
+		{value: "=1+2", want: "'=1+2"},
+		{value: "+1+2", want: "'+1+2"},
+		{value: "-1+2", want: "'-1+2"},
+		{value: "@SUM(A1:A2)", want: "'@SUM(A1:A2)"},
+		{value: "\t=1", want: "'\t=1"},
+		{value: "\r=1", want: "'\r=1"},
+		{value: "-Inf", want: "'-Inf"},
+		{value: "-12", want: "-12"},
+		{value: "+1.5e3", want: "+1.5e3"},
+		{value: "a=1", want: "a=1"},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.value, func(t *testing.T) {
+			assert.Equal(t, tt.want, EscapeCSVFormula(tt.value))
+		})
+	}
+}
+
+func TestCSVCodecEncodeEscapesFormulas(t *testing.T) {
+	type row struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+	var buf bytes.Buffer
+	err := (&CSVCodec{}).Encode(&buf, []row{{Name: `=HYPERLINK("http://evil")`, Age: -1}, {Name: "Jane", Age: 30}})
+	assert.NoError(t, err)
+	assert.Equal(t, "name,age\n\"'=HYPERLINK(\"\"http://evil\"\")\",-1\nJane,30\n", buf.String())
+}
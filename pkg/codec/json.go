@@ -0,0 +1,20 @@
+package codec
+
+import (
+	"encoding/json"
+	"io"
+)
+
+type JSONCodec struct{}
+
+func (j *JSONCodec) ContentType() string {
+	return "application/json"
+}
+
+func (j *JSONCodec) Encode(w io.Writer, v interface{}) error {
+	return json.NewEncoder(w).Encode(v)
+}
+
+func (j *JSONCodec) Decode(r io.Reader, v interface{}) error {
+	return json.NewDecoder(r).Decode(v)
+}
@@ -0,0 +1,26 @@
+package codec
+
+import (
+	"github.com/vmihailenco/msgpack/v5"
+	"io"
+)
+
+// MsgPackCodec encodes structs as maps keyed by their json tag names so the
+// field names match the JSON representation.
+type MsgPackCodec struct{}
+
+func (m *MsgPackCodec) ContentType() string {
+	return "application/msgpack"
+}
+
+func (m *MsgPackCodec) Encode(w io.Writer, v interface{}) error {
+	enc := msgpack.NewEncoder(w)
+	enc.SetCustomStructTag("json")
+	return enc.Encode(v)
+}
+
+func (m *MsgPackCodec) Decode(r io.Reader, v interface{}) error {
+	dec := msgpack.NewDecoder(r)
+	dec.SetCustomStructTag("json")
+	return dec.Decode(v)
+}
@@ -0,0 +1,138 @@
+package codec
+
+import (
+	"errors"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var ErrNotAcceptable = errors.New("codec: no acceptable content type")
+
+var (
+	registryOnce     sync.Once
+	registryInstance *Registry
+)
+
+// Registry holds the codecs available for content negotiation. The first
+// registered codec is used when the client accepts anything.
+type Registry struct {
+	mu     sync.RWMutex
+	codecs []Codec
+}
+
+func (r *Registry) Register(codec Codec) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, c := range r.codecs {
+		if c.ContentType() == codec.ContentType() {
+			r.codecs[i] = codec
+			return
+		}
+	}
+	r.codecs = append(r.codecs, codec)
+}
+
+// Lookup returns the codec registered for contentType, ignoring parameters
+// such as charset.
+func (r *Registry) Lookup(contentType string) (Codec, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, c := range r.codecs {
+		if c.ContentType() == mediaType {
+			return c, true
+		}
+	}
+	return nil, false
+}
+
+// Negotiate picks the codec that best matches an Accept header, honouring
+// q-values and wildcards. An empty header accepts the default codec.
+func (r *Registry) Negotiate(accept string) (Codec, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if len(r.codecs) == 0 {
+		return nil, ErrNotAcceptable
+	}
+	if strings.TrimSpace(accept) == "" {
+		return r.codecs[0], nil
+	}
+
+	for _, rng := range parseAccept(accept) {
+		for _, c := range r.codecs {
+			if rng.matches(c.ContentType()) {
+				return c, nil
+			}
+		}
+	}
+	return nil, ErrNotAcceptable
+}
+
+type mediaRange struct {
+	mediaType string
+	q         float64
+}
+
+func (m mediaRange) matches(contentType string) bool {
+	if m.mediaType == "*/*" || m.mediaType == contentType {
+		return true
+	}
+	if prefix, ok := strings.CutSuffix(m.mediaType, "/*"); ok {
+		return strings.HasPrefix(contentType, prefix+"/")
+	}
+	return false
+}
+
+// parseAccept returns the acceptable media ranges ordered by preference.
+// Ranges with q=0 are dropped.
+func parseAccept(accept string) []mediaRange {
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+		ranges = append(ranges, mediaRange{mediaType: mediaType, q: q})
+	}
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].q > ranges[j].q
+	})
+	return ranges
+}
+
+func NewRegistry(codecs ...Codec) *Registry {
+	r := &Registry{}
+	for _, c := range codecs {
+		r.Register(c)
+	}
+	return r
+}
+
+// GetRegistry returns the shared registry with the JSON, XML, CSV and
+// MessagePack codecs registered.
+func GetRegistry() *Registry {
+	registryOnce.Do(func() {
+		registryInstance = NewRegistry(
+			&JSONCodec{},
+			&XMLCodec{},
+			&CSVCodec{},
+			&MsgPackCodec{},
+		)
+	})
+	return registryInstance
+}
@@ -0,0 +1,23 @@
+package codec
+
+import (
+	"encoding/xml"
+	"io"
+)
+
+type XMLCodec struct{}
+
+func (x *XMLCodec) ContentType() string {
+	return "application/xml"
+}
+
+func (x *XMLCodec) Encode(w io.Writer, v interface{}) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	return xml.NewEncoder(w).Encode(v)
+}
+
+func (x *XMLCodec) Decode(r io.Reader, v interface{}) error {
+	return xml.NewDecoder(r).Decode(v)
+}
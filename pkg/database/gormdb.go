@@ -1,30 +1,97 @@
 package database
 
 import (
+	"context"
 	"crud-customer/config"
+	"crud-customer/pkg/database/migrations"
+	"crud-customer/pkg/fieldcrypt"
+	"crud-customer/pkg/metrics"
+	"crud-customer/pkg/migrate"
+	"fmt"
 	"github.com/glebarez/sqlite"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"gorm.io/gorm"
+	"gorm.io/plugin/opentelemetry/tracing"
 )
 
 type GormDB interface {
 	GetDB() *gorm.DB
-	AutoMigrate() error
-	Seed() error
+	// Migrator applies the migrations of the migrations package.
+	Migrator() migrate.Migrator
+	Ping(ctx context.Context) error
+	// Checkpoint copies the write-ahead log into the database file and
+	// truncates it. Without WAL journaling it does nothing.
+	Checkpoint(ctx context.Context) error
+	Close() error
 }
 
 type gormDB struct {
-	db  *gorm.DB
-	cfg *config.Config
+	db       *gorm.DB
+	cfg      *config.Config
+	migrator migrate.Migrator
 }
 
 func (g *gormDB) GetDB() *gorm.DB {
 	return g.db
 }
 
+func (g *gormDB) Migrator() migrate.Migrator {
+	return g.migrator
+}
+
+func (g *gormDB) Ping(ctx context.Context) error {
+	sqlDB, err := g.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+func (g *gormDB) Checkpoint(ctx context.Context) error {
+	return g.db.WithContext(ctx).Exec("PRAGMA wal_checkpoint(TRUNCATE)").Error
+}
+
+func (g *gormDB) Close() error {
+	sqlDB, err := g.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
+
 func NewGormDB(cfg *config.Config) (GormDB, error) {
-	db, err := gorm.Open(sqlite.Open(cfg.Database.File), &gorm.Config{})
+	if cfg.Database.Encryption.Enabled {
+		keyring, err := fieldcrypt.LoadKeyringFile(cfg.Database.Encryption.KeyringFile)
+		if err != nil {
+			return nil, fmt.Errorf("error loading keyring: %w", err)
+		}
+		fieldcrypt.SetKeyring(keyring)
+	}
+	db, err := gorm.Open(sqlite.Open(cfg.Database.File), &gorm.Config{Logger: newLogger()})
 	if err != nil {
 		return nil, err
 	}
-	return &gormDB{db: db, cfg: cfg}, nil
+	if err := db.Use(&metricsPlugin{}); err != nil {
+		return nil, err
+	}
+	// Statements are traced without their parameters, which may hold
+	// customer data.
+	if err := db.Use(tracing.NewPlugin(tracing.WithoutMetrics(), tracing.WithoutQueryVariables())); err != nil {
+		return nil, err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	// Only the pool of the first database opened is reported.
+	_ = metrics.Registry.Register(collectors.NewDBStatsCollector(sqlDB, "sqlite"))
+	list, err := migrate.Load(migrations.FS)
+	if err != nil {
+		return nil, fmt.Errorf("error loading migrations: %w", err)
+	}
+	migrator := migrate.NewMigrator(db, list, migrate.Options{
+		LockTimeout: cfg.Database.Migrations.LockTimeout,
+		LockExpiry:  cfg.Database.Migrations.LockExpiry,
+	})
+	return &gormDB{db: db, cfg: cfg, migrator: migrator}, nil
 }
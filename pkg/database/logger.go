@@ -0,0 +1,80 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"log/slog"
+	"time"
+)
+
+const slowQueryThreshold = 200 * time.Millisecond
+
+// slogLogger writes gorm's logs to the default slog logger, with the context
+// of the statement so that they carry its request ID. Failed statements are
+// logged as errors, slow ones as warnings and the others at debug level.
+// Statements are logged with placeholders instead of their parameters, which
+// may hold customer data.
+type slogLogger struct {
+	level logger.LogLevel
+}
+
+func newLogger() logger.Interface {
+	return &slogLogger{level: logger.Info}
+}
+
+func (l *slogLogger) LogMode(level logger.LogLevel) logger.Interface {
+	return &slogLogger{level: level}
+}
+
+func (l *slogLogger) Info(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= logger.Info {
+		slog.InfoContext(ctx, fmt.Sprintf(msg, data...))
+	}
+}
+
+func (l *slogLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= logger.Warn {
+		slog.WarnContext(ctx, fmt.Sprintf(msg, data...))
+	}
+}
+
+func (l *slogLogger) Error(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= logger.Error {
+		slog.ErrorContext(ctx, fmt.Sprintf(msg, data...))
+	}
+}
+
+func (l *slogLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if l.level <= logger.Silent {
+		return
+	}
+	elapsed := time.Since(begin)
+	level := slog.LevelDebug
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound) && l.level >= logger.Error:
+		level = slog.LevelError
+	case elapsed > slowQueryThreshold && l.level >= logger.Warn:
+		level = slog.LevelWarn
+	}
+	if !slog.Default().Enabled(ctx, level) {
+		return
+	}
+
+	sql, rows := fc()
+	attrs := []slog.Attr{
+		slog.String("sql", sql),
+		slog.Float64("elapsed_ms", float64(elapsed.Microseconds())/1000),
+		slog.Int64("rows", rows),
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	slog.LogAttrs(ctx, level, "sql", attrs...)
+}
+
+func (l *slogLogger) ParamsFilter(ctx context.Context, sql string, params ...interface{}) (string, []interface{}) {
+	return sql, nil
+}
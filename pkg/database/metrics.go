@@ -0,0 +1,59 @@
+package database
+
+import (
+	"crud-customer/pkg/metrics"
+	"gorm.io/gorm"
+	"time"
+)
+
+const metricsStartKey = "metrics:start"
+
+// metricsPlugin times every statement GORM runs, by operation and table.
+type metricsPlugin struct{}
+
+func (p *metricsPlugin) Name() string {
+	return "metrics"
+}
+
+func (p *metricsPlugin) Initialize(db *gorm.DB) error {
+	callback := db.Callback()
+	registrations := []struct {
+		operation string
+		before    func(name string, fn func(*gorm.DB)) error
+		after     func(name string, fn func(*gorm.DB)) error
+	}{
+		{"create", callback.Create().Before("gorm:create").Register, callback.Create().After("gorm:create").Register},
+		{"query", callback.Query().Before("gorm:query").Register, callback.Query().After("gorm:query").Register},
+		{"update", callback.Update().Before("gorm:update").Register, callback.Update().After("gorm:update").Register},
+		{"delete", callback.Delete().Before("gorm:delete").Register, callback.Delete().After("gorm:delete").Register},
+		{"row", callback.Row().Before("gorm:row").Register, callback.Row().After("gorm:row").Register},
+		{"raw", callback.Raw().Before("gorm:raw").Register, callback.Raw().After("gorm:raw").Register},
+	}
+	for _, registration := range registrations {
+		if err := registration.before("metrics:before_"+registration.operation, startTimer); err != nil {
+			return err
+		}
+		if err := registration.after("metrics:after_"+registration.operation, observeDuration(registration.operation)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func startTimer(db *gorm.DB) {
+	db.InstanceSet(metricsStartKey, time.Now())
+}
+
+func observeDuration(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		value, ok := db.InstanceGet(metricsStartKey)
+		if !ok {
+			return
+		}
+		table := db.Statement.Table
+		if table == "" {
+			table = "unknown"
+		}
+		metrics.DBQueryDuration.WithLabelValues(operation, table).Observe(time.Since(value.(time.Time)).Seconds())
+	}
+}
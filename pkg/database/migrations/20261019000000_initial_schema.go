@@ -0,0 +1,201 @@
+package migrations
+
+import (
+	"crud-customer/internal/entity"
+	"crud-customer/pkg/fieldcrypt"
+	"crud-customer/pkg/migrate"
+	"fmt"
+	"gorm.io/gorm"
+	"slices"
+	"strings"
+)
+
+type column struct {
+	name       string
+	definition string
+}
+
+type table struct {
+	name    string
+	columns []column
+}
+
+// initialTables is the schema autoMigrate created before versioned
+// migrations. Tables it created in earlier releases lack the columns added
+// since; those are all nullable or have a default.
+var initialTables = []table{
+	{"api_keys", []column{
+		{"id", "integer PRIMARY KEY AUTOINCREMENT NOT NULL"},
+		{"name", "text NOT NULL"},
+		{"tenant_id", "text NOT NULL DEFAULT ''"},
+		{"prefix", "text NOT NULL"},
+		{"hash", "text NOT NULL"},
+		{"scopes", "text"},
+		{"expires_at", "datetime"},
+		{"last_used_at", "datetime"},
+		{"revoked_at", "datetime"},
+		{"created_at", "datetime NOT NULL"},
+	}},
+	{"audit_entries", []column{
+		{"id", "integer PRIMARY KEY AUTOINCREMENT NOT NULL"},
+		{"tenant_id", "text NOT NULL DEFAULT 'default'"},
+		{"customer_id", "integer NOT NULL"},
+		{"action", "text NOT NULL"},
+		{"actor", "text NOT NULL"},
+		{"request_id", "text NOT NULL"},
+		{"source_ip", "text NOT NULL"},
+		{"diff", "text NOT NULL"},
+		{"created_at", "datetime NOT NULL"},
+		{"prev_hash", "text NOT NULL"},
+		{"hash", "text NOT NULL"},
+	}},
+	{"customers", []column{
+		{"id", "integer PRIMARY KEY AUTOINCREMENT NOT NULL"},
+		{"tenant_id", "text NOT NULL DEFAULT 'default'"},
+		{"name", "text NOT NULL"},
+		{"name_index", "text"},
+		{"age", "integer NOT NULL"},
+		{"external_source", "text"},
+		{"external_id", "text"},
+	}},
+	{"outbox_events", []column{
+		{"id", "integer PRIMARY KEY AUTOINCREMENT NOT NULL"},
+		{"tenant_id", "text NOT NULL DEFAULT 'default'"},
+		{"aggregate_type", "text NOT NULL"},
+		{"aggregate_id", "integer NOT NULL"},
+		{"event_type", "text NOT NULL"},
+		{"payload", "text NOT NULL"},
+		{"created_at", "datetime NOT NULL"},
+		{"dispatched_at", "datetime"},
+		{"attempts", "integer NOT NULL DEFAULT 0"},
+		{"last_error", "text"},
+	}},
+}
+
+var initialIndexes = []string{
+	"CREATE UNIQUE INDEX IF NOT EXISTS idx_api_keys_prefix ON api_keys(prefix)",
+	"CREATE UNIQUE INDEX IF NOT EXISTS idx_audit_entries_hash ON audit_entries(hash)",
+	"CREATE INDEX IF NOT EXISTS idx_audit_entries_customer ON audit_entries(tenant_id, customer_id)",
+	"CREATE INDEX IF NOT EXISTS idx_customers_name_index ON customers(name_index)",
+	"CREATE UNIQUE INDEX IF NOT EXISTS idx_customers_tenant_external ON customers(tenant_id, external_source, external_id)",
+	"CREATE INDEX IF NOT EXISTS idx_customers_tenant_id ON customers(tenant_id)",
+	"CREATE INDEX IF NOT EXISTS idx_customers_id ON customers(id)",
+	"CREATE INDEX IF NOT EXISTS idx_outbox_events_dispatched_at ON outbox_events(dispatched_at)",
+	"CREATE INDEX IF NOT EXISTS idx_outbox_events_aggregate ON outbox_events(aggregate_type, aggregate_id)",
+	// audit_entries is append-only. Only diff may be rewritten, which
+	// rotate-keys does to re-encrypt it; the hash chain covers its
+	// plaintext, so tampering with it is still detected.
+	`CREATE TRIGGER IF NOT EXISTS audit_entries_no_delete BEFORE DELETE ON audit_entries
+	BEGIN SELECT RAISE(ABORT, 'audit_entries is append-only'); END`,
+	`CREATE TRIGGER IF NOT EXISTS audit_entries_no_update
+	BEFORE UPDATE OF id, tenant_id, customer_id, action, actor, request_id, source_ip, created_at, prev_hash, hash ON audit_entries
+	BEGIN SELECT RAISE(ABORT, 'audit_entries is append-only'); END`,
+}
+
+const backfillBatchSize = 500
+
+func init() {
+	migrate.Register(20261019000000, "initial_schema", func(tx *gorm.DB) error {
+		for _, t := range initialTables {
+			if err := createOrUpgradeTable(tx, t); err != nil {
+				return err
+			}
+		}
+		for _, statement := range initialIndexes {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+		return backfillNameIndex(tx)
+	}, func(tx *gorm.DB) error {
+		for i := len(initialTables) - 1; i >= 0; i-- {
+			if err := tx.Exec("DROP TABLE IF EXISTS " + initialTables[i].name).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// createOrUpgradeTable creates t, or rebuilds it when its columns differ.
+// Adding the missing columns would append them, but encrypted values are
+// bound to tenant_id, which must be scanned before them, so the columns are
+// kept in the order of t.
+func createOrUpgradeTable(tx *gorm.DB, t table) error {
+	if !tx.Migrator().HasTable(t.name) {
+		return createTable(tx, t.name, t)
+	}
+	columnTypes, err := tx.Migrator().ColumnTypes(t.name)
+	if err != nil {
+		return err
+	}
+	existing := make([]string, len(columnTypes))
+	for i, columnType := range columnTypes {
+		existing[i] = columnType.Name()
+	}
+	if slices.Equal(existing, t.columnNames()) {
+		return nil
+	}
+	for _, name := range existing {
+		if !slices.Contains(t.columnNames(), name) {
+			return fmt.Errorf("error upgrading table %s: unexpected column %s", t.name, name)
+		}
+	}
+
+	// SQLite cannot reorder columns: the rows are copied to a new table,
+	// which then takes the place of the old one.
+	upgraded := t.name + "__upgraded"
+	if err := createTable(tx, upgraded, t); err != nil {
+		return err
+	}
+	columns := strings.Join(existing, ", ")
+	for _, statement := range []string{
+		fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s", upgraded, columns, columns, t.name),
+		// IDs of deleted rows are not handed out again.
+		fmt.Sprintf(`UPDATE sqlite_sequence SET seq = max(seq, coalesce((SELECT seq FROM sqlite_sequence WHERE name = '%s'), 0))
+		WHERE name = '%s'`, t.name, upgraded),
+		"DROP TABLE " + t.name,
+		fmt.Sprintf("ALTER TABLE %s RENAME TO %s", upgraded, t.name),
+	} {
+		if err := tx.Exec(statement).Error; err != nil {
+			return fmt.Errorf("error upgrading table %s: %w", t.name, err)
+		}
+	}
+	return nil
+}
+
+func createTable(tx *gorm.DB, name string, t table) error {
+	definitions := make([]string, len(t.columns))
+	for i, c := range t.columns {
+		definitions[i] = c.name + " " + c.definition
+	}
+	return tx.Exec(fmt.Sprintf("CREATE TABLE %s (%s)", name, strings.Join(definitions, ", "))).Error
+}
+
+func (t table) columnNames() []string {
+	names := make([]string, len(t.columns))
+	for i, c := range t.columns {
+		names[i] = c.name
+	}
+	return names
+}
+
+// backfillNameIndex computes the blind index of the customers written
+// without one, before encryption was enabled or before the column existed.
+// Without a keyring there is no index to compute. Queries are unscoped, as
+// deleted_at is only added by a later migration.
+func backfillNameIndex(tx *gorm.DB) error {
+	if fieldcrypt.GetKeyring() == nil {
+		return nil
+	}
+	var customers []*entity.Customer
+	return tx.Unscoped().Where("name_index IS NULL").FindInBatches(&customers, backfillBatchSize, func(batch *gorm.DB, _ int) error {
+		for _, customer := range customers {
+			customer.UpdateNameIndex()
+			if err := tx.Unscoped().Model(customer).UpdateColumn("name_index", customer.NameIndex).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Error
+}
@@ -0,0 +1,4 @@
+DROP INDEX idx_outbox_events_dead_lettered_at;
+DROP INDEX idx_outbox_events_next_attempt_at;
+ALTER TABLE outbox_events DROP COLUMN dead_lettered_at;
+ALTER TABLE outbox_events DROP COLUMN next_attempt_at;
@@ -0,0 +1,6 @@
+-- Failed outbox events are retried with a backoff and dead-lettered after
+-- outbox.maxAttempts.
+ALTER TABLE outbox_events ADD COLUMN next_attempt_at datetime;
+ALTER TABLE outbox_events ADD COLUMN dead_lettered_at datetime;
+CREATE INDEX idx_outbox_events_next_attempt_at ON outbox_events(next_attempt_at);
+CREATE INDEX idx_outbox_events_dead_lettered_at ON outbox_events(dead_lettered_at);
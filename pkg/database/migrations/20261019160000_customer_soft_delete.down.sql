@@ -0,0 +1,4 @@
+-- Without deleted_at, deleted customers would come back: they are removed.
+DELETE FROM customers WHERE deleted_at IS NOT NULL;
+DROP INDEX idx_customers_deleted_at;
+ALTER TABLE customers DROP COLUMN deleted_at;
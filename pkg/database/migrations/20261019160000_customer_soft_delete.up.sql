@@ -0,0 +1,3 @@
+-- Deleted customers are kept, so they can be restored.
+ALTER TABLE customers ADD COLUMN deleted_at datetime;
+CREATE INDEX idx_customers_deleted_at ON customers(deleted_at);
@@ -0,0 +1,10 @@
+// Package migrations holds the schema migrations of the database: SQL files
+// named <version>_<name>.up.sql and <version>_<name>.down.sql, embedded in
+// the binary, and Go migrations registered with migrate.Register. New ones
+// are created with `migrate create`.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
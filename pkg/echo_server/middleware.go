@@ -1,28 +1,407 @@
 package echo_server
 
 import (
+	"crud-customer/pkg/auth"
+	"crud-customer/pkg/metrics"
+	"crud-customer/pkg/ratelimit"
+	"crud-customer/pkg/redact"
+	"crud-customer/pkg/reqctx"
+	"crud-customer/pkg/tenant"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/labstack/echo/otelecho"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
+// Keys under which GetAuthMiddleware stores the authenticated caller on the
+// echo context.
+const (
+	ContextKeyPrincipal = "principal"
+	ContextKeyClaims    = "claims"
+	ContextKeyTenant    = "tenant_id"
+)
+
+var streamingRoutes sync.Map
+
+// RegisterStreamingRoute excludes route from the timeout middleware, which
+// buffers the whole response in memory before writing it.
+func RegisterStreamingRoute(route *echo.Route) {
+	streamingRoutes.Store(route.Method+" "+route.Path, true)
+}
+
+func isStreamingRoute(c echo.Context) bool {
+	_, ok := streamingRoutes.Load(c.Request().Method + " " + c.Path())
+	return ok
+}
+
+var sensitiveQueryParams sync.Map
+
+// RegisterSensitiveQueryParams hides the values of the given query
+// parameters in the request log.
+func RegisterSensitiveQueryParams(params ...string) {
+	for _, param := range params {
+		sensitiveQueryParams.Store(param, true)
+	}
+}
+
+// GetLoggerMiddleware logs every request as a JSON record carrying the
+// request ID, with the values of sensitive query parameters masked in the
+// URI. Server errors are logged as errors, client errors as warnings.
+func GetLoggerMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			err := next(c)
+
+			req := c.Request()
+			status := responseStatus(c, err)
+			level := slog.LevelInfo
+			switch {
+			case status >= http.StatusInternalServerError:
+				level = slog.LevelError
+			case status >= http.StatusBadRequest:
+				level = slog.LevelWarn
+			}
+			attrs := []slog.Attr{
+				slog.String("remote_ip", c.RealIP()),
+				slog.String("host", req.Host),
+				slog.String("method", req.Method),
+				slog.String("uri", maskedURI(req)),
+				slog.String("route", c.Path()),
+				slog.String("user_agent", req.UserAgent()),
+				slog.Int("status", status),
+				slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+				slog.Int64("bytes_in", max(req.ContentLength, 0)),
+				slog.Int64("bytes_out", c.Response().Size),
+			}
+			if err != nil {
+				attrs = append(attrs, slog.String("error", err.Error()))
+			}
+			slog.LogAttrs(req.Context(), level, "request", attrs...)
+			return err
+		}
+	}
+}
+
+// responseStatus returns the status of the response to c, or the status
+// echo's error handler will send for err.
+func responseStatus(c echo.Context, err error) int {
+	if err == nil {
+		return c.Response().Status
+	}
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.Code
+	}
+	return http.StatusInternalServerError
+}
+
+func maskedURI(req *http.Request) string {
+	u, err := url.ParseRequestURI(req.RequestURI)
+	if err != nil || u.RawQuery == "" {
+		return req.RequestURI
+	}
+	query := u.Query()
+	masked := false
+	for param, values := range query {
+		if _, ok := sensitiveQueryParams.Load(param); !ok {
+			continue
+		}
+		for i, value := range values {
+			values[i] = redact.Mask(redact.MaskFull, value)
+		}
+		masked = true
+	}
+	if !masked {
+		return req.RequestURI
+	}
+	u.RawQuery = query.Encode()
+	return u.RequestURI()
+}
+
+// GetMetricsMiddleware records the count and latency of requests, labelled
+// by the name of the matched route.
+func GetMetricsMiddleware() echo.MiddlewareFunc {
+	var names sync.Map
+	routeName := func(c echo.Context) string {
+		if c.Path() == "" {
+			return "unmatched"
+		}
+		key := c.Request().Method + " " + c.Path()
+		if name, ok := names.Load(key); ok {
+			return name.(string)
+		}
+		name := c.Path()
+		for _, route := range c.Echo().Routes() {
+			if route.Method == c.Request().Method && route.Path == c.Path() && route.Name != "" {
+				name = route.Name
+				break
+			}
+		}
+		names.Store(key, name)
+		return name
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			err := next(c)
+
+			route := routeName(c)
+			method := c.Request().Method
+			metrics.HTTPRequestsTotal.WithLabelValues(route, method, strconv.Itoa(responseStatus(c, err))).Inc()
+			metrics.HTTPRequestDuration.WithLabelValues(route, method).Observe(time.Since(start).Seconds())
+			return err
+		}
+	}
+}
+
+// GetTracingMiddleware starts a span for every request, continuing the
+// trace of the W3C traceparent header when present. Health probes are not
+// traced.
+func GetTracingMiddleware(serviceName string) echo.MiddlewareFunc {
+	return otelecho.Middleware(serviceName, otelecho.WithSkipper(func(c echo.Context) bool {
+		return c.Path() == "/healthz" || c.Path() == "/readyz"
+	}))
+}
+
 func GetTimeOutMiddleware(timeout time.Duration) echo.MiddlewareFunc {
 	return middleware.TimeoutWithConfig(middleware.TimeoutConfig{
-		Skipper:      middleware.DefaultSkipper,
+		Skipper:      isStreamingRoute,
 		ErrorMessage: "Error: Request timeout.",
 		Timeout:      timeout * time.Second,
 	})
 }
 
-func GetCORSMiddleware(allowOrigins []string) echo.MiddlewareFunc {
+func GetCORSMiddleware(allowOrigins []string, allowHeaders ...string) echo.MiddlewareFunc {
 	return middleware.CORSWithConfig(middleware.CORSConfig{
-		Skipper:      middleware.DefaultSkipper,
-		AllowOrigins: allowOrigins,
-		AllowMethods: []string{echo.GET, echo.POST, echo.PUT, echo.PATCH, echo.DELETE},
-		AllowHeaders: []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept},
+		Skipper:       middleware.DefaultSkipper,
+		AllowOrigins:  allowOrigins,
+		AllowMethods:  []string{echo.GET, echo.POST, echo.PUT, echo.PATCH, echo.DELETE},
+		AllowHeaders:  append([]string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization}, allowHeaders...),
+		ExposeHeaders: []string{"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", echo.HeaderRetryAfter},
 	})
 }
 
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// GetRequestIDMiddleware keeps the X-Request-ID of the caller when it is a
+// plausible ID, so that a request can be followed across services, and
+// generates one otherwise. The ID is sent back in the response.
+func GetRequestIDMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			requestID := c.Request().Header.Get(echo.HeaderXRequestID)
+			if !requestIDPattern.MatchString(requestID) {
+				requestID = newRequestID()
+			}
+			c.Response().Header().Set(echo.HeaderXRequestID, requestID)
+			return next(c)
+		}
+	}
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// GetRequestContextMiddleware copies the request ID, set by the request ID
+// middleware, and the client IP into the request context, so layers below
+// the handlers can record and log them.
+func GetRequestContextMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			ctx := reqctx.WithRequestID(c.Request().Context(), c.Response().Header().Get(echo.HeaderXRequestID))
+			ctx = reqctx.WithSourceIP(ctx, c.RealIP())
+			c.SetRequest(c.Request().WithContext(ctx))
+			return next(c)
+		}
+	}
+}
+
 func GetBodyLimitMiddleware(bodyLimit string) echo.MiddlewareFunc {
 	return middleware.BodyLimit(bodyLimit)
 }
+
+// GetAuthMiddleware tries each authenticator in turn and stores the first
+// principal found in the request context. Requests without any recognised
+// credentials, or with invalid ones, are rejected with 401.
+func GetAuthMiddleware(authenticators ...auth.Authenticator) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			for _, authenticator := range authenticators {
+				principal, err := authenticator.Authenticate(c.Request())
+				if errors.Is(err, auth.ErrNoCredentials) {
+					continue
+				}
+				if errors.Is(err, auth.ErrInvalidCredentials) {
+					slog.DebugContext(c.Request().Context(), "rejected credentials", "error", err)
+					return echo.NewHTTPError(http.StatusUnauthorized, "invalid credentials")
+				}
+				if err != nil {
+					return echo.NewHTTPError(http.StatusInternalServerError, "error authenticating request").SetInternal(err)
+				}
+				c.SetRequest(c.Request().WithContext(auth.WithPrincipal(c.Request().Context(), principal)))
+				c.Set(ContextKeyPrincipal, principal)
+				if principal.Claims != nil {
+					c.Set(ContextKeyClaims, principal.Claims)
+				}
+				return next(c)
+			}
+			return echo.NewHTTPError(http.StatusUnauthorized, "missing credentials")
+		}
+	}
+}
+
+// GetTenantMiddleware resolves the tenant of each request and stores it in
+// the request context, where the repositories pick it up. It must run after
+// GetAuthMiddleware so tenants bound to credentials are enforced. Without
+// authentication there are no credentials to bind, and requests may name
+// any tenant.
+func GetTenantMiddleware(resolver *tenant.Resolver) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			credentialTenant := tenant.AnyTenant
+			if principal, ok := auth.PrincipalFromContext(c.Request().Context()); ok && principal != nil {
+				credentialTenant = principal.TenantID
+			}
+			tenantID, err := resolver.Resolve(c.Request(), credentialTenant)
+			if errors.Is(err, tenant.ErrTenantMismatch) {
+				slog.WarnContext(c.Request().Context(), "denied request", "method", c.Request().Method, "route", c.Path(), "error", err)
+				return echo.NewHTTPError(http.StatusForbidden, "tenant does not match credentials")
+			}
+			if err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, "missing or invalid tenant")
+			}
+			c.SetRequest(c.Request().WithContext(tenant.WithTenant(c.Request().Context(), tenantID)))
+			c.Set(ContextKeyTenant, tenantID)
+			return next(c)
+		}
+	}
+}
+
+// GetRateLimitMiddleware limits requests per client and group, keyed by the
+// authenticated principal or, for anonymous requests, the client IP. The
+// limit of the group is looked up on every request, and requests of groups
+// without one are let through. Every limited response carries the
+// RateLimit-* headers; rejected requests get 429 with Retry-After. If the
+// store fails the request is let through.
+func GetRateLimitMiddleware(store ratelimit.Store, limits *ratelimit.Limits, group string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			limit, group, ok := limits.Get(group)
+			if !ok {
+				return next(c)
+			}
+			client := "ip:" + c.RealIP()
+			if principal, ok := auth.PrincipalFromContext(c.Request().Context()); ok && principal != nil {
+				client = principal.Subject
+			}
+			result, err := store.Take(c.Request().Context(), group+"|"+client, limit)
+			if err != nil {
+				slog.ErrorContext(c.Request().Context(), "error checking rate limit", "error", err)
+				return next(c)
+			}
+
+			header := c.Response().Header()
+			header.Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+			header.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+			header.Set("RateLimit-Reset", ceilSeconds(result.ResetAfter))
+			if !result.Allowed {
+				header.Set("Retry-After", ceilSeconds(result.RetryAfter))
+				slog.WarnContext(c.Request().Context(), "rate limited", "method", c.Request().Method, "route", c.Path(), "client", client, "group", group)
+				return echo.NewHTTPError(http.StatusTooManyRequests, "rate limit exceeded")
+			}
+			return next(c)
+		}
+	}
+}
+
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
+
+// isAllowed reports whether principal holds permission. Scoped principals
+// need it among their scopes; with RBAC, every principal needs it among the
+// permissions of its roles and scopes. A nil authorizer means RBAC is
+// disabled.
+func isAllowed(authorizer *auth.Authorizer, principal *auth.Principal, permission string) bool {
+	if principal != nil && principal.IsScoped() && !principal.HasScope(permission) {
+		return false
+	}
+	return authorizer == nil || authorizer.IsAllowed(principal, permission)
+}
+
+// GetPermissionMiddleware rejects requests whose principal lacks permission
+// with 403, see isAllowed.
+func GetPermissionMiddleware(authorizer *auth.Authorizer, permission string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			principal, _ := auth.PrincipalFromContext(c.Request().Context())
+			if isAllowed(authorizer, principal, permission) {
+				return next(c)
+			}
+
+			subject := "anonymous"
+			if principal != nil {
+				subject = principal.Subject
+			}
+			slog.WarnContext(c.Request().Context(), "denied request", "method", c.Request().Method, "route", c.Path(), "subject", subject, "missing_permission", permission)
+			return c.JSON(http.StatusForbidden, map[string]interface{}{
+				"status_code":        http.StatusForbidden,
+				"success":            false,
+				"message":            "missing permission: " + permission,
+				"missing_permission": permission,
+			})
+		}
+	}
+}
+
+// GetRedactionMiddleware marks the requests whose principal lacks
+// permission, see isAllowed, so that PII in their responses is masked.
+func GetRedactionMiddleware(authorizer *auth.Authorizer, permission string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			principal, _ := auth.PrincipalFromContext(c.Request().Context())
+			if !isAllowed(authorizer, principal, permission) {
+				c.SetRequest(c.Request().WithContext(redact.WithMasking(c.Request().Context())))
+			}
+			return next(c)
+		}
+	}
+}
+
+// reloadableMiddleware runs the middleware last stored in it, so that it
+// can be replaced while serving.
+type reloadableMiddleware struct {
+	current atomic.Pointer[echo.MiddlewareFunc]
+}
+
+func newReloadableMiddleware(m echo.MiddlewareFunc) *reloadableMiddleware {
+	r := &reloadableMiddleware{}
+	r.Store(m)
+	return r
+}
+
+func (r *reloadableMiddleware) Store(m echo.MiddlewareFunc) {
+	r.current.Store(&m)
+}
+
+func (r *reloadableMiddleware) Middleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		return (*r.current.Load())(next)(c)
+	}
+}
@@ -0,0 +1,194 @@
+package echo_server
+
+import (
+	"crud-customer/pkg/auth"
+	"crud-customer/pkg/redact"
+	"crud-customer/pkg/reqctx"
+	"crud-customer/pkg/tenant"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetRequestContextMiddleware(t *testing.T) {
+	testCases := []struct {
+		name           string
+		trustedProxies []string
+		want           string
+	}{
+		{name: "client headers ignored", want: "10.0.0.1"},
+		{name: "trusted proxy", trustedProxies: []string{"10.0.0.0/8"}, want: "1.2.3.4"},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			app := echo.New()
+			trustedProxies, err := ParseTrustedProxies(tt.trustedProxies)
+			assert.NoError(t, err)
+			app.IPExtractor = NewIPExtractor(trustedProxies)
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = "10.0.0.1:1234"
+			req.Header.Set(echo.HeaderXForwardedFor, "1.2.3.4")
+			req.Header.Set(echo.HeaderXRealIP, "6.6.6.6")
+			rec := httptest.NewRecorder()
+			rec.Header().Set(echo.HeaderXRequestID, "req-1")
+			c := app.NewContext(req, rec)
+
+			var sourceIP, requestID string
+			err = GetRequestContextMiddleware()(func(c echo.Context) error {
+				sourceIP = reqctx.SourceIPFromContext(c.Request().Context())
+				requestID = reqctx.RequestIDFromContext(c.Request().Context())
+				return nil
+			})(c)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, sourceIP)
+			assert.Equal(t, "req-1", requestID)
+		})
+	}
+}
+
+func TestGetPermissionMiddleware(t *testing.T) {
+	authorizer := auth.NewAuthorizer(map[string][]string{"support": {"customers:read"}})
+	testCases := []struct {
+		name       string
+		authorizer *auth.Authorizer
+		principal  *auth.Principal
+		wantStatus int
+	}{
+		{
+			name:       "api key with scope",
+			principal:  &auth.Principal{Type: auth.PrincipalTypeAPIKey, Subject: "api_key:1", Scopes: []string{"customers:read"}},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "api key with wildcard scope",
+			principal:  &auth.Principal{Type: auth.PrincipalTypeAPIKey, Subject: "api_key:1", Scopes: []string{"customers:*"}},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "api key without scope",
+			principal:  &auth.Principal{Type: auth.PrincipalTypeAPIKey, Subject: "api_key:1", Scopes: []string{"audit:read"}},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "api key without scope with rbac",
+			authorizer: authorizer,
+			principal:  &auth.Principal{Type: auth.PrincipalTypeAPIKey, Subject: "api_key:1", Roles: []string{"support"}},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "jwt without rbac",
+			principal:  &auth.Principal{Type: auth.PrincipalTypeJWT, Subject: "user"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "jwt with role",
+			authorizer: authorizer,
+			principal:  &auth.Principal{Type: auth.PrincipalTypeJWT, Subject: "user", Roles: []string{"support"}},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "jwt without role",
+			authorizer: authorizer,
+			principal:  &auth.Principal{Type: auth.PrincipalTypeJWT, Subject: "user"},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "anonymous without rbac",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "anonymous with rbac",
+			authorizer: authorizer,
+			wantStatus: http.StatusForbidden,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.principal != nil {
+				req = req.WithContext(auth.WithPrincipal(req.Context(), tt.principal))
+			}
+			rec := httptest.NewRecorder()
+			c := echo.New().NewContext(req, rec)
+
+			err := GetPermissionMiddleware(tt.authorizer, "customers:read")(func(c echo.Context) error {
+				return c.NoContent(http.StatusOK)
+			})(c)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, rec.Code)
+		})
+	}
+}
+
+func TestGetRedactionMiddleware(t *testing.T) {
+	testCases := []struct {
+		name      string
+		principal *auth.Principal
+		want      bool
+	}{
+		{name: "api key with pii scope", principal: &auth.Principal{Type: auth.PrincipalTypeAPIKey, Scopes: []string{"customers:pii"}}, want: false},
+		{name: "api key without pii scope", principal: &auth.Principal{Type: auth.PrincipalTypeAPIKey, Scopes: []string{"customers:read"}}, want: true},
+		{name: "jwt without rbac", principal: &auth.Principal{Type: auth.PrincipalTypeJWT}, want: false},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req = req.WithContext(auth.WithPrincipal(req.Context(), tt.principal))
+			c := echo.New().NewContext(req, httptest.NewRecorder())
+
+			var masking bool
+			err := GetRedactionMiddleware(nil, "customers:pii")(func(c echo.Context) error {
+				masking = redact.MaskingFromContext(c.Request().Context())
+				return nil
+			})(c)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, masking)
+		})
+	}
+}
+
+func TestGetTenantMiddleware(t *testing.T) {
+	resolver := tenant.NewResolver(tenant.ResolverConfig{Header: "X-Tenant-ID", Default: tenant.DefaultTenant})
+	testCases := []struct {
+		name       string
+		principal  *auth.Principal
+		header     string
+		wantStatus int
+		want       string
+	}{
+		{name: "without authentication", header: "acme", wantStatus: http.StatusOK, want: "acme"},
+		{name: "bound key", principal: &auth.Principal{Subject: "api_key:1", TenantID: "acme"}, wantStatus: http.StatusOK, want: "acme"},
+		{name: "unbound key", principal: &auth.Principal{Subject: "api_key:1"}, wantStatus: http.StatusOK, want: "default"},
+		{name: "unbound key naming a tenant", principal: &auth.Principal{Subject: "api_key:1"}, header: "acme", wantStatus: http.StatusForbidden},
+		{name: "cross-tenant key", principal: &auth.Principal{Subject: "api_key:1", TenantID: tenant.AnyTenant}, header: "acme", wantStatus: http.StatusOK, want: "acme"},
+		{name: "invalid tenant", principal: &auth.Principal{Subject: "api_key:1", TenantID: tenant.AnyTenant}, header: "ACME", wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.principal != nil {
+				req = req.WithContext(auth.WithPrincipal(req.Context(), tt.principal))
+			}
+			if tt.header != "" {
+				req.Header.Set("X-Tenant-ID", tt.header)
+			}
+			c := echo.New().NewContext(req, httptest.NewRecorder())
+
+			got := ""
+			err := GetTenantMiddleware(resolver)(func(c echo.Context) error {
+				got = tenant.FromContext(c.Request().Context())
+				return c.NoContent(http.StatusOK)
+			})(c)
+			if tt.wantStatus == http.StatusOK {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+				return
+			}
+			var httpErr *echo.HTTPError
+			assert.ErrorAs(t, err, &httpErr)
+			assert.Equal(t, tt.wantStatus, httpErr.Code)
+		})
+	}
+}
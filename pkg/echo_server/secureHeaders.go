@@ -0,0 +1,216 @@
+package echo_server
+
+import (
+	"context"
+	"crud-customer/pkg/ratelimit"
+	"encoding/json"
+	"github.com/labstack/echo/v4"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CSPReportPath receives the Content-Security-Policy violation reports of
+// browsers. The CSP of every preset points its report-uri here.
+const CSPReportPath = "/csp-report"
+
+// Secure header presets, picked per environment.
+const (
+	SecureHeadersPresetDevelopment = "development"
+	SecureHeadersPresetProduction  = "production"
+)
+
+var secureHeadersPresets = map[string]map[string]string{
+	// Development leaves out HSTS, which would pin localhost to HTTPS, and
+	// only reports CSP violations.
+	SecureHeadersPresetDevelopment: {
+		"X-Content-Type-Options":              "nosniff",
+		"X-Frame-Options":                     "SAMEORIGIN",
+		"Referrer-Policy":                     "strict-origin-when-cross-origin",
+		"Content-Security-Policy-Report-Only": "default-src 'self'; report-uri " + CSPReportPath,
+	},
+	// Production denies everything a JSON API does not need.
+	SecureHeadersPresetProduction: {
+		"Strict-Transport-Security":    "max-age=31536000; includeSubDomains",
+		"X-Content-Type-Options":       "nosniff",
+		"X-Frame-Options":              "DENY",
+		"Referrer-Policy":              "no-referrer",
+		"Cross-Origin-Opener-Policy":   "same-origin",
+		"Cross-Origin-Resource-Policy": "same-origin",
+		"Content-Security-Policy":      "default-src 'none'; frame-ancestors 'none'; report-uri " + CSPReportPath,
+	},
+}
+
+// SecureHeaders returns the headers of preset with overrides applied. An
+// override with an empty value drops the header.
+func SecureHeaders(preset string, overrides map[string]string) (map[string]string, bool) {
+	presetHeaders, ok := secureHeadersPresets[preset]
+	if !ok {
+		return nil, false
+	}
+	headers := make(map[string]string, len(presetHeaders))
+	for name, value := range presetHeaders {
+		headers[name] = value
+	}
+	for name, value := range overrides {
+		name = http.CanonicalHeaderKey(name)
+		if value == "" {
+			delete(headers, name)
+			continue
+		}
+		headers[name] = value
+	}
+	return headers, true
+}
+
+// GetSecureHeadersMiddleware sets headers on every response.
+// Strict-Transport-Security is only sent over HTTPS, where browsers honour
+// it. X-Forwarded-Proto only tells so on connections from trustedProxies,
+// as any client can set it.
+func GetSecureHeadersMiddleware(headers map[string]string, trustedProxies []*net.IPNet) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			isHTTPS := c.IsTLS() || (c.Request().Header.Get(echo.HeaderXForwardedProto) == "https" &&
+				isTrustedProxy(c.Request(), trustedProxies))
+			for name, value := range headers {
+				if name == echo.HeaderStrictTransportSecurity && !isHTTPS {
+					continue
+				}
+				c.Response().Header().Set(name, value)
+			}
+			return next(c)
+		}
+	}
+}
+
+// cspReport is a violation in either the legacy report-uri format or the
+// Reporting API format.
+type cspReport struct {
+	DocumentURI        string `json:"document-uri"`
+	DocumentURL        string `json:"documentURL"`
+	ViolatedDirective  string `json:"violated-directive"`
+	EffectiveDirective string `json:"effectiveDirective"`
+	BlockedURI         string `json:"blocked-uri"`
+	BlockedURL         string `json:"blockedURL"`
+}
+
+const (
+	maxCSPReportSize = 64 << 10
+	// maxCSPReportsPerRequest bounds the reports logged from one request
+	// of the Reporting API, which batches them.
+	maxCSPReportsPerRequest = 10
+	// maxCSPReportsLogged bounds the reports logged per cspLogWindow across
+	// all clients; the rest are counted and summed up in one line.
+	maxCSPReportsLogged = 100
+	cspLogWindow        = time.Minute
+)
+
+// cspReportLimit is the number of reports each client may post. Browsers
+// send one per violation, so a page breaking its policy stays well below.
+var cspReportLimit = ratelimit.Limit{Requests: 30, Period: time.Minute}
+
+// NewCSPReportLimitMiddleware limits the reports each client may post,
+// whether or not rate limiting is enabled for the API: reports are
+// unauthenticated, so anyone can post them.
+func NewCSPReportLimitMiddleware() echo.MiddlewareFunc {
+	store, _ := ratelimit.NewMemoryStore(nil)
+	return GetRateLimitMiddleware(store, ratelimit.NewLimits("csp", map[string]ratelimit.Limit{"csp": cspReportLimit}), "csp")
+}
+
+// cspReportHandler logs CSP reports, capping the reports logged per window.
+type cspReportHandler struct {
+	mu          sync.Mutex
+	now         func() time.Time
+	windowStart time.Time
+	logged      int
+	suppressed  int
+}
+
+// allow reports whether a report may be logged. The first report of a new
+// window logs how many the window before suppressed.
+func (h *cspReportHandler) allow(ctx context.Context) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if now := h.now(); now.Sub(h.windowStart) >= cspLogWindow {
+		if h.suppressed > 0 {
+			slog.WarnContext(ctx, "csp violations not logged, too many reports", "count", h.suppressed, "window", cspLogWindow)
+		}
+		h.windowStart, h.logged, h.suppressed = now, 0, 0
+	}
+	if h.logged >= maxCSPReportsLogged {
+		h.suppressed++
+		return false
+	}
+	h.logged++
+	return true
+}
+
+// NewCSPReportHandler returns the handler logging the CSP violation reports
+// posted by browsers. Reports are unauthenticated, so only a few fields of
+// each are logged, and only so many per minute.
+func NewCSPReportHandler() echo.HandlerFunc {
+	return (&cspReportHandler{now: time.Now}).handle
+}
+
+func (h *cspReportHandler) handle(c echo.Context) error {
+	body, err := io.ReadAll(io.LimitReader(c.Request().Body, maxCSPReportSize))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid csp report")
+	}
+
+	var reports []cspReport
+	var legacy struct {
+		Report *cspReport `json:"csp-report"`
+	}
+	var batch []struct {
+		Type string    `json:"type"`
+		Body cspReport `json:"body"`
+	}
+	switch {
+	case json.Unmarshal(body, &legacy) == nil && legacy.Report != nil:
+		reports = append(reports, *legacy.Report)
+	case json.Unmarshal(body, &batch) == nil:
+		for _, report := range batch {
+			if report.Type == "csp-violation" {
+				reports = append(reports, report.Body)
+			}
+		}
+	default:
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid csp report")
+	}
+
+	if len(reports) > maxCSPReportsPerRequest {
+		reports = reports[:maxCSPReportsPerRequest]
+	}
+	for _, report := range reports {
+		if !h.allow(c.Request().Context()) {
+			break
+		}
+		slog.WarnContext(c.Request().Context(), "csp violation",
+			"document_uri", truncate(firstNonEmpty(report.DocumentURI, report.DocumentURL)),
+			"directive", truncate(firstNonEmpty(report.ViolatedDirective, report.EffectiveDirective)),
+			"blocked_uri", truncate(firstNonEmpty(report.BlockedURI, report.BlockedURL)))
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+func truncate(value string) string {
+	const maxLength = 256
+	if len(value) <= maxLength {
+		return value
+	}
+	return strings.ToValidUTF8(value[:maxLength], "") + "..."
+}
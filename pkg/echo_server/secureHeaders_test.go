@@ -0,0 +1,211 @@
+package echo_server
+
+import (
+	"bytes"
+	"crypto/tls"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSecureHeaders(t *testing.T) {
+	testCases := []struct {
+		name      string
+		preset    string
+		overrides map[string]string
+		want      map[string]string
+		wantOK    bool
+	}{
+		{
+			name:   "production",
+			preset: SecureHeadersPresetProduction,
+			want:   secureHeadersPresets[SecureHeadersPresetProduction],
+			wantOK: true,
+		},
+		{
+			name:   "development",
+			preset: SecureHeadersPresetDevelopment,
+			want: map[string]string{
+				"X-Content-Type-Options":              "nosniff",
+				"X-Frame-Options":                     "SAMEORIGIN",
+				"Referrer-Policy":                     "strict-origin-when-cross-origin",
+				"Content-Security-Policy-Report-Only": "default-src 'self'; report-uri /csp-report",
+			},
+			wantOK: true,
+		},
+		{
+			name:   "overrides",
+			preset: SecureHeadersPresetDevelopment,
+			overrides: map[string]string{
+				"x-frame-options":           "DENY",
+				"referrer-policy":           "",
+				"Permissions-Policy":        "camera=()",
+				"Strict-Transport-Security": "",
+			},
+			want: map[string]string{
+				"X-Content-Type-Options":              "nosniff",
+				"X-Frame-Options":                     "DENY",
+				"Content-Security-Policy-Report-Only": "default-src 'self'; report-uri /csp-report",
+				"Permissions-Policy":                  "camera=()",
+			},
+			wantOK: true,
+		},
+		{
+			name:   "unknown preset",
+			preset: "staging",
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := SecureHeaders(tt.preset, tt.overrides)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	// Overrides leave the preset alone.
+	SecureHeaders(SecureHeadersPresetProduction, map[string]string{"X-Frame-Options": ""})
+	assert.Equal(t, "DENY", secureHeadersPresets[SecureHeadersPresetProduction]["X-Frame-Options"])
+}
+
+func TestGetSecureHeadersMiddleware(t *testing.T) {
+	headers, _ := SecureHeaders(SecureHeadersPresetProduction, nil)
+	trustedProxies, err := ParseTrustedProxies([]string{"10.0.0.0/8"})
+	assert.NoError(t, err)
+	testCases := []struct {
+		name       string
+		remoteAddr string
+		tls        bool
+		proto      string
+		wantHSTS   bool
+	}{
+		{name: "http", remoteAddr: "10.0.0.1:1234", wantHSTS: false},
+		{name: "https", remoteAddr: "192.168.0.1:1234", tls: true, wantHSTS: true},
+		{name: "https behind a trusted proxy", remoteAddr: "10.0.0.1:1234", proto: "https", wantHSTS: true},
+		{name: "http behind a trusted proxy", remoteAddr: "10.0.0.1:1234", proto: "http", wantHSTS: false},
+		{name: "https claimed by an untrusted client", remoteAddr: "192.168.0.1:1234", proto: "https", wantHSTS: false},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.tls {
+				req.TLS = &tls.ConnectionState{}
+			}
+			if tt.proto != "" {
+				req.Header.Set(echo.HeaderXForwardedProto, tt.proto)
+			}
+			rec := httptest.NewRecorder()
+			err := GetSecureHeadersMiddleware(headers, trustedProxies)(func(c echo.Context) error {
+				return c.NoContent(http.StatusOK)
+			})(echo.New().NewContext(req, rec))
+			assert.NoError(t, err)
+			assert.Equal(t, "DENY", rec.Header().Get("X-Frame-Options"))
+			assert.Equal(t, tt.wantHSTS, rec.Header().Get(echo.HeaderStrictTransportSecurity) != "")
+		})
+	}
+}
+
+// captureLogs sends the default logger to a buffer for the test.
+func captureLogs(t *testing.T) *bytes.Buffer {
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+	return &buf
+}
+
+func postCSPReport(handler echo.HandlerFunc, body string) (int, error) {
+	req := httptest.NewRequest(http.MethodPost, CSPReportPath, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	err := handler(echo.New().NewContext(req, rec))
+	return rec.Code, err
+}
+
+func TestCSPReportHandler(t *testing.T) {
+	batch := func(n int) string {
+		reports := make([]string, n)
+		for i := range reports {
+			reports[i] = `{"type":"csp-violation","body":{"documentURL":"https://example.com","effectiveDirective":"script-src","blockedURL":"https://evil.example.com"}}`
+		}
+		return "[" + strings.Join(reports, ",") + "]"
+	}
+	testCases := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantLogged int
+	}{
+		{
+			name:       "legacy report",
+			body:       `{"csp-report":{"document-uri":"https://example.com","violated-directive":"script-src","blocked-uri":"inline"}}`,
+			wantStatus: http.StatusNoContent,
+			wantLogged: 1,
+		},
+		{name: "reporting api", body: batch(2), wantStatus: http.StatusNoContent, wantLogged: 2},
+		{name: "other report types", body: `[{"type":"deprecation","body":{}}]`, wantStatus: http.StatusNoContent},
+		{name: "batch capped", body: batch(maxCSPReportsPerRequest + 5), wantStatus: http.StatusNoContent, wantLogged: maxCSPReportsPerRequest},
+		{name: "invalid", body: `{"csp-report":`, wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			logs := captureLogs(t)
+			status, err := postCSPReport(NewCSPReportHandler(), tt.body)
+			if tt.wantStatus == http.StatusBadRequest {
+				var httpErr *echo.HTTPError
+				assert.ErrorAs(t, err, &httpErr)
+				assert.Equal(t, tt.wantStatus, httpErr.Code)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, status)
+			assert.Equal(t, tt.wantLogged, strings.Count(logs.String(), "csp violation"))
+		})
+	}
+}
+
+func TestCSPReportHandlerLogCap(t *testing.T) {
+	logs := captureLogs(t)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	handler := &cspReportHandler{now: func() time.Time { return now }}
+	report := `{"csp-report":{"document-uri":"https://example.com","violated-directive":"script-src","blocked-uri":"inline"}}`
+
+	for i := 0; i < maxCSPReportsLogged+3; i++ {
+		status, err := postCSPReport(handler.handle, report)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNoContent, status)
+	}
+	assert.Equal(t, maxCSPReportsLogged, strings.Count(logs.String(), "csp violation\""))
+
+	// The next window sums up the reports the last one left out.
+	now = now.Add(cspLogWindow)
+	_, err := postCSPReport(handler.handle, report)
+	assert.NoError(t, err)
+	assert.Contains(t, logs.String(), `msg="csp violations not logged, too many reports" count=3`)
+	assert.Equal(t, maxCSPReportsLogged+1, strings.Count(logs.String(), "csp violation\""))
+}
+
+func TestCSPReportLimit(t *testing.T) {
+	app := echo.New()
+	app.POST(CSPReportPath, func(c echo.Context) error {
+		return c.NoContent(http.StatusNoContent)
+	}, NewCSPReportLimitMiddleware())
+
+	post := func(remoteAddr string) int {
+		req := httptest.NewRequest(http.MethodPost, CSPReportPath, strings.NewReader("{}"))
+		req.RemoteAddr = remoteAddr
+		rec := httptest.NewRecorder()
+		app.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	for i := 0; i < cspReportLimit.Requests; i++ {
+		assert.Equal(t, http.StatusNoContent, post("10.0.0.1:1234"))
+	}
+	assert.Equal(t, http.StatusTooManyRequests, post("10.0.0.1:1234"))
+	assert.Equal(t, http.StatusNoContent, post("10.0.0.2:1234"))
+}
@@ -2,16 +2,17 @@ package echo_server
 
 import (
 	"context"
+	"crud-customer/pkg/codec"
+	"crud-customer/pkg/logging"
 	"crud-customer/util/validator"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
-	"github.com/labstack/gommon/log"
+	"log/slog"
+	"net"
 	"net/http"
-	"os"
-	"os/signal"
-	"syscall"
 	"time"
 )
 
@@ -21,27 +22,45 @@ type Config struct {
 	AllowOrigins []string
 	BodyLimit    string
 	LogLevel     string
+	AllowHeaders []string
+	// TLS serves HTTPS when set.
+	TLS *tls.Config
+	// SecureHeaders are set on every response.
+	SecureHeaders map[string]string
+	// Metrics records request metrics.
+	Metrics bool
+	// TracingServiceName, when set, traces every request under this
+	// service name.
+	TracingServiceName string
+	// IPExtractor finds the client IP of requests, which is the address
+	// of the connection when nil.
+	IPExtractor echo.IPExtractor
+	// TrustedProxies are the peers whose X-Forwarded-Proto header is
+	// believed.
+	TrustedProxies []*net.IPNet
 }
 
 type EchoServer struct {
 	App        *echo.Echo
 	EchoConfig *Config
+	// The middlewares applying the settings Reload can change.
+	timeout   *reloadableMiddleware
+	cors      *reloadableMiddleware
+	bodyLimit *reloadableMiddleware
+	logLevel  string
 }
 
-func (s *EchoServer) GetLogLevel() log.Lvl {
-	switch s.EchoConfig.LogLevel {
-	case "DEBUG":
-		return log.DEBUG
-	case "INFO":
-		return log.INFO
-	case "WARN":
-		return log.WARN
-	case "ERROR":
-		return log.ERROR
-	default:
-		fmt.Printf("Invalid log level: %s. Defaulting to DEBUG", s.EchoConfig.LogLevel)
-		return log.DEBUG
+func (s *EchoServer) GetLogLevel() slog.Level {
+	return parseLogLevel(s.EchoConfig.LogLevel)
+}
+
+func parseLogLevel(logLevel string) slog.Level {
+	level, err := logging.ParseLevel(logLevel)
+	if err != nil {
+		slog.Warn("invalid log level, defaulting to DEBUG", "log_level", logLevel)
+		return slog.LevelDebug
 	}
+	return level
 }
 
 func (s *EchoServer) GetRouter() *echo.Router {
@@ -49,39 +68,135 @@ func (s *EchoServer) GetRouter() *echo.Router {
 }
 
 func (s *EchoServer) SetupServer() {
-	s.App.Logger.SetLevel(s.GetLogLevel())
+	logging.SetLevel(s.GetLogLevel())
+	s.logLevel = s.EchoConfig.LogLevel
+	s.App.Logger = logging.NewEchoLogger()
+	s.App.StdLogger = slog.NewLogLogger(slog.Default().Handler(), slog.LevelError)
+	s.App.HideBanner = true
+	s.App.HidePort = true
 	s.App.Validator = validator.GetEchoValidator()
+	s.App.Binder = codec.NewBinder(codec.GetRegistry())
+	s.App.IPExtractor = s.EchoConfig.IPExtractor
+	if s.App.IPExtractor == nil {
+		s.App.IPExtractor = echo.ExtractIPDirect()
+	}
 	s.setupMiddleWares()
+	if s.EchoConfig.SecureHeaders != nil {
+		s.App.POST(CSPReportPath, NewCSPReportHandler(), NewCSPReportLimitMiddleware()).Name = "CSPReport"
+	}
 }
 
 func (s *EchoServer) setupMiddleWares() {
 	s.App.Use(middleware.Recover())
-	s.App.Use(middleware.Logger())
-	s.App.Use(GetTimeOutMiddleware(s.EchoConfig.Timeout))
-	s.App.Use(GetCORSMiddleware(s.EchoConfig.AllowOrigins))
-	s.App.Use(GetBodyLimitMiddleware(s.EchoConfig.BodyLimit))
+	if s.EchoConfig.TracingServiceName != "" {
+		s.App.Use(GetTracingMiddleware(s.EchoConfig.TracingServiceName))
+	}
+	s.App.Use(GetRequestIDMiddleware())
+	s.App.Use(GetRequestContextMiddleware())
+	if s.EchoConfig.Metrics {
+		s.App.Use(GetMetricsMiddleware())
+	}
+	s.App.Use(GetLoggerMiddleware())
+	s.timeout = newReloadableMiddleware(GetTimeOutMiddleware(s.EchoConfig.Timeout))
+	s.App.Use(s.timeout.Middleware)
+	s.cors = newReloadableMiddleware(GetCORSMiddleware(s.EchoConfig.AllowOrigins, s.EchoConfig.AllowHeaders...))
+	s.App.Use(s.cors.Middleware)
+	if s.EchoConfig.SecureHeaders != nil {
+		s.App.Use(GetSecureHeadersMiddleware(s.EchoConfig.SecureHeaders, s.EchoConfig.TrustedProxies))
+	}
+	s.bodyLimit = newReloadableMiddleware(GetBodyLimitMiddleware(s.EchoConfig.BodyLimit))
+	s.App.Use(s.bodyLimit.Middleware)
 }
 
-func (s *EchoServer) HttpListening() {
+// Reload applies the timeout, CORS, body limit and log level of cfg while
+// serving; requests already in flight keep the previous settings. The log
+// level is only set when cfg changes it, so a level set through the admin
+// server outlives unrelated reloads.
+func (s *EchoServer) Reload(cfg *Config) {
+	s.timeout.Store(GetTimeOutMiddleware(cfg.Timeout))
+	s.cors.Store(GetCORSMiddleware(cfg.AllowOrigins, cfg.AllowHeaders...))
+	s.bodyLimit.Store(GetBodyLimitMiddleware(cfg.BodyLimit))
+	if cfg.LogLevel != s.logLevel {
+		logging.SetLevel(parseLogLevel(cfg.LogLevel))
+		s.logLevel = cfg.LogLevel
+	}
+}
+
+// HttpListening serves until Shutdown or Close is called, which makes it
+// return nil.
+func (s *EchoServer) HttpListening() error {
 	url := fmt.Sprintf(":%d", s.EchoConfig.Port)
-	s.setupGracefullyShutdown()
-	if err := s.App.Start(url); err != nil && !errors.Is(err, http.ErrServerClosed) {
-		s.App.Logger.Panicf("Error: %v", err)
+	slog.Info("http server started", "address", url, "tls", s.EchoConfig.TLS != nil)
+	var err error
+	if s.EchoConfig.TLS != nil {
+		s.App.TLSServer.Addr = url
+		s.App.TLSServer.TLSConfig = s.EchoConfig.TLS
+		err = s.App.StartServer(s.App.TLSServer)
+	} else {
+		err = s.App.Start(url)
 	}
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
 }
 
-func (s *EchoServer) setupGracefullyShutdown() {
-	ctx := context.Background()
-	quitCh := make(chan os.Signal, 1)
-	go func(quitCh chan os.Signal) {
-		signal.Notify(quitCh, syscall.SIGINT, syscall.SIGTERM)
-		<-quitCh
-		s.App.Logger.Infof("Shutting down service...")
+// Shutdown stops accepting connections and waits for the requests in flight
+// until ctx is done.
+func (s *EchoServer) Shutdown(ctx context.Context) error {
+	return s.App.Shutdown(ctx)
+}
 
-		if err := s.App.Shutdown(ctx); err != nil {
-			s.App.Logger.Fatalf("Error: %s", err.Error())
+// Close drops every connection, including those with requests in flight.
+func (s *EchoServer) Close() error {
+	return s.App.Close()
+}
+
+// ParseTrustedProxies parses the CIDRs of trusted proxies.
+func ParseTrustedProxies(cidrs []string) ([]*net.IPNet, error) {
+	trustedProxies := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", cidr, err)
+		}
+		trustedProxies = append(trustedProxies, ipNet)
+	}
+	return trustedProxies, nil
+}
+
+// NewIPExtractor returns the IPExtractor taking the client IP from the
+// X-Forwarded-For header of requests coming through trustedProxies, and
+// the address of the connection otherwise. Without trusted proxies the
+// header is ignored, as any client can set it.
+func NewIPExtractor(trustedProxies []*net.IPNet) echo.IPExtractor {
+	if len(trustedProxies) == 0 {
+		return echo.ExtractIPDirect()
+	}
+	options := []echo.TrustOption{echo.TrustLoopback(false), echo.TrustLinkLocal(false), echo.TrustPrivateNet(false)}
+	for _, ipNet := range trustedProxies {
+		options = append(options, echo.TrustIPRange(ipNet))
+	}
+	return echo.ExtractIPFromXFFHeader(options...)
+}
+
+// isTrustedProxy reports whether the connection of r comes from one of
+// trustedProxies.
+func isTrustedProxy(r *http.Request, trustedProxies []*net.IPNet) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, ipNet := range trustedProxies {
+		if ipNet.Contains(ip) {
+			return true
 		}
-	}(quitCh)
+	}
+	return false
 }
 
 func NewEchoServer(cfg *Config) EchoServer {
@@ -0,0 +1,71 @@
+package echo_server
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewIPExtractor(t *testing.T) {
+	testCases := []struct {
+		name           string
+		trustedProxies []string
+		remoteAddr     string
+		forwardedFor   string
+		want           string
+	}{
+		{
+			name:         "no trusted proxies ignores the header",
+			remoteAddr:   "10.0.0.1:1234",
+			forwardedFor: "1.2.3.4",
+			want:         "10.0.0.1",
+		},
+		{
+			name:           "trusted proxy",
+			trustedProxies: []string{"10.0.0.0/8"},
+			remoteAddr:     "10.0.0.1:1234",
+			forwardedFor:   "1.2.3.4",
+			want:           "1.2.3.4",
+		},
+		{
+			name:           "untrusted peer",
+			trustedProxies: []string{"10.0.0.0/8"},
+			remoteAddr:     "192.168.0.1:1234",
+			forwardedFor:   "1.2.3.4",
+			want:           "192.168.0.1",
+		},
+		{
+			name:           "spoofed entries before the trusted hops",
+			trustedProxies: []string{"10.0.0.0/8"},
+			remoteAddr:     "10.0.0.1:1234",
+			forwardedFor:   "6.6.6.6, 1.2.3.4, 10.0.0.2",
+			want:           "1.2.3.4",
+		},
+		{
+			name:           "private networks are not trusted by default",
+			trustedProxies: []string{"10.0.0.0/8"},
+			remoteAddr:     "127.0.0.1:1234",
+			forwardedFor:   "1.2.3.4",
+			want:           "127.0.0.1",
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			trustedProxies, err := ParseTrustedProxies(tt.trustedProxies)
+			assert.NoError(t, err)
+			extractor := NewIPExtractor(trustedProxies)
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			req.Header.Set(echo.HeaderXForwardedFor, tt.forwardedFor)
+			req.Header.Set(echo.HeaderXRealIP, "6.6.6.6")
+			assert.Equal(t, tt.want, extractor(req))
+		})
+	}
+}
+
+func TestParseTrustedProxiesInvalidProxy(t *testing.T) {
+	_, err := ParseTrustedProxies([]string{"10.0.0.1"})
+	assert.ErrorContains(t, err, `invalid trusted proxy "10.0.0.1"`)
+}
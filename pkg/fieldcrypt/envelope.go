@@ -0,0 +1,146 @@
+package fieldcrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// envelopePrefix marks encrypted values, so plaintext written before
+	// encryption was enabled can still be read until it is re-encrypted.
+	envelopePrefix = "enc2."
+	// plaintextPrefix escapes plaintext that would otherwise read as an
+	// envelope.
+	plaintextPrefix = "raw."
+)
+
+var ErrMalformedEnvelope = errors.New("fieldcrypt: malformed envelope")
+
+// Encrypt seals plaintext with a fresh data key, and the data key with the
+// primary key. The result is "enc2.<key id>.<wrapped data key>.<ciphertext>".
+// aad binds the value to where it is stored, e.g. "customers.name", so a
+// ciphertext copied to another column fails to decrypt.
+func (k *Keyring) Encrypt(plaintext string, aad string) (string, error) {
+	dataKey := make([]byte, keySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", err
+	}
+	kek, err := k.key(k.primary)
+	if err != nil {
+		return "", err
+	}
+	wrapped, err := seal(kek, dataKey, []byte(k.primary))
+	if err != nil {
+		return "", err
+	}
+	ciphertext, err := seal(dataKey, []byte(plaintext), []byte(aad))
+	if err != nil {
+		return "", err
+	}
+	return envelopePrefix + k.primary + "." +
+		base64.RawURLEncoding.EncodeToString(wrapped) + "." +
+		base64.RawURLEncoding.EncodeToString(ciphertext), nil
+}
+
+// Decrypt opens a value written by Encrypt. Values without the envelope
+// prefix are plaintext and returned as is.
+func (k *Keyring) Decrypt(value string, aad string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+	parts := strings.Split(value[len(envelopePrefix):], ".")
+	if len(parts) != 3 {
+		return "", ErrMalformedEnvelope
+	}
+	kek, err := k.key(parts[0])
+	if err != nil {
+		return "", err
+	}
+	wrapped, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", ErrMalformedEnvelope
+	}
+	ciphertext, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", ErrMalformedEnvelope
+	}
+	dataKey, err := open(kek, wrapped, []byte(parts[0]))
+	if err != nil {
+		return "", fmt.Errorf("fieldcrypt: error unwrapping data key: %w", err)
+	}
+	plaintext, err := open(dataKey, ciphertext, []byte(aad))
+	if err != nil {
+		return "", fmt.Errorf("fieldcrypt: error decrypting value: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+// BlindIndex returns a keyed hash of value for equality lookups on an
+// encrypted column. purpose keeps the indexes of different columns apart.
+func (k *Keyring) BlindIndex(value string, purpose string) string {
+	mac := hmac.New(sha256.New, k.blindIndexKey)
+	mac.Write([]byte(purpose))
+	mac.Write([]byte{0})
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, envelopePrefix)
+}
+
+// EscapePlaintext returns value as it is stored without a keyring. Values
+// that start like an envelope, or like an escaped value, are prefixed with
+// "raw." so they read back as written.
+func EscapePlaintext(value string) string {
+	if IsEncrypted(value) || strings.HasPrefix(value, plaintextPrefix) {
+		return plaintextPrefix + value
+	}
+	return value
+}
+
+// UnescapePlaintext reverses EscapePlaintext.
+func UnescapePlaintext(value string) string {
+	return strings.TrimPrefix(value, plaintextPrefix)
+}
+
+// seal encrypts with AES-GCM and prepends the nonce.
+func seal(key []byte, plaintext []byte, aad []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, aad), nil
+}
+
+func open(key []byte, sealed []byte, aad []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, ErrMalformedEnvelope
+	}
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, aad)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
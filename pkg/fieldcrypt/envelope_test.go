@@ -0,0 +1,154 @@
+package fieldcrypt_test
+
+import (
+	"crud-customer/pkg/fieldcrypt"
+	"crud-customer/util/testhelper"
+	"encoding/base64"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	k := testhelper.NewKeyring("k1", "k1")
+	value, err := k.Encrypt("John Doe", "customers.name:default")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(value, "enc2.k1."))
+	assert.True(t, fieldcrypt.IsEncrypted(value))
+	assert.NotContains(t, value, "John Doe")
+
+	plaintext, err := k.Decrypt(value, "customers.name:default")
+	assert.NoError(t, err)
+	assert.Equal(t, "John Doe", plaintext)
+
+	// Fresh data keys and nonces: the same plaintext encrypts differently.
+	other, err := k.Encrypt("John Doe", "customers.name:default")
+	assert.NoError(t, err)
+	assert.NotEqual(t, value, other)
+
+	plaintext, err = k.Decrypt("John Doe", "customers.name:default")
+	assert.NoError(t, err)
+	assert.Equal(t, "John Doe", plaintext)
+}
+
+func TestDecryptAdditionalDataMismatch(t *testing.T) {
+	k := testhelper.NewKeyring("k1", "k1")
+	value, err := k.Encrypt("John Doe", "customers.name:tenant-a")
+	assert.NoError(t, err)
+
+	for _, aad := range []string{"customers.name:tenant-b", "audit_entries.diff:tenant-a", "customers.name"} {
+		_, err = k.Decrypt(value, aad)
+		assert.ErrorContains(t, err, "fieldcrypt: error decrypting value", aad)
+	}
+}
+
+func TestDecryptTampered(t *testing.T) {
+	k := testhelper.NewKeyring("k1", "k1")
+	value, err := k.Encrypt("John Doe", "customers.name:default")
+	assert.NoError(t, err)
+	parts := strings.Split(value, ".")
+
+	flip := func(part string) string {
+		data, err := base64.RawURLEncoding.DecodeString(part)
+		assert.NoError(t, err)
+		data[len(data)-1] ^= 1
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	testCases := []struct {
+		name  string
+		value string
+		err   string
+	}{
+		{name: "ciphertext", value: strings.Join([]string{parts[0], parts[1], parts[2], flip(parts[3])}, "."), err: "fieldcrypt: error decrypting value"},
+		{name: "wrapped data key", value: strings.Join([]string{parts[0], parts[1], flip(parts[2]), parts[3]}, "."), err: "fieldcrypt: error unwrapping data key"},
+		{name: "truncated", value: strings.Join(parts[:3], "."), err: fieldcrypt.ErrMalformedEnvelope.Error()},
+		{name: "not base64", value: strings.Join([]string{parts[0], parts[1], parts[2], "!"}, "."), err: fieldcrypt.ErrMalformedEnvelope.Error()},
+		{name: "too short", value: "enc2.k1.AA.AA", err: "fieldcrypt: error unwrapping data key"},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := k.Decrypt(tt.value, "customers.name:default")
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestDecryptUnknownKey(t *testing.T) {
+	value, err := testhelper.NewKeyring("k2", "k2").Encrypt("John Doe", "customers.name:default")
+	assert.NoError(t, err)
+
+	_, err = testhelper.NewKeyring("k1", "k1").Decrypt(value, "customers.name:default")
+	assert.True(t, errors.Is(err, fieldcrypt.ErrUnknownKey))
+	assert.ErrorContains(t, err, `"k2"`)
+}
+
+func TestKeyRotation(t *testing.T) {
+	old := testhelper.NewKeyring("k1", "k1")
+	value, err := old.Encrypt("John Doe", "customers.name:default")
+	assert.NoError(t, err)
+
+	// The retired key stays in the keyring to read values written with it.
+	rotated := testhelper.NewKeyring("k2", "k1", "k2")
+	plaintext, err := rotated.Decrypt(value, "customers.name:default")
+	assert.NoError(t, err)
+	assert.Equal(t, "John Doe", plaintext)
+
+	reencrypted, err := rotated.Encrypt(plaintext, "customers.name:default")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(reencrypted, "enc2.k2."))
+
+	_, err = old.Decrypt(reencrypted, "customers.name:default")
+	assert.True(t, errors.Is(err, fieldcrypt.ErrUnknownKey))
+
+	// The blind index key is not rotated.
+	assert.Equal(t, old.BlindIndex("John Doe", "customers.name"), rotated.BlindIndex("John Doe", "customers.name"))
+	assert.NotEqual(t, old.BlindIndex("John Doe", "customers.name"), old.BlindIndex("John Doe", "other.name"))
+}
+
+func TestNewKeyring(t *testing.T) {
+	key := make([]byte, 32)
+	testCases := []struct {
+		name          string
+		primary       string
+		keys          map[string][]byte
+		blindIndexKey []byte
+		err           string
+	}{
+		{name: "valid", primary: "k1", keys: map[string][]byte{"k1": key}, blindIndexKey: key},
+		{name: "unknown primary", primary: "k2", keys: map[string][]byte{"k1": key}, blindIndexKey: key, err: `fieldcrypt: unknown key id: primary key "k2"`},
+		{name: "dot in key id", primary: "k.1", keys: map[string][]byte{"k.1": key}, blindIndexKey: key, err: `fieldcrypt: invalid key id "k.1"`},
+		{name: "short key", primary: "k1", keys: map[string][]byte{"k1": key[:16]}, blindIndexKey: key, err: `fieldcrypt: key "k1" must be 32 bytes`},
+		{name: "short blind index key", primary: "k1", keys: map[string][]byte{"k1": key}, blindIndexKey: key[:16], err: "fieldcrypt: blind index key must be 32 bytes"},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := fieldcrypt.NewKeyring(tt.primary, tt.keys, tt.blindIndexKey)
+			if tt.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestEscapePlaintext(t *testing.T) {
+	testCases := []struct {
+		value string
+		want  string
+	}{
+		{value: "John Doe", want: "John Doe"},
+		{value: "enc2.k1.a.b", want: "raw.enc2.k1.a.b"},
+		{value: "raw.John", want: "raw.raw.John"},
+		{value: "encore", want: "encore"},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.value, func(t *testing.T) {
+			escaped := fieldcrypt.EscapePlaintext(tt.value)
+			assert.Equal(t, tt.want, escaped)
+			assert.False(t, fieldcrypt.IsEncrypted(escaped))
+			assert.Equal(t, tt.value, fieldcrypt.UnescapePlaintext(escaped))
+		})
+	}
+}
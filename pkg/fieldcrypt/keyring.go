@@ -0,0 +1,95 @@
+package fieldcrypt
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// keySize is the size of key encryption keys and data keys: AES-256.
+const keySize = 32
+
+var ErrUnknownKey = errors.New("fieldcrypt: unknown key id")
+
+// Keyring holds the key encryption keys by ID. New values are encrypted
+// with the primary key; older keys stay in the keyring to decrypt values
+// written before a rotation, until rotate-keys has re-encrypted them.
+type Keyring struct {
+	primary       string
+	keys          map[string][]byte
+	blindIndexKey []byte
+}
+
+// keyringFile is the JSON layout of a keyring file. Keys are base64 encoded
+// 32 byte AES keys.
+//
+//	{
+//	  "primary": "2024-06",
+//	  "keys": {"2024-01": "...", "2024-06": "..."},
+//	  "blindIndexKey": "..."
+//	}
+type keyringFile struct {
+	Primary       string            `json:"primary"`
+	Keys          map[string]string `json:"keys"`
+	BlindIndexKey string            `json:"blindIndexKey"`
+}
+
+func NewKeyring(primary string, keys map[string][]byte, blindIndexKey []byte) (*Keyring, error) {
+	for id, key := range keys {
+		if id == "" || strings.Contains(id, ".") {
+			return nil, fmt.Errorf("fieldcrypt: invalid key id %q", id)
+		}
+		if len(key) != keySize {
+			return nil, fmt.Errorf("fieldcrypt: key %q must be %d bytes", id, keySize)
+		}
+	}
+	if _, ok := keys[primary]; !ok {
+		return nil, fmt.Errorf("%w: primary key %q", ErrUnknownKey, primary)
+	}
+	// The blind index key must never change: every stored index would have
+	// to be recomputed, so it is kept apart from the rotated keys.
+	if len(blindIndexKey) != keySize {
+		return nil, fmt.Errorf("fieldcrypt: blind index key must be %d bytes", keySize)
+	}
+	return &Keyring{primary: primary, keys: keys, blindIndexKey: blindIndexKey}, nil
+}
+
+func LoadKeyringFile(path string) (*Keyring, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file keyringFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("fieldcrypt: error parsing keyring %s: %w", path, err)
+	}
+
+	keys := make(map[string][]byte, len(file.Keys))
+	for id, encoded := range file.Keys {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("fieldcrypt: error decoding key %q: %w", id, err)
+		}
+		keys[id] = key
+	}
+	blindIndexKey, err := base64.StdEncoding.DecodeString(file.BlindIndexKey)
+	if err != nil {
+		return nil, fmt.Errorf("fieldcrypt: error decoding blind index key: %w", err)
+	}
+	return NewKeyring(file.Primary, keys, blindIndexKey)
+}
+
+func (k *Keyring) Primary() string {
+	return k.primary
+}
+
+func (k *Keyring) key(id string) ([]byte, error) {
+	key, ok := k.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, id)
+	}
+	return key, nil
+}
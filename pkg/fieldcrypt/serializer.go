@@ -0,0 +1,138 @@
+package fieldcrypt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gorm.io/gorm/schema"
+	"reflect"
+	"sync/atomic"
+)
+
+var (
+	ErrNoKeyring = errors.New("fieldcrypt: value is encrypted but no keyring is configured")
+
+	keyring atomic.Pointer[Keyring]
+)
+
+// SetKeyring sets the keyring used by the "encrypted" serializer. Without
+// one, values are written as plaintext.
+func SetKeyring(k *Keyring) {
+	keyring.Store(k)
+}
+
+func GetKeyring() *Keyring {
+	return keyring.Load()
+}
+
+// BlindIndex returns the blind index of value under the current keyring,
+// or nil when there is none.
+func BlindIndex(value string, purpose string) *string {
+	k := keyring.Load()
+	if k == nil {
+		return nil
+	}
+	index := k.BlindIndex(value, purpose)
+	return &index
+}
+
+// tenantColumn binds encrypted values to the tenant of their row.
+const tenantColumn = "tenant_id"
+
+// Serializer encrypts string and *string fields tagged
+// `gorm:"serializer:encrypted"` with the current keyring. The table, column
+// and tenant of the row are the additional data of each value, so a
+// ciphertext copied to another column or tenant fails to decrypt. Fields
+// are scanned in the order of the result columns: tenant_id must come
+// before the encrypted columns of a table.
+type Serializer struct{}
+
+func (Serializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	fieldValue := reflect.New(field.FieldType)
+	if dbValue != nil {
+		var value string
+		switch v := dbValue.(type) {
+		case []byte:
+			value = string(v)
+		case string:
+			value = v
+		default:
+			return fmt.Errorf("fieldcrypt: unsupported value for %s: %T", field.Name, dbValue)
+		}
+
+		if IsEncrypted(value) {
+			k := keyring.Load()
+			if k == nil {
+				return ErrNoKeyring
+			}
+			aad, err := additionalData(ctx, field, dst)
+			if err != nil {
+				return err
+			}
+			plaintext, err := k.Decrypt(value, aad)
+			if err != nil {
+				return err
+			}
+			value = plaintext
+		} else {
+			value = UnescapePlaintext(value)
+		}
+		setString(fieldValue.Elem(), value)
+	}
+	field.ReflectValueOf(ctx, dst).Set(fieldValue.Elem())
+	return nil
+}
+
+func (Serializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	var plaintext string
+	switch v := fieldValue.(type) {
+	case string:
+		plaintext = v
+	case *string:
+		if v == nil {
+			return nil, nil
+		}
+		plaintext = *v
+	default:
+		return nil, fmt.Errorf("fieldcrypt: unsupported field type for %s: %T", field.Name, fieldValue)
+	}
+
+	k := keyring.Load()
+	if k == nil {
+		return EscapePlaintext(plaintext), nil
+	}
+	aad, err := additionalData(ctx, field, dst)
+	if err != nil {
+		return nil, err
+	}
+	return k.Encrypt(plaintext, aad)
+}
+
+// additionalData is "<table>.<column>:<tenant>", or "<table>.<column>" for
+// tables without tenants.
+func additionalData(ctx context.Context, field *schema.Field, dst reflect.Value) (string, error) {
+	aad := field.Schema.Table + "." + field.DBName
+	tenant := field.Schema.LookUpField(tenantColumn)
+	if tenant == nil {
+		return aad, nil
+	}
+	value, zero := tenant.ValueOf(ctx, dst)
+	if zero {
+		return "", fmt.Errorf("fieldcrypt: %s.%s must be set before %s", field.Schema.Table, tenantColumn, field.DBName)
+	}
+	return fmt.Sprintf("%s:%v", aad, value), nil
+}
+
+func setString(v reflect.Value, s string) {
+	if v.Kind() == reflect.Pointer {
+		p := reflect.New(v.Type().Elem())
+		p.Elem().SetString(s)
+		v.Set(p)
+		return
+	}
+	v.SetString(s)
+}
+
+func init() {
+	schema.RegisterSerializer("encrypted", Serializer{})
+}
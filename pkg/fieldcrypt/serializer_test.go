@@ -0,0 +1,145 @@
+package fieldcrypt_test
+
+import (
+	"crud-customer/pkg/fieldcrypt"
+	"crud-customer/util/testhelper"
+	"errors"
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type record struct {
+	ID       uint
+	TenantID string
+	Secret   *string `gorm:"serializer:encrypted"`
+}
+
+// misordered reads its encrypted column before the tenant it is bound to.
+type misordered struct {
+	ID       uint
+	Secret   string `gorm:"serializer:encrypted"`
+	TenantID string
+}
+
+type untenanted struct {
+	ID     uint
+	Secret string `gorm:"serializer:encrypted"`
+}
+
+type SerializerTestSuite struct {
+	suite.Suite
+	db *gorm.DB
+}
+
+func (s *SerializerTestSuite) SetupTest() {
+	var err error
+	s.db, err = gorm.Open(sqlite.Open(filepath.Join(s.T().TempDir(), "test.db")), &gorm.Config{Logger: logger.Discard})
+	s.Require().NoError(err)
+	s.Require().NoError(s.db.AutoMigrate(&record{}, &misordered{}, &untenanted{}))
+}
+
+func (s *SerializerTestSuite) TearDownTest() {
+	fieldcrypt.SetKeyring(nil)
+	if db, err := s.db.DB(); err == nil {
+		db.Close()
+	}
+}
+
+func (s *SerializerTestSuite) stored(table string, id uint) string {
+	var value string
+	s.Require().NoError(s.db.Raw("SELECT secret FROM "+table+" WHERE id = ?", id).Scan(&value).Error)
+	return value
+}
+
+func (s *SerializerTestSuite) TestRoundTrip() {
+	fieldcrypt.SetKeyring(testhelper.NewKeyring("k1", "k1"))
+	secret := "hunter2"
+	r := &record{TenantID: "acme", Secret: &secret}
+	s.NoError(s.db.Create(r).Error)
+	s.True(strings.HasPrefix(s.stored("records", r.ID), "enc2.k1."))
+
+	var got record
+	s.NoError(s.db.First(&got, r.ID).Error)
+	s.Equal("hunter2", *got.Secret)
+
+	empty := &record{TenantID: "acme"}
+	s.NoError(s.db.Create(empty).Error)
+	var gotEmpty record
+	s.NoError(s.db.First(&gotEmpty, empty.ID).Error)
+	s.Nil(gotEmpty.Secret)
+}
+
+func (s *SerializerTestSuite) TestBoundToTenant() {
+	fieldcrypt.SetKeyring(testhelper.NewKeyring("k1", "k1"))
+	secret := "hunter2"
+	a := &record{TenantID: "tenant-a", Secret: &secret}
+	b := &record{TenantID: "tenant-b", Secret: &secret}
+	s.NoError(s.db.Create([]*record{a, b}).Error)
+
+	s.NoError(s.db.Exec("UPDATE records SET secret = ? WHERE id = ?", s.stored("records", a.ID), b.ID).Error)
+	var got record
+	s.ErrorContains(s.db.First(&got, b.ID).Error, "fieldcrypt: error decrypting value")
+
+	s.ErrorContains(s.db.Create(&record{Secret: &secret}).Error, "fieldcrypt: records.tenant_id must be set before secret")
+}
+
+func (s *SerializerTestSuite) TestTenantReadFirst() {
+	fieldcrypt.SetKeyring(testhelper.NewKeyring("k1", "k1"))
+	m := &misordered{TenantID: "acme", Secret: "hunter2"}
+	s.NoError(s.db.Create(m).Error)
+
+	var got misordered
+	s.ErrorContains(s.db.First(&got, m.ID).Error, "fieldcrypt: misordereds.tenant_id must be set before secret")
+}
+
+func (s *SerializerTestSuite) TestUntenanted() {
+	fieldcrypt.SetKeyring(testhelper.NewKeyring("k1", "k1"))
+	u := &untenanted{Secret: "hunter2"}
+	s.NoError(s.db.Create(u).Error)
+
+	var got untenanted
+	s.NoError(s.db.First(&got, u.ID).Error)
+	s.Equal("hunter2", got.Secret)
+}
+
+func (s *SerializerTestSuite) TestPlaintext() {
+	secrets := []string{"hunter2", "enc2.k1.a.b", "raw.hunter2"}
+	for _, secret := range secrets {
+		s.NoError(s.db.Create(&record{TenantID: "acme", Secret: &secret}).Error)
+	}
+	s.Equal("hunter2", s.stored("records", 1))
+	s.Equal("raw.enc2.k1.a.b", s.stored("records", 2))
+
+	var got []record
+	s.NoError(s.db.Order("id").Find(&got).Error)
+	for i, secret := range secrets {
+		s.Equal(secret, *got[i].Secret)
+	}
+
+	// Read back once encryption is enabled, until rotate-keys rewrites them.
+	fieldcrypt.SetKeyring(testhelper.NewKeyring("k1", "k1"))
+	s.NoError(s.db.Order("id").Find(&got).Error)
+	for i, secret := range secrets {
+		s.Equal(secret, *got[i].Secret)
+	}
+}
+
+func (s *SerializerTestSuite) TestNoKeyring() {
+	fieldcrypt.SetKeyring(testhelper.NewKeyring("k1", "k1"))
+	secret := "hunter2"
+	r := &record{TenantID: "acme", Secret: &secret}
+	s.NoError(s.db.Create(r).Error)
+
+	fieldcrypt.SetKeyring(nil)
+	var got record
+	s.True(errors.Is(s.db.First(&got, r.ID).Error, fieldcrypt.ErrNoKeyring))
+}
+
+func TestSerializerTestSuite(t *testing.T) {
+	suite.Run(t, new(SerializerTestSuite))
+}
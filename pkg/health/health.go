@@ -0,0 +1,144 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Statuses of a report and of its checks.
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+// ErrShuttingDown fails readiness once graceful shutdown has started, so
+// load balancers stop routing to the instance before it stops listening.
+var ErrShuttingDown = errors.New("shutting down")
+
+// CheckFunc reports whether a dependency is usable. It must return when ctx
+// is done.
+type CheckFunc func(ctx context.Context) error
+
+type check struct {
+	name    string
+	timeout time.Duration
+	fn      CheckFunc
+}
+
+type CheckResult struct {
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
+
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+func (r *Report) OK() bool {
+	return r.Status == StatusOK
+}
+
+// Registry holds the checks readiness depends on.
+type Registry struct {
+	mu           sync.RWMutex
+	checks       []check
+	shuttingDown atomic.Bool
+}
+
+// Register adds a check, which fails when it takes longer than timeout.
+func (r *Registry) Register(name string, timeout time.Duration, fn CheckFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checks = append(r.checks, check{name: name, timeout: timeout, fn: fn})
+}
+
+// SetShuttingDown makes every later Check fail, with ErrShuttingDown
+// reported as the "shutdown" check.
+func (r *Registry) SetShuttingDown() {
+	r.shuttingDown.Store(true)
+}
+
+// Check runs every check concurrently and reports each result. The report
+// is ok only when every check passed and shutdown has not started.
+func (r *Registry) Check(ctx context.Context) Report {
+	r.mu.RLock()
+	checks := append([]check{}, r.checks...)
+	r.mu.RUnlock()
+
+	results := make([]CheckResult, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func(i int, c check) {
+			defer wg.Done()
+			results[i] = run(ctx, c)
+		}(i, c)
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusOK, Checks: make(map[string]CheckResult, len(checks))}
+	for i, c := range checks {
+		report.Checks[c.name] = results[i]
+		if results[i].Status != StatusOK {
+			report.Status = StatusFail
+		}
+	}
+	if r.shuttingDown.Load() {
+		report.Status = StatusFail
+		report.Checks["shutdown"] = CheckResult{Status: StatusFail, Error: ErrShuttingDown.Error(), Duration: "0s"}
+	}
+	return report
+}
+
+func run(ctx context.Context, c check) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- c.fn(ctx)
+	}()
+	var err error
+	select {
+	case err = <-errCh:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	result := CheckResult{Status: StatusOK, Duration: time.Since(start).Round(time.Microsecond).String()}
+	if err != nil {
+		result.Status = StatusFail
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// DiskWritable checks that a file can be created in dir.
+func DiskWritable(dir string) CheckFunc {
+	return func(ctx context.Context) error {
+		file, err := os.CreateTemp(dir, ".healthcheck-*")
+		if err != nil {
+			return err
+		}
+		name := file.Name()
+		_, err = file.Write([]byte("ok"))
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if removeErr := os.Remove(name); err == nil {
+			err = removeErr
+		}
+		return err
+	}
+}
+
+func NewRegistry() *Registry {
+	return &Registry{}
+}
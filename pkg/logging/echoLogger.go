@@ -0,0 +1,183 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// echoLogger is an echo.Logger writing to the default slog logger, so that
+// what echo and its middlewares log is JSON like the rest. Its level is the
+// level of the default logger and its output cannot be changed.
+type echoLogger struct {
+	prefix string
+}
+
+// NewEchoLogger returns an echo.Logger backed by the default slog logger.
+func NewEchoLogger() echo.Logger {
+	return &echoLogger{}
+}
+
+func (l *echoLogger) Output() io.Writer {
+	return &lineWriter{level: slog.LevelInfo}
+}
+
+func (l *echoLogger) SetOutput(w io.Writer) {}
+
+func (l *echoLogger) Prefix() string {
+	return l.prefix
+}
+
+func (l *echoLogger) SetPrefix(p string) {
+	l.prefix = p
+}
+
+func (l *echoLogger) Level() log.Lvl {
+	switch lvl := Level(); {
+	case lvl >= slog.LevelError:
+		return log.ERROR
+	case lvl >= slog.LevelWarn:
+		return log.WARN
+	case lvl >= slog.LevelInfo:
+		return log.INFO
+	default:
+		return log.DEBUG
+	}
+}
+
+func (l *echoLogger) SetLevel(v log.Lvl) {
+	switch v {
+	case log.DEBUG:
+		SetLevel(slog.LevelDebug)
+	case log.INFO:
+		SetLevel(slog.LevelInfo)
+	case log.WARN:
+		SetLevel(slog.LevelWarn)
+	case log.ERROR, log.OFF:
+		SetLevel(slog.LevelError)
+	}
+}
+
+func (l *echoLogger) SetHeader(h string) {}
+
+func (l *echoLogger) log(level slog.Level, msg string) {
+	if l.prefix != "" {
+		msg = l.prefix + ": " + msg
+	}
+	slog.Log(context.Background(), level, msg)
+}
+
+func (l *echoLogger) logj(level slog.Level, j log.JSON) {
+	attrs := make([]slog.Attr, 0, len(j))
+	for key, value := range j {
+		attrs = append(attrs, slog.Any(key, value))
+	}
+	slog.LogAttrs(context.Background(), level, l.prefix, attrs...)
+}
+
+func (l *echoLogger) Print(i ...interface{}) {
+	l.log(slog.LevelInfo, fmt.Sprint(i...))
+}
+
+func (l *echoLogger) Printf(format string, args ...interface{}) {
+	l.log(slog.LevelInfo, fmt.Sprintf(format, args...))
+}
+
+func (l *echoLogger) Printj(j log.JSON) {
+	l.logj(slog.LevelInfo, j)
+}
+
+func (l *echoLogger) Debug(i ...interface{}) {
+	l.log(slog.LevelDebug, fmt.Sprint(i...))
+}
+
+func (l *echoLogger) Debugf(format string, args ...interface{}) {
+	l.log(slog.LevelDebug, fmt.Sprintf(format, args...))
+}
+
+func (l *echoLogger) Debugj(j log.JSON) {
+	l.logj(slog.LevelDebug, j)
+}
+
+func (l *echoLogger) Info(i ...interface{}) {
+	l.log(slog.LevelInfo, fmt.Sprint(i...))
+}
+
+func (l *echoLogger) Infof(format string, args ...interface{}) {
+	l.log(slog.LevelInfo, fmt.Sprintf(format, args...))
+}
+
+func (l *echoLogger) Infoj(j log.JSON) {
+	l.logj(slog.LevelInfo, j)
+}
+
+func (l *echoLogger) Warn(i ...interface{}) {
+	l.log(slog.LevelWarn, fmt.Sprint(i...))
+}
+
+func (l *echoLogger) Warnf(format string, args ...interface{}) {
+	l.log(slog.LevelWarn, fmt.Sprintf(format, args...))
+}
+
+func (l *echoLogger) Warnj(j log.JSON) {
+	l.logj(slog.LevelWarn, j)
+}
+
+func (l *echoLogger) Error(i ...interface{}) {
+	l.log(slog.LevelError, fmt.Sprint(i...))
+}
+
+func (l *echoLogger) Errorf(format string, args ...interface{}) {
+	l.log(slog.LevelError, fmt.Sprintf(format, args...))
+}
+
+func (l *echoLogger) Errorj(j log.JSON) {
+	l.logj(slog.LevelError, j)
+}
+
+func (l *echoLogger) Fatal(i ...interface{}) {
+	l.log(slog.LevelError, fmt.Sprint(i...))
+	os.Exit(1)
+}
+
+func (l *echoLogger) Fatalj(j log.JSON) {
+	l.logj(slog.LevelError, j)
+	os.Exit(1)
+}
+
+func (l *echoLogger) Fatalf(format string, args ...interface{}) {
+	l.log(slog.LevelError, fmt.Sprintf(format, args...))
+	os.Exit(1)
+}
+
+func (l *echoLogger) Panic(i ...interface{}) {
+	msg := fmt.Sprint(i...)
+	l.log(slog.LevelError, msg)
+	panic(msg)
+}
+
+func (l *echoLogger) Panicj(j log.JSON) {
+	l.logj(slog.LevelError, j)
+	panic(j)
+}
+
+func (l *echoLogger) Panicf(format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	l.log(slog.LevelError, msg)
+	panic(msg)
+}
+
+// lineWriter logs everything written to it, one record per write.
+type lineWriter struct {
+	level slog.Level
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	slog.Log(context.Background(), w.level, strings.TrimRight(string(p), "\n"))
+	return len(p), nil
+}
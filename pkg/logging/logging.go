@@ -0,0 +1,72 @@
+package logging
+
+import (
+	"context"
+	"crud-customer/pkg/reqctx"
+	"fmt"
+	"go.opentelemetry.io/otel/trace"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+var level = new(slog.LevelVar)
+
+// Setup makes the default slog logger, which the standard log package also
+// writes to, emit JSON lines to w from lvl up. Every record logged with a
+// context carries the request ID and the trace of that context.
+func Setup(w io.Writer, lvl slog.Level) {
+	level.Set(lvl)
+	handler := slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level})
+	slog.SetDefault(slog.New(&contextHandler{Handler: handler}))
+}
+
+// SetLevel changes the level of the default logger at runtime.
+func SetLevel(lvl slog.Level) {
+	level.Set(lvl)
+}
+
+func Level() slog.Level {
+	return level.Level()
+}
+
+// ParseLevel reads DEBUG, INFO, WARN or ERROR, in any case.
+func ParseLevel(s string) (slog.Level, error) {
+	switch strings.ToUpper(s) {
+	case "DEBUG":
+		return slog.LevelDebug, nil
+	case "INFO":
+		return slog.LevelInfo, nil
+	case "WARN":
+		return slog.LevelWarn, nil
+	case "ERROR":
+		return slog.LevelError, nil
+	}
+	return slog.LevelInfo, fmt.Errorf("invalid log level %q", s)
+}
+
+// contextHandler adds the correlation IDs found in the context of a record.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h *contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if requestID := reqctx.RequestIDFromContext(ctx); requestID != "" {
+		record.AddAttrs(slog.String("request_id", requestID))
+	}
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		record.AddAttrs(
+			slog.String("trace_id", spanContext.TraceID().String()),
+			slog.String("span_id", spanContext.SpanID().String()),
+		)
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,93 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+	"time"
+)
+
+const namespace = "crud_customer"
+
+// Registry holds every metric of the application, along with the Go runtime
+// and process metrics.
+var Registry = prometheus.NewRegistry()
+
+var (
+	HTTPRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by route name, method and status code.",
+	}, []string{"route", "method", "status"})
+
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by route name and method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method"})
+
+	ServiceCallsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "service_calls_total",
+		Help:      "Service method calls by service, method and result (ok or error).",
+	}, []string{"service", "method", "result"})
+
+	DBQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Duration of GORM statements by operation and table.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+	}, []string{"operation", "table"})
+
+	ConfigReloadsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "config_reloads_total",
+		Help:      "Reloads of config.yaml by result (applied or rejected).",
+	}, []string{"result"})
+)
+
+// Results of a service call.
+const (
+	ResultOK    = "ok"
+	ResultError = "error"
+)
+
+// Results of a config reload.
+const (
+	ReloadApplied  = "applied"
+	ReloadRejected = "rejected"
+)
+
+// ObserveServiceCall counts a call of method on service.
+func ObserveServiceCall(service string, method string, err error) {
+	result := ResultOK
+	if err != nil {
+		result = ResultError
+	}
+	ServiceCallsTotal.WithLabelValues(service, method, result).Inc()
+}
+
+// NewServer serves the registry at path on addr.
+func NewServer(addr string, path string) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle(path, promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry}))
+	return &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+}
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequestsTotal,
+		HTTPRequestDuration,
+		ServiceCallsTotal,
+		DBQueryDuration,
+		ConfigReloadsTotal,
+	)
+}
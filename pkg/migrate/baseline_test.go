@@ -0,0 +1,130 @@
+package migrate_test
+
+import (
+	"context"
+	"crud-customer/internal/entity"
+	"crud-customer/pkg/database/migrations"
+	"crud-customer/pkg/fieldcrypt"
+	"crud-customer/pkg/migrate"
+	"crud-customer/util/testhelper"
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"os"
+	"testing"
+	"time"
+)
+
+// BaselineTestSuite upgrades databases autoMigrate created before versioned
+// migrations, starting from the schema of the first release.
+type BaselineTestSuite struct {
+	suite.Suite
+	tmpDBFile *os.File
+	db        *gorm.DB
+	migrator  migrate.Migrator
+}
+
+func (s *BaselineTestSuite) SetupTest() {
+	f, err := os.CreateTemp("", "test.*.db")
+	if err != nil {
+		panic(err)
+	}
+	s.tmpDBFile = f
+	s.db, err = gorm.Open(sqlite.Open(f.Name()), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		panic(err)
+	}
+	for _, statement := range []string{
+		"CREATE TABLE customers (id integer PRIMARY KEY AUTOINCREMENT NOT NULL, name text NOT NULL, age integer NOT NULL)",
+		"CREATE INDEX idx_customers_id ON customers(id)",
+		"INSERT INTO customers (name, age) VALUES ('John Doe', 20), ('Jane Doe', 30), ('Jim Beam', 40)",
+		"DELETE FROM customers WHERE name = 'Jim Beam'",
+	} {
+		if err := s.db.Exec(statement).Error; err != nil {
+			panic(err)
+		}
+	}
+	list, err := migrate.Load(migrations.FS)
+	if err != nil {
+		panic(err)
+	}
+	s.migrator = migrate.NewMigrator(s.db, list, migrate.Options{LockTimeout: time.Second, LockExpiry: time.Minute})
+}
+
+func (s *BaselineTestSuite) TearDownTest() {
+	fieldcrypt.SetKeyring(nil)
+	if db, err := s.db.DB(); err == nil {
+		db.Close()
+	}
+	os.Remove(s.tmpDBFile.Name())
+}
+
+func (s *BaselineTestSuite) TestUpgradeAddsColumns() {
+	_, err := s.migrator.Up(context.Background(), 0)
+	s.Nil(err)
+	// tenant_id comes before name, which is bound to it when encrypted.
+	columnTypes, err := s.db.Migrator().ColumnTypes("customers")
+	s.Nil(err)
+	var columns []string
+	for _, columnType := range columnTypes {
+		columns = append(columns, columnType.Name())
+	}
+	s.Equal([]string{"id", "tenant_id", "name", "name_index", "age", "external_source", "external_id", "deleted_at"}, columns)
+	for _, table := range []string{"api_keys", "audit_entries", "outbox_events"} {
+		s.True(s.db.Migrator().HasTable(table), table)
+	}
+
+	var customers []entity.Customer
+	s.Nil(s.db.Order("id").Find(&customers).Error)
+	s.Len(customers, 2)
+	s.Equal("default", customers[0].TenantID)
+	s.Equal("John Doe", *customers[0].Name)
+	s.Nil(customers[0].NameIndex)
+
+	// IDs of deleted customers are not handed out again.
+	s.Nil(s.db.Exec("INSERT INTO customers (name, age) VALUES ('Joe', 50)").Error)
+	var lastID uint
+	s.Nil(s.db.Raw("SELECT max(id) FROM customers").Scan(&lastID).Error)
+	s.Equal(uint(4), lastID)
+
+	pending, err := s.migrator.Pending(context.Background())
+	s.Nil(err)
+	s.Empty(pending)
+}
+
+func (s *BaselineTestSuite) TestUpgradeBackfillsNameIndex() {
+	fieldcrypt.SetKeyring(testhelper.NewKeyring("k1", "k1"))
+	_, err := s.migrator.Up(context.Background(), 0)
+	s.Nil(err)
+
+	var customers []entity.Customer
+	s.Nil(s.db.Order("id").Find(&customers).Error)
+	s.Len(customers, 2)
+	s.Equal(fieldcrypt.BlindIndex("John Doe", entity.CustomerNameIndexPurpose), customers[0].NameIndex)
+	s.Equal(fieldcrypt.BlindIndex("Jane Doe", entity.CustomerNameIndexPurpose), customers[1].NameIndex)
+}
+
+func (s *BaselineTestSuite) TestUpgradeRejectsUnknownColumns() {
+	s.Nil(s.db.Exec("ALTER TABLE customers ADD COLUMN email text").Error)
+	_, err := s.migrator.Up(context.Background(), 0)
+	s.ErrorContains(err, "error upgrading table customers: unexpected column email")
+	s.False(s.db.Migrator().HasColumn("customers", "tenant_id"))
+}
+
+func (s *BaselineTestSuite) TestUpgradeExternalIndexPerTenant() {
+	s.Nil(s.db.Exec("ALTER TABLE customers ADD COLUMN external_source text").Error)
+	s.Nil(s.db.Exec("ALTER TABLE customers ADD COLUMN external_id text").Error)
+	_, err := s.migrator.Up(context.Background(), 0)
+	s.Nil(err)
+
+	s.True(s.db.Migrator().HasIndex("customers", "idx_customers_tenant_external"))
+	for _, tenantID := range []string{"a", "b"} {
+		s.Nil(s.db.Exec("INSERT INTO customers (tenant_id, name, age, external_source, external_id) VALUES (?, 'Joe', 40, 'crm', '1')",
+			tenantID).Error)
+	}
+}
+
+func TestBaselineTestSuite(t *testing.T) {
+	suite.Run(t, new(BaselineTestSuite))
+}
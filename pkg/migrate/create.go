@@ -0,0 +1,75 @@
+package migrate
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// VersionFormat is the layout of the time migration versions are made of.
+const VersionFormat = "20060102150405"
+
+var nonWord = regexp.MustCompile(`[^a-z0-9]+`)
+
+const sqlTemplate = `-- %s
+`
+
+const goTemplate = `package %s
+
+import (
+	"crud-customer/pkg/migrate"
+	"gorm.io/gorm"
+)
+
+func init() {
+	migrate.Register(%s, %q, func(tx *gorm.DB) error {
+		return nil
+	}, func(tx *gorm.DB) error {
+		return nil
+	})
+}
+`
+
+// Create writes the files of a new migration named name into dir, versioned
+// with the time now: an up and a down SQL file, or a Go file registering the
+// migration when goMigration is set. It returns the files written.
+func Create(dir string, name string, goMigration bool, now time.Time) ([]string, error) {
+	name = strings.Trim(nonWord.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if name == "" {
+		return nil, fmt.Errorf("invalid migration name")
+	}
+	version := now.UTC().Format(VersionFormat)
+	base := filepath.Join(dir, version+"_"+name)
+
+	files := map[string]string{}
+	if goMigration {
+		files[base+".go"] = fmt.Sprintf(goTemplate, filepath.Base(dir), version, name)
+	} else {
+		files[base+".up.sql"] = fmt.Sprintf(sqlTemplate, name+": up")
+		files[base+".down.sql"] = fmt.Sprintf(sqlTemplate, name+": down")
+	}
+
+	var written []string
+	for _, file := range []string{base + ".up.sql", base + ".down.sql", base + ".go"} {
+		content, ok := files[file]
+		if !ok {
+			continue
+		}
+		f, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err != nil {
+			return written, err
+		}
+		_, err = f.WriteString(content)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return written, err
+		}
+		written = append(written, file)
+	}
+	return written, nil
+}
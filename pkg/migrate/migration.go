@@ -0,0 +1,109 @@
+package migrate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"gorm.io/gorm"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// Migration changes the schema from the previous version to Version. Up and
+// Down each run in a transaction, together with the update of
+// schema_migrations. Down is nil for migrations that cannot be reverted.
+type Migration struct {
+	Version int64
+	Name    string
+	// Checksum is the SHA-256 of the up file of SQL migrations, recorded
+	// when applied to detect later changes. Go migrations have none.
+	Checksum string
+	Up       func(tx *gorm.DB) error
+	Down     func(tx *gorm.DB) error
+}
+
+func (m Migration) String() string {
+	return fmt.Sprintf("%d_%s", m.Version, m.Name)
+}
+
+var registered []Migration
+
+// Register adds a migration written in Go, for changes SQL cannot express,
+// like backfills computed by the application. It is called from the init
+// functions of the migrations package.
+func Register(version int64, name string, up func(tx *gorm.DB) error, down func(tx *gorm.DB) error) {
+	registered = append(registered, Migration{Version: version, Name: name, Up: up, Down: down})
+}
+
+// sqlFile matches the SQL migrations, <version>_<name>.up.sql and
+// <version>_<name>.down.sql.
+var sqlFile = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Load returns the SQL migrations in the root of fsys and the registered Go
+// migrations, by version. Two migrations with the same version are an error.
+func Load(fsys fs.FS) ([]Migration, error) {
+	return load(fsys, registered)
+}
+
+func load(fsys fs.FS, goMigrations []Migration) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+	byVersion := map[int64]*Migration{}
+	for _, entry := range entries {
+		match := sqlFile.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version %s: %w", entry.Name(), err)
+		}
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("duplicate migration version %d: %s and %s", version, m.Name, match[2])
+		}
+		if match[3] == "up" {
+			sum := sha256.Sum256(content)
+			m.Checksum = hex.EncodeToString(sum[:])
+			m.Up = execSQL(string(content))
+		} else {
+			m.Down = execSQL(string(content))
+		}
+	}
+	for _, r := range goMigrations {
+		if m, ok := byVersion[r.Version]; ok {
+			return nil, fmt.Errorf("duplicate migration version %d: %s and %s", r.Version, m.Name, r.Name)
+		}
+		r := r
+		byVersion[r.Version] = &r
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == nil {
+			return nil, fmt.Errorf("migration %s has no up", m)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+func execSQL(sql string) func(tx *gorm.DB) error {
+	return func(tx *gorm.DB) error {
+		return tx.Exec(sql).Error
+	}
+}
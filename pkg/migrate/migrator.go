@@ -0,0 +1,380 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"log/slog"
+	"os"
+	"sort"
+	"time"
+)
+
+// ErrLocked is returned when another process holds the migration lock for
+// longer than the lock timeout.
+var ErrLocked = errors.New("migrations are locked")
+
+// ErrModified is returned by Up when an applied SQL migration was changed
+// since, which the database would silently not reflect.
+var ErrModified = errors.New("applied migrations were modified")
+
+type Migrator interface {
+	// Up applies the pending migrations by version, at most steps of them
+	// when steps is positive, and returns the ones applied.
+	Up(ctx context.Context, steps int) ([]Migration, error)
+	// Down reverts the last steps applied migrations and returns them.
+	Down(ctx context.Context, steps int) ([]Migration, error)
+	// Redo reverts the last applied migration and applies it again.
+	Redo(ctx context.Context) (Migration, error)
+	// Status lists the migrations with the time they were applied, including
+	// the applied ones this build does not know.
+	Status(ctx context.Context) ([]Status, error)
+	// Pending lists the migrations Up would apply.
+	Pending(ctx context.Context) ([]Migration, error)
+}
+
+type Status struct {
+	Migration
+	// AppliedAt is nil for pending migrations.
+	AppliedAt *time.Time
+	// Unknown marks applied migrations missing from this build, applied by
+	// a newer one.
+	Unknown bool
+	// Modified marks applied SQL migrations whose up file changed since.
+	Modified bool
+}
+
+type Options struct {
+	// LockTimeout bounds the wait for the migration lock.
+	LockTimeout time.Duration
+	// LockExpiry is the age after which the lock of a process that stopped
+	// without releasing it is taken over. The holder refreshes it after
+	// every migration, so it must exceed the longest one.
+	LockExpiry time.Duration
+}
+
+// schemaMigration is a row of schema_migrations, one per applied migration.
+type schemaMigration struct {
+	Version   int64     `gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"not null"`
+	Checksum  string    `gorm:"not null"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// schemaMigrationLock is the row of schema_migrations_lock held while
+// migrations run, so that instances starting together migrate one at a time.
+type schemaMigrationLock struct {
+	ID       int       `gorm:"primaryKey;autoIncrement:false"`
+	Owner    string    `gorm:"not null"`
+	LockedAt time.Time `gorm:"not null"`
+}
+
+func (schemaMigrationLock) TableName() string {
+	return "schema_migrations_lock"
+}
+
+var createTables = []string{
+	"CREATE TABLE IF NOT EXISTS schema_migrations (version integer PRIMARY KEY, name text NOT NULL, checksum text NOT NULL DEFAULT '', applied_at datetime NOT NULL)",
+	"CREATE TABLE IF NOT EXISTS schema_migrations_lock (id integer PRIMARY KEY, owner text NOT NULL, locked_at datetime NOT NULL)",
+}
+
+const (
+	lockID            = 1
+	lockRetryInterval = 500 * time.Millisecond
+)
+
+type migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+	options    Options
+	// owner identifies the lock rows of this process.
+	owner string
+}
+
+// NewMigrator returns a Migrator applying migrations, sorted by version, to
+// db.
+func NewMigrator(db *gorm.DB, migrations []Migration, options Options) Migrator {
+	hostname, _ := os.Hostname()
+	return &migrator{
+		db:         db,
+		migrations: migrations,
+		options:    options,
+		owner:      fmt.Sprintf("%s:%d", hostname, os.Getpid()),
+	}
+}
+
+func (m *migrator) Up(ctx context.Context, steps int) ([]Migration, error) {
+	var applied []Migration
+	err := m.withLock(ctx, func() error {
+		statuses, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		var pending, modified []Migration
+		for _, status := range statuses {
+			switch {
+			case status.Modified:
+				modified = append(modified, status.Migration)
+			case status.AppliedAt == nil:
+				pending = append(pending, status.Migration)
+			}
+		}
+		if len(modified) > 0 {
+			return fmt.Errorf("%w: %v; restore them, or redo the last one", ErrModified, modified)
+		}
+		for _, migration := range pending {
+			if steps > 0 && len(applied) == steps {
+				break
+			}
+			if err := m.apply(ctx, migration); err != nil {
+				return err
+			}
+			applied = append(applied, migration)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+func (m *migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var reverted []Migration
+	err := m.withLock(ctx, func() error {
+		applied, err := m.applied(ctx)
+		if err != nil {
+			return err
+		}
+		for i := len(applied) - 1; i >= 0 && len(reverted) < steps; i-- {
+			if err := m.revert(ctx, applied[i]); err != nil {
+				return err
+			}
+			reverted = append(reverted, applied[i])
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+func (m *migrator) Redo(ctx context.Context) (Migration, error) {
+	var redone Migration
+	err := m.withLock(ctx, func() error {
+		applied, err := m.applied(ctx)
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			return fmt.Errorf("no migration applied")
+		}
+		redone = applied[len(applied)-1]
+		if err := m.revert(ctx, redone); err != nil {
+			return err
+		}
+		return m.apply(ctx, redone)
+	})
+	return redone, err
+}
+
+func (m *migrator) Status(ctx context.Context) ([]Status, error) {
+	rows, err := m.rows(ctx)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := Status{Migration: migration}
+		if row, ok := rows[migration.Version]; ok {
+			status.AppliedAt = &row.AppliedAt
+			status.Modified = row.Checksum != "" && row.Checksum != migration.Checksum
+			delete(rows, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+	for _, row := range rows {
+		row := row
+		statuses = append(statuses, Status{
+			Migration: Migration{Version: row.Version, Name: row.Name},
+			AppliedAt: &row.AppliedAt,
+			Unknown:   true,
+		})
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+	return statuses, nil
+}
+
+func (m *migrator) Pending(ctx context.Context) ([]Migration, error) {
+	rows, err := m.rows(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var pending []Migration
+	for _, migration := range m.migrations {
+		if _, ok := rows[migration.Version]; !ok {
+			pending = append(pending, migration)
+		}
+	}
+	return pending, nil
+}
+
+// applied returns the applied migrations this build knows, by version.
+// Reverting an unknown one is impossible, so it is an error.
+func (m *migrator) applied(ctx context.Context) ([]Migration, error) {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var applied []Migration
+	for _, status := range statuses {
+		if status.Unknown {
+			return nil, fmt.Errorf("migration %s was applied by a newer build", status.Migration)
+		}
+		if status.AppliedAt != nil {
+			applied = append(applied, status.Migration)
+		}
+	}
+	return applied, nil
+}
+
+// rows returns the rows of schema_migrations by version; none before the
+// first migration.
+func (m *migrator) rows(ctx context.Context) (map[int64]schemaMigration, error) {
+	db := m.db.WithContext(ctx)
+	rows := map[int64]schemaMigration{}
+	if !db.Migrator().HasTable(&schemaMigration{}) {
+		return rows, nil
+	}
+	var applied []schemaMigration
+	if err := db.Find(&applied).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range applied {
+		rows[row.Version] = row
+	}
+	return rows, nil
+}
+
+// session runs the statements of migrations without logging them: a
+// failing one would log the whole migration, and its error is returned
+// with the name of the migration instead.
+func (m *migrator) session(ctx context.Context) *gorm.DB {
+	return m.db.Session(&gorm.Session{Context: ctx, Logger: m.db.Logger.LogMode(logger.Silent)})
+}
+
+func (m *migrator) apply(ctx context.Context, migration Migration) error {
+	start := time.Now()
+	err := m.session(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := migration.Up(tx); err != nil {
+			return err
+		}
+		return tx.Create(&schemaMigration{
+			Version:   migration.Version,
+			Name:      migration.Name,
+			Checksum:  migration.Checksum,
+			AppliedAt: time.Now().UTC(),
+		}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("error applying migration %s: %w", migration, err)
+	}
+	m.refreshLock(ctx)
+	slog.InfoContext(ctx, "migration applied", "version", migration.Version, "name", migration.Name,
+		"elapsed_ms", time.Since(start).Milliseconds())
+	return nil
+}
+
+func (m *migrator) revert(ctx context.Context, migration Migration) error {
+	if migration.Down == nil {
+		return fmt.Errorf("migration %s cannot be reverted", migration)
+	}
+	start := time.Now()
+	err := m.session(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := migration.Down(tx); err != nil {
+			return err
+		}
+		return tx.Delete(&schemaMigration{}, migration.Version).Error
+	})
+	if err != nil {
+		return fmt.Errorf("error reverting migration %s: %w", migration, err)
+	}
+	m.refreshLock(ctx)
+	slog.InfoContext(ctx, "migration reverted", "version", migration.Version, "name", migration.Name,
+		"elapsed_ms", time.Since(start).Milliseconds())
+	return nil
+}
+
+// withLock runs fn holding the migration lock, the only row of
+// schema_migrations_lock. It retries until the lock timeout while another
+// process holds it, and takes over locks older than the lock expiry.
+func (m *migrator) withLock(ctx context.Context, fn func() error) error {
+	db := m.db.WithContext(ctx)
+	if err := m.createTables(db); err != nil {
+		return err
+	}
+	deadline := time.Now().Add(m.options.LockTimeout)
+	for {
+		// The primary key lets a single row in, the lock holder's.
+		result := db.Exec("INSERT OR IGNORE INTO schema_migrations_lock (id, owner, locked_at) VALUES (?, ?, ?)",
+			lockID, m.owner, time.Now().UTC())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 1 {
+			break
+		}
+
+		var holder schemaMigrationLock
+		if err := db.Limit(1).Find(&holder, lockID).Error; err != nil {
+			return err
+		}
+		if holder.Owner != "" && time.Since(holder.LockedAt) > m.options.LockExpiry {
+			// Only the expired row is removed, not one a new holder just
+			// took.
+			if err := db.Where("owner = ? AND locked_at = ?", holder.Owner, holder.LockedAt).Delete(&holder).Error; err != nil {
+				return err
+			}
+			slog.WarnContext(ctx, "took over expired migration lock", "owner", holder.Owner, "locked_at", holder.LockedAt)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%w by %s since %s; it is taken over once older than %s",
+				ErrLocked, holder.Owner, holder.LockedAt.Format(time.RFC3339), m.options.LockExpiry)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(lockRetryInterval):
+		}
+	}
+	defer func() {
+		// The lock is released even when ctx is done.
+		if err := m.db.Where("owner = ?", m.owner).Delete(&schemaMigrationLock{}, lockID).Error; err != nil {
+			slog.Error("error releasing migration lock", "error", err)
+		}
+	}()
+	return fn()
+}
+
+// refreshLock keeps the lock from expiring while migrations run.
+func (m *migrator) refreshLock(ctx context.Context) {
+	err := m.db.WithContext(ctx).Model(&schemaMigrationLock{}).Where("id = ? AND owner = ?", lockID, m.owner).
+		Update("locked_at", time.Now().UTC()).Error
+	if err != nil {
+		slog.WarnContext(ctx, "error refreshing migration lock", "error", err)
+	}
+}
+
+// createTables creates the tables of the migrator.
+func (m *migrator) createTables(db *gorm.DB) error {
+	for _, createTable := range createTables {
+		if err := db.Exec(createTable).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
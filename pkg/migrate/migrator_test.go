@@ -0,0 +1,295 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"os"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+type MigratorTestSuite struct {
+	suite.Suite
+	tmpDBFile  *os.File
+	db         *gorm.DB
+	migrations []Migration
+}
+
+func (s *MigratorTestSuite) SetupTest() {
+	f, err := os.CreateTemp("", "test.*.db")
+	if err != nil {
+		panic(err)
+	}
+	s.tmpDBFile = f
+	s.db, err = gorm.Open(sqlite.Open(f.Name()), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		panic(err)
+	}
+	// The second migration fails unless the first one ran before it.
+	s.migrations, err = load(fstest.MapFS{
+		"1_create_items.up.sql":     {Data: []byte("CREATE TABLE items (id integer PRIMARY KEY)")},
+		"1_create_items.down.sql":   {Data: []byte("DROP TABLE items")},
+		"2_add_item_name.up.sql":    {Data: []byte("ALTER TABLE items ADD COLUMN name text")},
+		"2_add_item_name.down.sql":  {Data: []byte("ALTER TABLE items DROP COLUMN name")},
+		"3_create_orders.up.sql":    {Data: []byte("CREATE TABLE orders (id integer PRIMARY KEY)")},
+		"3_create_orders.down.sql":  {Data: []byte("DROP TABLE orders")},
+		"README.md":                 {Data: []byte("not a migration")},
+		"4_unrelated.txt":           {Data: []byte("not a migration")},
+		"5_irreversible.up.sql.bak": {Data: []byte("not a migration")},
+	}, nil)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (s *MigratorTestSuite) TearDownTest() {
+	if db, err := s.db.DB(); err == nil {
+		db.Close()
+	}
+	os.Remove(s.tmpDBFile.Name())
+}
+
+func (s *MigratorTestSuite) newMigrator(migrations []Migration) Migrator {
+	return NewMigrator(s.db, migrations, Options{LockTimeout: time.Second, LockExpiry: time.Minute})
+}
+
+func (s *MigratorTestSuite) versions(migrations []Migration) []int64 {
+	versions := make([]int64, len(migrations))
+	for i, migration := range migrations {
+		versions[i] = migration.Version
+	}
+	return versions
+}
+
+func (s *MigratorTestSuite) TestLoadOrdersMigrations() {
+	goMigration := Migration{Version: 2, Name: "backfill", Up: func(tx *gorm.DB) error { return nil }}
+	got, err := load(fstest.MapFS{
+		"3_c.up.sql":   {Data: []byte("SELECT 3")},
+		"1_a.up.sql":   {Data: []byte("SELECT 1")},
+		"1_a.down.sql": {Data: []byte("SELECT 1")},
+	}, []Migration{goMigration})
+	s.Nil(err)
+	s.Equal([]int64{1, 2, 3}, s.versions(got))
+	s.Equal("1_a", got[0].String())
+	s.NotNil(got[0].Down)
+	s.Nil(got[1].Down)
+	s.Nil(got[2].Down)
+	s.Len(got[0].Checksum, 64)
+	s.Empty(got[1].Checksum)
+	s.NotEqual(got[0].Checksum, got[2].Checksum)
+}
+
+func (s *MigratorTestSuite) TestLoadRejectsDuplicateVersions() {
+	_, err := load(fstest.MapFS{
+		"1_a.up.sql": {Data: []byte("SELECT 1")},
+		"1_b.up.sql": {Data: []byte("SELECT 1")},
+	}, nil)
+	s.ErrorContains(err, "duplicate migration version 1")
+
+	_, err = load(fstest.MapFS{
+		"1_a.up.sql": {Data: []byte("SELECT 1")},
+	}, []Migration{{Version: 1, Name: "b", Up: func(tx *gorm.DB) error { return nil }}})
+	s.ErrorContains(err, "duplicate migration version 1")
+}
+
+func (s *MigratorTestSuite) TestLoadRejectsMigrationWithoutUp() {
+	_, err := load(fstest.MapFS{
+		"1_a.down.sql": {Data: []byte("SELECT 1")},
+	}, nil)
+	s.ErrorContains(err, "migration 1_a has no up")
+}
+
+func (s *MigratorTestSuite) TestUpAppliesPendingInOrder() {
+	m := s.newMigrator(s.migrations)
+	applied, err := m.Up(context.Background(), 0)
+	s.Nil(err)
+	s.Equal([]int64{1, 2, 3}, s.versions(applied))
+	s.True(s.db.Migrator().HasColumn("items", "name"))
+	s.True(s.db.Migrator().HasTable("orders"))
+
+	applied, err = m.Up(context.Background(), 0)
+	s.Nil(err)
+	s.Empty(applied)
+	pending, err := m.Pending(context.Background())
+	s.Nil(err)
+	s.Empty(pending)
+}
+
+func (s *MigratorTestSuite) TestUpSteps() {
+	m := s.newMigrator(s.migrations)
+	applied, err := m.Up(context.Background(), 2)
+	s.Nil(err)
+	s.Equal([]int64{1, 2}, s.versions(applied))
+
+	pending, err := m.Pending(context.Background())
+	s.Nil(err)
+	s.Equal([]int64{3}, s.versions(pending))
+	statuses, err := m.Status(context.Background())
+	s.Nil(err)
+	s.Len(statuses, 3)
+	s.NotNil(statuses[0].AppliedAt)
+	s.NotNil(statuses[1].AppliedAt)
+	s.Nil(statuses[2].AppliedAt)
+}
+
+func (s *MigratorTestSuite) TestUpFailedMigrationRollsBack() {
+	migrations := append(s.migrations[:1:1], Migration{
+		Version: 2,
+		Name:    "broken",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.Exec("CREATE TABLE orders (id integer PRIMARY KEY)").Error; err != nil {
+				return err
+			}
+			return tx.Exec("INSERT INTO missing VALUES (1)").Error
+		},
+	})
+	applied, err := s.newMigrator(migrations).Up(context.Background(), 0)
+	s.ErrorContains(err, "error applying migration 2_broken")
+	s.Equal([]int64{1}, s.versions(applied))
+	s.False(s.db.Migrator().HasTable("orders"))
+
+	pending, err := s.newMigrator(migrations).Pending(context.Background())
+	s.Nil(err)
+	s.Equal([]int64{2}, s.versions(pending))
+}
+
+func (s *MigratorTestSuite) TestUpRejectsModifiedMigration() {
+	_, err := s.newMigrator(s.migrations).Up(context.Background(), 2)
+	s.Nil(err)
+
+	modified := append([]Migration(nil), s.migrations...)
+	modified[0].Checksum = "changed"
+	m := s.newMigrator(modified)
+	applied, err := m.Up(context.Background(), 0)
+	s.True(errors.Is(err, ErrModified))
+	s.Empty(applied)
+	s.False(s.db.Migrator().HasTable("orders"))
+
+	statuses, err := m.Status(context.Background())
+	s.Nil(err)
+	s.True(statuses[0].Modified)
+	s.False(statuses[1].Modified)
+}
+
+func (s *MigratorTestSuite) TestDownRevertsInReverseOrder() {
+	m := s.newMigrator(s.migrations)
+	_, err := m.Up(context.Background(), 0)
+	s.Nil(err)
+
+	reverted, err := m.Down(context.Background(), 2)
+	s.Nil(err)
+	s.Equal([]int64{3, 2}, s.versions(reverted))
+	s.False(s.db.Migrator().HasTable("orders"))
+	s.False(s.db.Migrator().HasColumn("items", "name"))
+	s.True(s.db.Migrator().HasTable("items"))
+
+	pending, err := m.Pending(context.Background())
+	s.Nil(err)
+	s.Equal([]int64{2, 3}, s.versions(pending))
+}
+
+func (s *MigratorTestSuite) TestDownIrreversibleMigration() {
+	migrations := append(s.migrations[:1:1], Migration{
+		Version: 2,
+		Name:    "irreversible",
+		Up:      func(tx *gorm.DB) error { return nil },
+	})
+	m := s.newMigrator(migrations)
+	_, err := m.Up(context.Background(), 0)
+	s.Nil(err)
+
+	reverted, err := m.Down(context.Background(), 2)
+	s.ErrorContains(err, "migration 2_irreversible cannot be reverted")
+	s.Empty(reverted)
+	pending, err := m.Pending(context.Background())
+	s.Nil(err)
+	s.Empty(pending)
+}
+
+func (s *MigratorTestSuite) TestDownUnknownMigration() {
+	_, err := s.newMigrator(s.migrations).Up(context.Background(), 0)
+	s.Nil(err)
+
+	m := s.newMigrator(s.migrations[:2])
+	statuses, err := m.Status(context.Background())
+	s.Nil(err)
+	s.Len(statuses, 3)
+	s.True(statuses[2].Unknown)
+
+	_, err = m.Down(context.Background(), 1)
+	s.ErrorContains(err, "migration 3_create_orders was applied by a newer build")
+	s.True(s.db.Migrator().HasTable("orders"))
+}
+
+func (s *MigratorTestSuite) TestRedo() {
+	m := s.newMigrator(s.migrations)
+	_, err := m.Up(context.Background(), 0)
+	s.Nil(err)
+	s.Nil(s.db.Exec("INSERT INTO orders (id) VALUES (1)").Error)
+
+	redone, err := m.Redo(context.Background())
+	s.Nil(err)
+	s.Equal(int64(3), redone.Version)
+	var count int64
+	s.Nil(s.db.Table("orders").Count(&count).Error)
+	s.Equal(int64(0), count)
+
+	modified := append([]Migration(nil), s.migrations...)
+	modified[2].Checksum = "changed"
+	_, err = s.newMigrator(modified).Redo(context.Background())
+	s.Nil(err)
+	statuses, err := s.newMigrator(modified).Status(context.Background())
+	s.Nil(err)
+	s.False(statuses[2].Modified)
+}
+
+func (s *MigratorTestSuite) TestLockHeldByAnotherProcess() {
+	s.Nil(s.db.Exec(createTables[1]).Error)
+	s.Nil(s.db.Create(&schemaMigrationLock{ID: lockID, Owner: "other:1", LockedAt: time.Now().UTC()}).Error)
+
+	m := NewMigrator(s.db, s.migrations, Options{LockExpiry: time.Minute})
+	applied, err := m.Up(context.Background(), 0)
+	s.True(errors.Is(err, ErrLocked))
+	s.ErrorContains(err, "by other:1")
+	s.Empty(applied)
+	s.False(s.db.Migrator().HasTable("items"))
+
+	var holder schemaMigrationLock
+	s.Nil(s.db.First(&holder, lockID).Error)
+	s.Equal("other:1", holder.Owner)
+}
+
+func (s *MigratorTestSuite) TestLockExpiredIsTakenOver() {
+	s.Nil(s.db.Exec(createTables[1]).Error)
+	s.Nil(s.db.Create(&schemaMigrationLock{ID: lockID, Owner: "other:1", LockedAt: time.Now().Add(-time.Hour).UTC()}).Error)
+
+	m := NewMigrator(s.db, s.migrations, Options{LockExpiry: time.Minute})
+	applied, err := m.Up(context.Background(), 0)
+	s.Nil(err)
+	s.Len(applied, 3)
+
+	var count int64
+	s.Nil(s.db.Model(&schemaMigrationLock{}).Count(&count).Error)
+	s.Equal(int64(0), count)
+}
+
+func (s *MigratorTestSuite) TestLockReleasedAfterError() {
+	migrations := []Migration{{Version: 1, Name: "broken", Up: func(tx *gorm.DB) error {
+		return errors.New("broken")
+	}}}
+	_, err := s.newMigrator(migrations).Up(context.Background(), 0)
+	s.ErrorContains(err, "broken")
+
+	var count int64
+	s.Nil(s.db.Model(&schemaMigrationLock{}).Count(&count).Error)
+	s.Equal(int64(0), count)
+}
+
+func TestMigratorTestSuite(t *testing.T) {
+	suite.Run(t, new(MigratorTestSuite))
+}
@@ -0,0 +1,85 @@
+package ratelimit
+
+import (
+	"context"
+	"crud-customer/config"
+	"math"
+	"sync"
+	"time"
+)
+
+const sweepInterval = time.Minute
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	fullAt  time.Time
+}
+
+// memoryStore keeps buckets in process memory, so limits are per instance.
+type memoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func (m *memoryStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	now := m.now()
+	capacity := limit.capacity()
+	rate := limit.rate()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sweep(now)
+
+	b, ok := m.buckets[key]
+	if !ok {
+		b = &bucket{tokens: capacity}
+		m.buckets[key] = b
+	} else {
+		b.tokens = math.Min(capacity, b.tokens+now.Sub(b.updated).Seconds()*rate)
+	}
+	b.updated = now
+
+	result := Result{Limit: int(capacity)}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = seconds((1 - b.tokens) / rate)
+	}
+	result.Remaining = int(b.tokens)
+	result.ResetAfter = seconds((capacity - b.tokens) / rate)
+	b.fullAt = now.Add(result.ResetAfter)
+	return result, nil
+}
+
+// sweep drops buckets that have refilled completely, since a full bucket
+// behaves the same as a missing one.
+func (m *memoryStore) sweep(now time.Time) {
+	if now.Sub(m.lastSweep) < sweepInterval {
+		return
+	}
+	m.lastSweep = now
+	for key, b := range m.buckets {
+		if !b.fullAt.After(now) {
+			delete(m.buckets, key)
+		}
+	}
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+func NewMemoryStore(cfg *config.Config) (Store, error) {
+	return &memoryStore{
+		buckets: map[string]*bucket{},
+		now:     time.Now,
+	}, nil
+}
+
+func init() {
+	RegisterStore("memory", NewMemoryStore)
+}
@@ -0,0 +1,102 @@
+package ratelimit
+
+import (
+	"context"
+	"crud-customer/config"
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Limit is a token bucket holding at most Burst tokens, refilled with
+// Requests tokens every Period. A zero Burst means Requests.
+type Limit struct {
+	Requests int
+	Period   time.Duration
+	Burst    int
+}
+
+func (l Limit) capacity() float64 {
+	if l.Burst > 0 {
+		return float64(l.Burst)
+	}
+	return float64(l.Requests)
+}
+
+// rate returns the refill rate in tokens per second.
+func (l Limit) rate() float64 {
+	return float64(l.Requests) / l.Period.Seconds()
+}
+
+// Limits holds the limit of each group of routes. It can be replaced while
+// serving.
+type Limits struct {
+	fallback string
+	groups   atomic.Pointer[map[string]Limit]
+}
+
+// NewLimits returns limits where groups without a limit of their own share
+// the limit of the fallback group.
+func NewLimits(fallback string, groups map[string]Limit) *Limits {
+	l := &Limits{fallback: fallback}
+	l.Set(groups)
+	return l
+}
+
+func (l *Limits) Set(groups map[string]Limit) {
+	l.groups.Store(&groups)
+}
+
+// Get returns the limit of group and the group it belongs to, which is the
+// fallback group when group has no limit of its own. ok is false when
+// neither has a limit.
+func (l *Limits) Get(group string) (limit Limit, limitGroup string, ok bool) {
+	groups := *l.groups.Load()
+	if limit, ok = groups[group]; ok {
+		return limit, group, true
+	}
+	limit, ok = groups[l.fallback]
+	return limit, l.fallback, ok
+}
+
+// Result is the state of a bucket after a Take.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// ResetAfter is the time until the bucket is full again.
+	ResetAfter time.Duration
+	// RetryAfter is the time until the next token, set when not Allowed.
+	RetryAfter time.Duration
+}
+
+// Store keeps the token buckets. Take must be atomic per key, so a store
+// shared between instances has to check and decrement in one operation.
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+type StoreFactory func(cfg *config.Config) (Store, error)
+
+var storeFactories = map[string]StoreFactory{}
+
+// RegisterStore makes a store available under name for the
+// server.rateLimit.store config.
+func RegisterStore(name string, factory StoreFactory) {
+	storeFactories[name] = factory
+}
+
+func NewStore(cfg *config.Config) (Store, error) {
+	factory, ok := storeFactories[cfg.Server.RateLimit.Store]
+	if !ok {
+		names := make([]string, 0, len(storeFactories))
+		for name := range storeFactories {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("unknown rate limit store %q, registered: %s", cfg.Server.RateLimit.Store, strings.Join(names, ", "))
+	}
+	return factory(cfg)
+}
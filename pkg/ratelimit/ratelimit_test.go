@@ -0,0 +1,153 @@
+package ratelimit
+
+import (
+	"context"
+	"crud-customer/config"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newTestStore() (*memoryStore, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	store, _ := NewMemoryStore(&config.Config{})
+	m := store.(*memoryStore)
+	m.now = clock.Now
+	return m, clock
+}
+
+func take(t *testing.T, store Store, key string, limit Limit) Result {
+	t.Helper()
+	result, err := store.Take(context.Background(), key, limit)
+	assert.NoError(t, err)
+	return result
+}
+
+func TestMemoryStoreBurst(t *testing.T) {
+	store, _ := newTestStore()
+	limit := Limit{Requests: 10, Period: time.Minute, Burst: 3}
+
+	for i := 2; i >= 0; i-- {
+		result := take(t, store, "a", limit)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 3, result.Limit)
+		assert.Equal(t, i, result.Remaining)
+	}
+	result := take(t, store, "a", limit)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+	// A token every 6s.
+	assert.Equal(t, 6*time.Second, result.RetryAfter)
+	assert.Equal(t, 18*time.Second, result.ResetAfter)
+}
+
+func TestMemoryStoreBurstDefaultsToRequests(t *testing.T) {
+	store, _ := newTestStore()
+	limit := Limit{Requests: 2, Period: time.Second}
+
+	assert.True(t, take(t, store, "a", limit).Allowed)
+	assert.True(t, take(t, store, "a", limit).Allowed)
+	result := take(t, store, "a", limit)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 2, result.Limit)
+	assert.Equal(t, 500*time.Millisecond, result.RetryAfter)
+}
+
+func TestMemoryStoreRefill(t *testing.T) {
+	store, clock := newTestStore()
+	limit := Limit{Requests: 10, Period: time.Minute, Burst: 2}
+
+	take(t, store, "a", limit)
+	take(t, store, "a", limit)
+	assert.False(t, take(t, store, "a", limit).Allowed)
+
+	clock.Advance(3 * time.Second)
+	result := take(t, store, "a", limit)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 3*time.Second, result.RetryAfter)
+
+	clock.Advance(3 * time.Second)
+	result = take(t, store, "a", limit)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+
+	// Refills stop at the burst.
+	clock.Advance(time.Hour)
+	result = take(t, store, "a", limit)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 1, result.Remaining)
+}
+
+func TestMemoryStoreKeys(t *testing.T) {
+	store, _ := newTestStore()
+	limit := Limit{Requests: 1, Period: time.Minute}
+
+	assert.True(t, take(t, store, "a", limit).Allowed)
+	assert.False(t, take(t, store, "a", limit).Allowed)
+	assert.True(t, take(t, store, "b", limit).Allowed)
+}
+
+func TestMemoryStoreSweep(t *testing.T) {
+	store, clock := newTestStore()
+	limit := Limit{Requests: 1, Period: time.Second}
+
+	take(t, store, "a", limit)
+	clock.Advance(2 * sweepInterval)
+	take(t, store, "b", limit)
+	assert.NotContains(t, store.buckets, "a")
+	assert.Contains(t, store.buckets, "b")
+}
+
+func TestLimitsGet(t *testing.T) {
+	def := Limit{Requests: 100, Period: time.Minute}
+	bulk := Limit{Requests: 10, Period: time.Minute, Burst: 2}
+	limits := NewLimits("default", map[string]Limit{"default": def, "bulk": bulk})
+
+	limit, group, ok := limits.Get("bulk")
+	assert.True(t, ok)
+	assert.Equal(t, bulk, limit)
+	assert.Equal(t, "bulk", group)
+
+	limit, group, ok = limits.Get("auth")
+	assert.True(t, ok)
+	assert.Equal(t, def, limit)
+	assert.Equal(t, "default", group)
+
+	limits.Set(map[string]Limit{"bulk": bulk})
+	_, _, ok = limits.Get("auth")
+	assert.False(t, ok)
+	_, _, ok = limits.Get("bulk")
+	assert.True(t, ok)
+}
+
+func TestNewStore(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Server.RateLimit.Store = "memory"
+	store, err := NewStore(cfg)
+	assert.NoError(t, err)
+	assert.IsType(t, &memoryStore{}, store)
+
+	RegisterStore("test", func(cfg *config.Config) (Store, error) {
+		return NewMemoryStore(cfg)
+	})
+	defer delete(storeFactories, "test")
+	cfg.Server.RateLimit.Store = "test"
+	_, err = NewStore(cfg)
+	assert.NoError(t, err)
+
+	cfg.Server.RateLimit.Store = "redis"
+	_, err = NewStore(cfg)
+	assert.EqualError(t, err, `unknown rate limit store "redis", registered: memory, test`)
+}
@@ -0,0 +1,229 @@
+package redact
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Masks a field can be tagged with, e.g. `redact:"partial"`. Only string
+// and *string fields can be tagged.
+const (
+	// MaskPartial keeps the first letter of each word: "John Doe" becomes
+	// "J*** D***".
+	MaskPartial = "partial"
+	// MaskFull hides the whole value.
+	MaskFull = "full"
+)
+
+const (
+	redacted     = "[REDACTED]"
+	redactedJSON = `"` + redacted + `"`
+)
+
+// Mask returns value masked as mask. Unknown masks hide the whole value.
+func Mask(mask string, value string) string {
+	if value == "" {
+		return ""
+	}
+	if mask != MaskPartial {
+		return redacted
+	}
+	words := strings.Fields(value)
+	for i, word := range words {
+		r, _ := utf8.DecodeRuneInString(word)
+		words[i] = string(r) + "***"
+	}
+	return strings.Join(words, " ")
+}
+
+// Apply masks, in place, every tagged field reachable from v through
+// pointers, structs and slices. v must be a pointer. Tagged *string fields
+// are pointed at a masked copy, so the original string is left untouched.
+func Apply(v interface{}) {
+	apply(reflect.ValueOf(v))
+}
+
+func apply(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if !v.IsNil() {
+			apply(v.Elem())
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			apply(v.Index(i))
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			if mask, ok := field.Tag.Lookup("redact"); ok {
+				maskValue(v.Field(i), mask)
+				continue
+			}
+			apply(v.Field(i))
+		}
+	}
+}
+
+func maskValue(v reflect.Value, mask string) {
+	if !v.CanSet() {
+		return
+	}
+	switch {
+	case v.Kind() == reflect.String:
+		v.SetString(Mask(mask, v.String()))
+	case v.Kind() == reflect.Pointer && v.Type().Elem().Kind() == reflect.String && !v.IsNil():
+		masked := reflect.New(v.Type().Elem())
+		masked.Elem().SetString(Mask(mask, v.Elem().String()))
+		v.Set(masked)
+	}
+}
+
+// Scrub replaces, in text, the value of every tagged field of v with its
+// masked form. It is meant for messages that may quote request data, such as
+// errors, before they are logged or returned.
+func Scrub(text string, v interface{}) string {
+	var values [][2]string
+	collect(reflect.ValueOf(v), func(value string, mask string) {
+		if value != "" {
+			values = append(values, [2]string{value, Mask(mask, value)})
+		}
+	})
+	// Longest first, so a value containing another is replaced whole.
+	sort.Slice(values, func(i, j int) bool {
+		return len(values[i][0]) > len(values[j][0])
+	})
+	for _, value := range values {
+		text = strings.ReplaceAll(text, value[0], value[1])
+	}
+	return text
+}
+
+func collect(v reflect.Value, fn func(value string, mask string)) {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if !v.IsNil() {
+			collect(v.Elem(), fn)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			collect(v.Index(i), fn)
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			mask, ok := field.Tag.Lookup("redact")
+			if !ok {
+				collect(v.Field(i), fn)
+				continue
+			}
+			value := v.Field(i)
+			if value.Kind() == reflect.Pointer && !value.IsNil() {
+				value = value.Elem()
+			}
+			if value.Kind() == reflect.String {
+				fn(value.String(), mask)
+			}
+		}
+	}
+}
+
+// MaskDiff masks, in diff, a JSON object mapping field names to their
+// before and after values, the values of the tagged fields of the struct v,
+// matched by their JSON names. A diff that is not such an object is hidden
+// whole, as it cannot be told apart from PII, and replaced with a JSON
+// string so it stays valid JSON.
+func MaskDiff(diff string, v interface{}) string {
+	var changes map[string]map[string]interface{}
+	if err := json.Unmarshal([]byte(diff), &changes); err != nil {
+		return redactedJSON
+	}
+	masks := jsonMasks(reflect.TypeOf(v))
+	for name, change := range changes {
+		mask, ok := masks[name]
+		if !ok {
+			continue
+		}
+		for side, value := range change {
+			switch value := value.(type) {
+			case nil:
+			case string:
+				change[side] = Mask(mask, value)
+			default:
+				change[side] = redacted
+			}
+		}
+	}
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return redactedJSON
+	}
+	return string(data)
+}
+
+// jsonMasks returns the masks of the tagged fields of the struct t by their
+// JSON names.
+func jsonMasks(t reflect.Type) map[string]string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	masks := map[string]string{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		mask, ok := field.Tag.Lookup("redact")
+		if !ok {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" {
+			name = field.Name
+		}
+		masks[name] = mask
+	}
+	return masks
+}
+
+// QueryParams returns the query parameter names, from the `query` tags, of
+// the tagged fields of the struct v.
+func QueryParams(v interface{}) []string {
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	var params []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if _, ok := field.Tag.Lookup("redact"); !ok {
+			continue
+		}
+		if name, _, _ := strings.Cut(field.Tag.Get("query"), ","); name != "" {
+			params = append(params, name)
+		}
+	}
+	return params
+}
+
+type maskingKey struct{}
+
+// WithMasking marks ctx as belonging to a caller that may only see masked
+// PII.
+func WithMasking(ctx context.Context) context.Context {
+	return context.WithValue(ctx, maskingKey{}, true)
+}
+
+func MaskingFromContext(ctx context.Context) bool {
+	masking, _ := ctx.Value(maskingKey{}).(bool)
+	return masking
+}
@@ -0,0 +1,122 @@
+package redact
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type person struct {
+	Name     string  `json:"name" query:"name" redact:"partial"`
+	Nickname *string `json:"nickname,omitempty" query:"nickname,omitempty" redact:"partial"`
+	Secret   string  `json:"secret" redact:"full"`
+	Age      int     `json:"age" query:"age"`
+	Friends  []*person
+}
+
+func TestMask(t *testing.T) {
+	testCases := []struct {
+		name  string
+		mask  string
+		value string
+		want  string
+	}{
+		{name: "partial", mask: MaskPartial, value: "John Doe", want: "J*** D***"},
+		{name: "partial collapses spaces", mask: MaskPartial, value: "  John   Doe ", want: "J*** D***"},
+		{name: "partial multibyte", mask: MaskPartial, value: "Élodie Ñúñez", want: "É*** Ñ***"},
+		{name: "full", mask: MaskFull, value: "John Doe", want: "[REDACTED]"},
+		{name: "unknown mask", mask: "other", value: "John Doe", want: "[REDACTED]"},
+		{name: "empty", mask: MaskFull, value: "", want: ""},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Mask(tt.mask, tt.value))
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	nickname := "Johnny Boy"
+	friend := &person{Name: "Jane Roe", Secret: "s3cret", Age: 30}
+	p := &person{Name: "John Doe", Nickname: &nickname, Secret: "hunter2", Age: 20, Friends: []*person{friend, nil}}
+
+	Apply(p)
+	assert.Equal(t, "J*** D***", p.Name)
+	assert.Equal(t, "J*** B***", *p.Nickname)
+	assert.Equal(t, "[REDACTED]", p.Secret)
+	assert.Equal(t, 20, p.Age)
+	assert.Equal(t, "J*** R***", friend.Name)
+	assert.Equal(t, "[REDACTED]", friend.Secret)
+	// The string pointed at is not the caller's to change.
+	assert.Equal(t, "Johnny Boy", nickname)
+}
+
+func TestApplyUnaddressable(t *testing.T) {
+	p := person{Name: "John Doe"}
+	Apply(p)
+	assert.Equal(t, "John Doe", p.Name)
+}
+
+func TestScrub(t *testing.T) {
+	nickname := "John"
+	p := &person{Name: "John Doe", Nickname: &nickname, Secret: "hunter2"}
+
+	got := Scrub(`UNIQUE constraint failed: "John Doe" aka John, password hunter2`, p)
+	assert.Equal(t, `UNIQUE constraint failed: "J*** D***" aka J***, password [REDACTED]`, got)
+	assert.Equal(t, "nothing to scrub", Scrub("nothing to scrub", &person{}))
+	assert.Equal(t, "John Doe", Scrub("John Doe", nil))
+}
+
+func TestMaskDiff(t *testing.T) {
+	testCases := []struct {
+		name string
+		diff string
+		want string
+	}{
+		{
+			name: "masks tagged fields",
+			diff: `{"name":{"before":"John Doe","after":"Jane Roe"},"age":{"before":20,"after":21}}`,
+			want: `{"age":{"after":21,"before":20},"name":{"after":"J*** R***","before":"J*** D***"}}`,
+		},
+		{
+			name: "created",
+			diff: `{"name":{"after":"John Doe"},"secret":{"after":"hunter2"}}`,
+			want: `{"name":{"after":"J*** D***"},"secret":{"after":"[REDACTED]"}}`,
+		},
+		{
+			name: "null and non-string values",
+			diff: `{"nickname":{"before":null,"after":{"first":"John"}}}`,
+			want: `{"nickname":{"after":"[REDACTED]","before":null}}`,
+		},
+		{
+			name: "untagged fields",
+			diff: `{"age":{"before":20,"after":21}}`,
+			want: `{"age":{"after":21,"before":20}}`,
+		},
+		{
+			name: "not a diff",
+			diff: `["John Doe"]`,
+			want: `"[REDACTED]"`,
+		},
+		{
+			name: "invalid json",
+			diff: `{"name":`,
+			want: `"[REDACTED]"`,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.JSONEq(t, tt.want, MaskDiff(tt.diff, person{}))
+		})
+	}
+}
+
+func TestQueryParams(t *testing.T) {
+	assert.Equal(t, []string{"name", "nickname"}, QueryParams(&person{}))
+	assert.Equal(t, []string{"name", "nickname"}, QueryParams(person{}))
+}
+
+func TestMaskingFromContext(t *testing.T) {
+	assert.False(t, MaskingFromContext(context.Background()))
+	assert.True(t, MaskingFromContext(WithMasking(context.Background())))
+}
@@ -0,0 +1,30 @@
+package reqctx
+
+import "context"
+
+type (
+	requestIDKey struct{}
+	sourceIPKey  struct{}
+)
+
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext returns the ID of the request ctx belongs to, or ""
+// outside a request.
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+func WithSourceIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, sourceIPKey{}, ip)
+}
+
+// SourceIPFromContext returns the client IP of the request ctx belongs to,
+// or "" outside a request.
+func SourceIPFromContext(ctx context.Context) string {
+	ip, _ := ctx.Value(sourceIPKey{}).(string)
+	return ip
+}
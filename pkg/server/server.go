@@ -1,36 +1,110 @@
 package server
 
 import (
+	"context"
 	"crud-customer/config"
 	"crud-customer/pkg/echo_server"
+	"crud-customer/pkg/tlsconfig"
+	"errors"
+	"fmt"
 	"github.com/labstack/echo/v4"
 )
 
 type Server interface {
 	SetupServer()
-	HttpListening()
+	HttpListening() error
 	GetEchoApp() *echo.Echo
+	Shutdown(ctx context.Context) error
+	Close() error
+	// Reload applies the settings of cfg that are safe to change while
+	// serving: CORS, the timeout, the body limit and the log level.
+	Reload(cfg *config.Config)
 }
 
 type serverImpl struct {
 	echo_server.EchoServer
 	AppConfig *config.Config
+	// tlsReloader watches the certificate files, when TLS is enabled.
+	tlsReloader *tlsconfig.Reloader
+}
+
+// Shutdown stops the server gracefully, then stops watching the
+// certificate files.
+func (s *serverImpl) Shutdown(ctx context.Context) error {
+	return errors.Join(s.EchoServer.Shutdown(ctx), s.closeTLSReloader())
+}
+
+func (s *serverImpl) Close() error {
+	return errors.Join(s.EchoServer.Close(), s.closeTLSReloader())
+}
+
+func (s *serverImpl) closeTLSReloader() error {
+	if s.tlsReloader == nil {
+		return nil
+	}
+	return s.tlsReloader.Close()
 }
 
 func (s *serverImpl) GetEchoApp() *echo.Echo {
 	return s.EchoServer.App
 }
 
-func NewServer(cfg *config.Config) Server {
+func (s *serverImpl) Reload(cfg *config.Config) {
+	s.EchoServer.Reload(&echo_server.Config{
+		Timeout:      cfg.Server.Timeout,
+		AllowOrigins: cfg.Server.AllowOrigins,
+		AllowHeaders: []string{cfg.Server.APIKey.Header, cfg.Server.Tenant.Header},
+		BodyLimit:    cfg.Server.BodyLimit,
+		LogLevel:     cfg.Server.LogLevel,
+	})
+}
+
+func NewServer(cfg *config.Config) (Server, error) {
 	echoConf := &echo_server.Config{
 		Port:         cfg.Server.Port,
 		Timeout:      cfg.Server.Timeout,
 		AllowOrigins: cfg.Server.AllowOrigins,
 		BodyLimit:    cfg.Server.BodyLimit,
 		LogLevel:     cfg.Server.LogLevel,
+		AllowHeaders: []string{cfg.Server.APIKey.Header, cfg.Server.Tenant.Header},
+		Metrics:      cfg.Metrics.Enabled,
 	}
-	return &serverImpl{
-		EchoServer: echo_server.NewEchoServer(echoConf),
-		AppConfig:  cfg,
+	if cfg.Tracing.Enabled {
+		echoConf.TracingServiceName = cfg.Tracing.ServiceName
 	}
+	trustedProxies, err := echo_server.ParseTrustedProxies(cfg.Server.TrustedProxies)
+	if err != nil {
+		return nil, err
+	}
+	echoConf.IPExtractor = echo_server.NewIPExtractor(trustedProxies)
+	echoConf.TrustedProxies = trustedProxies
+	var tlsReloader *tlsconfig.Reloader
+	if cfg.Server.TLS.Enabled {
+		tlsReloader, err = tlsconfig.NewReloader(tlsconfig.Config{
+			CertFile:     cfg.Server.TLS.CertFile,
+			KeyFile:      cfg.Server.TLS.KeyFile,
+			MinVersion:   cfg.Server.TLS.MinVersion,
+			ClientCAFile: cfg.Server.TLS.ClientCAFile,
+			ClientAuth:   cfg.Server.TLS.ClientAuth,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error loading tls certificate: %w", err)
+		}
+		echoConf.TLS = tlsReloader.TLSConfig()
+	}
+	if cfg.Server.SecureHeaders.Enabled {
+		headers, ok := echo_server.SecureHeaders(cfg.Server.SecureHeaders.Preset, cfg.Server.SecureHeaders.Headers)
+		if !ok {
+			if tlsReloader != nil {
+				tlsReloader.Close()
+			}
+			return nil, fmt.Errorf("unknown secure headers preset %q", cfg.Server.SecureHeaders.Preset)
+		}
+		echoConf.SecureHeaders = headers
+	}
+	return &serverImpl{
+		EchoServer:  echo_server.NewEchoServer(echoConf),
+		AppConfig:   cfg,
+		tlsReloader: tlsReloader,
+	}, nil
 }
@@ -0,0 +1,112 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+const (
+	// DefaultTenant owns every row when multi-tenancy is disabled, and is used
+	// for work that does not come from a request, such as seeding.
+	DefaultTenant = "default"
+	// AnyTenant binds credentials to every tenant: the request names the one
+	// it acts on.
+	AnyTenant = "*"
+)
+
+var (
+	ErrInvalidTenant  = errors.New("tenant: invalid tenant id")
+	ErrTenantMismatch = errors.New("tenant: requested tenant does not match credentials")
+
+	tenantIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,62}$`)
+)
+
+// IsValidID reports whether tenantID is a well formed tenant ID: lowercase
+// letters, digits, '-' and '_', usable as a subdomain label.
+func IsValidID(tenantID string) bool {
+	return tenantIDPattern.MatchString(tenantID)
+}
+
+type tenantKey struct{}
+
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenantID)
+}
+
+// FromContext returns the tenant of ctx, or DefaultTenant when none is set.
+func FromContext(ctx context.Context) string {
+	if tenantID, ok := ctx.Value(tenantKey{}).(string); ok && tenantID != "" {
+		return tenantID
+	}
+	return DefaultTenant
+}
+
+type ResolverConfig struct {
+	// Header carries the tenant ID chosen by the caller.
+	Header string
+	// BaseDomain enables subdomain resolution: acme.<BaseDomain> is tenant acme.
+	BaseDomain string
+	// Default is used when nothing else names a tenant. Empty rejects such
+	// requests.
+	Default string
+}
+
+// Resolver works out the tenant of a request. A tenant bound to the
+// caller's credentials (a JWT claim or an API key's tenant) is authoritative:
+// a header or subdomain naming another tenant is rejected rather than
+// silently ignored. Credentials bound to no tenant act on the default
+// tenant; only those bound to AnyTenant may name another.
+type Resolver struct {
+	cfg ResolverConfig
+}
+
+// Resolve returns the tenant req acts on, given the tenant its credentials
+// are bound to: "" for none, or AnyTenant.
+func (r *Resolver) Resolve(req *http.Request, credentialTenant string) (string, error) {
+	requested := ""
+	if r.cfg.Header != "" {
+		requested = strings.TrimSpace(req.Header.Get(r.cfg.Header))
+	}
+	if requested == "" && r.cfg.BaseDomain != "" {
+		requested = subdomain(req.Host, r.cfg.BaseDomain)
+	}
+
+	tenantID := credentialTenant
+	switch credentialTenant {
+	case AnyTenant:
+		tenantID = requested
+	case "":
+		// Credentials bound to no tenant act on the default one.
+		tenantID = r.cfg.Default
+	}
+	if tenantID == "" {
+		tenantID = r.cfg.Default
+	}
+	if requested != "" && requested != tenantID {
+		return "", ErrTenantMismatch
+	}
+	if !IsValidID(tenantID) {
+		return "", ErrInvalidTenant
+	}
+	return tenantID, nil
+}
+
+func subdomain(host string, baseDomain string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.ToLower(host)
+	label, ok := strings.CutSuffix(host, "."+strings.ToLower(baseDomain))
+	if !ok || strings.Contains(label, ".") {
+		return ""
+	}
+	return label
+}
+
+func NewResolver(cfg ResolverConfig) *Resolver {
+	return &Resolver{cfg: cfg}
+}
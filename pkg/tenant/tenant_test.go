@@ -0,0 +1,53 @@
+package tenant
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	testCases := []struct {
+		name             string
+		cfg              ResolverConfig
+		credentialTenant string
+		header           string
+		host             string
+		want             string
+		wantErr          error
+	}{
+		{name: "bound credentials", credentialTenant: "acme", want: "acme"},
+		{name: "bound credentials naming their tenant", credentialTenant: "acme", header: "acme", want: "acme"},
+		{name: "bound credentials naming another tenant", credentialTenant: "acme", header: "other", wantErr: ErrTenantMismatch},
+		{name: "bound credentials on another subdomain", cfg: ResolverConfig{BaseDomain: "example.com"}, credentialTenant: "acme", host: "other.example.com", wantErr: ErrTenantMismatch},
+		{name: "unbound credentials act on the default tenant", want: "default"},
+		{name: "unbound credentials naming the default tenant", header: "default", want: "default"},
+		{name: "unbound credentials naming another tenant", header: "acme", wantErr: ErrTenantMismatch},
+		{name: "unbound credentials without a default tenant", cfg: ResolverConfig{Header: "X-Tenant-ID"}, wantErr: ErrInvalidTenant},
+		{name: "any tenant by header", credentialTenant: AnyTenant, header: "acme", want: "acme"},
+		{name: "any tenant by subdomain", cfg: ResolverConfig{BaseDomain: "example.com", Default: "default"}, credentialTenant: AnyTenant, host: "acme.example.com:8080", want: "acme"},
+		{name: "any tenant falls back to the default tenant", credentialTenant: AnyTenant, want: "default"},
+		{name: "any tenant without a default tenant", cfg: ResolverConfig{Header: "X-Tenant-ID"}, credentialTenant: AnyTenant, wantErr: ErrInvalidTenant},
+		{name: "malformed tenant", credentialTenant: AnyTenant, header: "Not Valid", wantErr: ErrInvalidTenant},
+		{name: "nested subdomain", cfg: ResolverConfig{BaseDomain: "example.com", Default: "default"}, credentialTenant: AnyTenant, host: "a.b.example.com", want: "default"},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.cfg
+			if cfg == (ResolverConfig{}) {
+				cfg = ResolverConfig{Header: "X-Tenant-ID", Default: DefaultTenant}
+			}
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set("X-Tenant-ID", tt.header)
+			}
+			if tt.host != "" {
+				req.Host = tt.host
+			}
+			got, err := NewResolver(cfg).Resolve(req, tt.credentialTenant)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
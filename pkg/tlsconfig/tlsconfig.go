@@ -0,0 +1,191 @@
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"github.com/fsnotify/fsnotify"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+)
+
+// Client certificate policies.
+const (
+	// ClientAuthNone does not ask for client certificates.
+	ClientAuthNone = "none"
+	// ClientAuthOptional verifies client certificates when they are sent.
+	ClientAuthOptional = "optional"
+	// ClientAuthRequire rejects handshakes without a valid client
+	// certificate.
+	ClientAuthRequire = "require"
+)
+
+// nextProtos are the protocols offered through ALPN. http.Server only adds
+// h2 to the config it is given, which GetConfigForClient replaces.
+var nextProtos = []string{"h2", "http/1.1"}
+
+var minVersions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+type Config struct {
+	CertFile string
+	KeyFile  string
+	// MinVersion is "1.2" or "1.3".
+	MinVersion string
+	// ClientCAFile holds the PEM encoded CAs client certificates are
+	// verified against.
+	ClientCAFile string
+	ClientAuth   string
+}
+
+// Reloader serves the certificate, key and client CAs of its config. The
+// files are watched and reloaded when they change, so certificates can be
+// renewed without a restart; files that fail to load keep the previous ones.
+type Reloader struct {
+	cfg        Config
+	minVersion uint16
+	clientAuth tls.ClientAuthType
+	// current is the config built from the files loaded last, shared by
+	// every handshake until the next reload.
+	current atomic.Pointer[tls.Config]
+	watcher *fsnotify.Watcher
+}
+
+// TLSConfig returns the config to serve with. Every handshake picks up the
+// files loaded last.
+func (r *Reloader) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: r.minVersion,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			return r.current.Load(), nil
+		},
+	}
+}
+
+func (r *Reloader) reload() error {
+	certificate, err := tls.LoadX509KeyPair(r.cfg.CertFile, r.cfg.KeyFile)
+	if err != nil {
+		return err
+	}
+	next := &tls.Config{
+		MinVersion:   r.minVersion,
+		Certificates: []tls.Certificate{certificate},
+		ClientAuth:   r.clientAuth,
+		NextProtos:   nextProtos,
+	}
+	if r.cfg.ClientCAFile != "" {
+		pem, err := os.ReadFile(r.cfg.ClientCAFile)
+		if err != nil {
+			return err
+		}
+		next.ClientCAs = x509.NewCertPool()
+		if !next.ClientCAs.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificates in %s", r.cfg.ClientCAFile)
+		}
+	}
+	r.current.Store(next)
+	return nil
+}
+
+func (r *Reloader) files() []string {
+	files := []string{r.cfg.CertFile, r.cfg.KeyFile}
+	if r.cfg.ClientCAFile != "" {
+		files = append(files, r.cfg.ClientCAFile)
+	}
+	return files
+}
+
+func (r *Reloader) isWatched(name string) bool {
+	// Kubernetes swaps mounted secrets through a "..data" symlink.
+	if strings.HasPrefix(filepath.Base(name), "..") {
+		return true
+	}
+	for _, file := range r.files() {
+		if filepath.Clean(name) == filepath.Clean(file) {
+			return true
+		}
+	}
+	return false
+}
+
+// watch reloads the files whenever their directories change. Directories
+// are watched rather than files so atomic renames are picked up.
+func (r *Reloader) watch() {
+	for {
+		select {
+		case event, ok := <-r.watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename|fsnotify.Remove) == 0 || !r.isWatched(event.Name) {
+				continue
+			}
+			if err := r.reload(); err != nil {
+				slog.Warn("keeping previous tls certificate, error reloading", "file", r.cfg.CertFile, "error", err)
+				continue
+			}
+			slog.Info("reloaded tls certificate", "file", r.cfg.CertFile)
+		case err, ok := <-r.watcher.Errors:
+			if !ok {
+				return
+			}
+			slog.Warn("error watching tls certificate", "file", r.cfg.CertFile, "error", err)
+		}
+	}
+}
+
+// Close stops watching the files; handshakes keep the files loaded last.
+// It can be called more than once.
+func (r *Reloader) Close() error {
+	return r.watcher.Close()
+}
+
+func NewReloader(cfg Config) (*Reloader, error) {
+	minVersion, ok := minVersions[cfg.MinVersion]
+	if !ok {
+		return nil, fmt.Errorf("unsupported tls version %q", cfg.MinVersion)
+	}
+	r := &Reloader{cfg: cfg, minVersion: minVersion}
+	switch cfg.ClientAuth {
+	case "", ClientAuthNone:
+		r.clientAuth = tls.NoClientCert
+	case ClientAuthOptional:
+		r.clientAuth = tls.VerifyClientCertIfGiven
+	case ClientAuthRequire:
+		r.clientAuth = tls.RequireAndVerifyClientCert
+	default:
+		return nil, fmt.Errorf("unsupported client auth %q", cfg.ClientAuth)
+	}
+	if r.clientAuth != tls.NoClientCert && cfg.ClientCAFile == "" {
+		return nil, errors.New("client auth requires a client ca file")
+	}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	dirs := map[string]bool{}
+	for _, file := range r.files() {
+		dir := filepath.Dir(file)
+		if dirs[dir] {
+			continue
+		}
+		dirs[dir] = true
+		if err := watcher.Add(dir); err != nil {
+			watcher.Close()
+			return nil, err
+		}
+	}
+	r.watcher = watcher
+	go r.watch()
+	return r, nil
+}
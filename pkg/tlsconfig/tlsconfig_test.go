@@ -0,0 +1,288 @@
+package tlsconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"github.com/stretchr/testify/suite"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type certificate struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+// newCertificate issues a certificate for name, signed by parent or
+// self-signed when parent is nil.
+func newCertificate(name string, parent *certificate) *certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	if err != nil {
+		panic(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		panic(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		panic(err)
+	}
+	return &certificate{cert: cert, key: key}
+}
+
+func (c *certificate) certPEM() []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.cert.Raw})
+}
+
+func (c *certificate) keyPEM() []byte {
+	der, err := x509.MarshalECPrivateKey(c.key)
+	if err != nil {
+		panic(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+}
+
+func (c *certificate) tlsCertificate() tls.Certificate {
+	certificate, err := tls.X509KeyPair(c.certPEM(), c.keyPEM())
+	if err != nil {
+		panic(err)
+	}
+	return certificate
+}
+
+// writeFile replaces path the way deployments do, through a rename.
+func writeFile(path string, data []byte) {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		panic(err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		panic(err)
+	}
+}
+
+type ReloaderTestSuite struct {
+	suite.Suite
+	dir    string
+	ca     *certificate
+	server *certificate
+	cfg    Config
+}
+
+func (s *ReloaderTestSuite) SetupTest() {
+	s.dir = s.T().TempDir()
+	s.ca = newCertificate("ca", nil)
+	s.server = newCertificate("server", s.ca)
+	s.cfg = Config{
+		CertFile:     filepath.Join(s.dir, "tls.crt"),
+		KeyFile:      filepath.Join(s.dir, "tls.key"),
+		MinVersion:   "1.2",
+		ClientCAFile: filepath.Join(s.dir, "ca.crt"),
+		ClientAuth:   ClientAuthRequire,
+	}
+	s.writeServerCertificate(s.server)
+	writeFile(s.cfg.ClientCAFile, s.ca.certPEM())
+}
+
+// writeServerCertificate writes the key first, so the pair only matches
+// once both are written.
+func (s *ReloaderTestSuite) writeServerCertificate(c *certificate) {
+	writeFile(s.cfg.KeyFile, c.keyPEM())
+	writeFile(s.cfg.CertFile, c.certPEM())
+}
+
+func (s *ReloaderTestSuite) newReloader() *Reloader {
+	r, err := NewReloader(s.cfg)
+	s.Require().NoError(err)
+	s.T().Cleanup(func() { r.Close() })
+	return r
+}
+
+func (s *ReloaderTestSuite) configForClient(r *Reloader) *tls.Config {
+	config, err := r.TLSConfig().GetConfigForClient(&tls.ClientHelloInfo{})
+	s.Require().NoError(err)
+	return config
+}
+
+func (s *ReloaderTestSuite) servedCertificate(r *Reloader) *x509.Certificate {
+	cert, err := x509.ParseCertificate(s.configForClient(r).Certificates[0].Certificate[0])
+	s.Require().NoError(err)
+	return cert
+}
+
+func (s *ReloaderTestSuite) TestNewReloaderErrors() {
+	testCases := []struct {
+		name   string
+		modify func(cfg *Config)
+		err    string
+	}{
+		{name: "unsupported version", modify: func(cfg *Config) { cfg.MinVersion = "1.1" }, err: `unsupported tls version "1.1"`},
+		{name: "unsupported client auth", modify: func(cfg *Config) { cfg.ClientAuth = "maybe" }, err: `unsupported client auth "maybe"`},
+		{name: "client auth without cas", modify: func(cfg *Config) { cfg.ClientCAFile = "" }, err: "client auth requires a client ca file"},
+		{name: "missing certificate", modify: func(cfg *Config) { cfg.CertFile = filepath.Join(s.dir, "missing.crt") }, err: "no such file or directory"},
+		{name: "no certificates in cas", modify: func(cfg *Config) { cfg.ClientCAFile = cfg.KeyFile }, err: "no certificates in " + s.cfg.KeyFile},
+	}
+	for _, tt := range testCases {
+		s.Run(tt.name, func() {
+			cfg := s.cfg
+			tt.modify(&cfg)
+			_, err := NewReloader(cfg)
+			s.ErrorContains(err, tt.err)
+		})
+	}
+}
+
+func (s *ReloaderTestSuite) TestConfig() {
+	r := s.newReloader()
+	config := s.configForClient(r)
+	s.Equal(uint16(tls.VersionTLS12), r.TLSConfig().MinVersion)
+	s.Equal(uint16(tls.VersionTLS12), config.MinVersion)
+	s.Equal(tls.RequireAndVerifyClientCert, config.ClientAuth)
+	s.NotNil(config.ClientCAs)
+	s.Equal(s.server.cert.SerialNumber, s.servedCertificate(r).SerialNumber)
+
+	// Handshakes share the config until the files change.
+	s.Same(config, s.configForClient(r))
+}
+
+func (s *ReloaderTestSuite) TestClientAuthNone() {
+	s.cfg.ClientAuth = ClientAuthNone
+	s.cfg.ClientCAFile = ""
+	config := s.configForClient(s.newReloader())
+	s.Equal(tls.NoClientCert, config.ClientAuth)
+	s.Nil(config.ClientCAs)
+}
+
+func (s *ReloaderTestSuite) TestReload() {
+	r := s.newReloader()
+	before := s.configForClient(r)
+
+	renewed := newCertificate("server", s.ca)
+	s.writeServerCertificate(renewed)
+	s.Eventually(func() bool {
+		return s.servedCertificate(r).SerialNumber.Cmp(renewed.cert.SerialNumber) == 0
+	}, 5*time.Second, 10*time.Millisecond)
+	s.NotSame(before, s.configForClient(r))
+
+	// A broken file keeps the certificate loaded last.
+	s.Require().NoError(os.WriteFile(s.cfg.CertFile, []byte("broken"), 0o600))
+	time.Sleep(100 * time.Millisecond)
+	s.Equal(renewed.cert.SerialNumber, s.servedCertificate(r).SerialNumber)
+}
+
+func (s *ReloaderTestSuite) TestClose() {
+	r := s.newReloader()
+	s.NoError(r.Close())
+	s.NoError(r.Close())
+
+	s.writeServerCertificate(newCertificate("server", s.ca))
+	time.Sleep(100 * time.Millisecond)
+	s.Equal(s.server.cert.SerialNumber, s.servedCertificate(r).SerialNumber)
+}
+
+func (s *ReloaderTestSuite) TestHandshake() {
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", s.newReloader().TLSConfig())
+	s.Require().NoError(err)
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			// Completes the handshake, then hangs up.
+			_ = conn.(*tls.Conn).Handshake()
+			conn.Close()
+		}
+	}()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(s.ca.cert)
+	dial := func(certificates []tls.Certificate) error {
+		conn, err := tls.Dial("tcp", listener.Addr().String(), &tls.Config{RootCAs: roots, Certificates: certificates})
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		// With TLS 1.3 a rejected client certificate surfaces on the
+		// first read.
+		_, err = conn.Read(make([]byte, 1))
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		return err
+	}
+
+	s.NoError(dial([]tls.Certificate{newCertificate("client", s.ca).tlsCertificate()}))
+	s.Error(dial(nil))
+	s.Error(dial([]tls.Certificate{newCertificate("client", nil).tlsCertificate()}))
+}
+
+func (s *ReloaderTestSuite) TestNegotiatesHTTP2() {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	s.Require().NoError(err)
+	server := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.WriteString(w, r.Proto)
+		}),
+		TLSConfig: s.newReloader().TLSConfig(),
+	}
+	go server.ServeTLS(listener, "", "")
+	defer server.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(s.ca.cert)
+	client := &http.Client{Transport: &http.Transport{
+		ForceAttemptHTTP2: true,
+		TLSClientConfig: &tls.Config{
+			RootCAs:      roots,
+			Certificates: []tls.Certificate{newCertificate("client", s.ca).tlsCertificate()},
+		},
+	}}
+	resp, err := client.Get("https://" + listener.Addr().String())
+	s.Require().NoError(err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	s.Require().NoError(err)
+
+	s.Equal("h2", resp.TLS.NegotiatedProtocol)
+	s.Equal("HTTP/2.0", string(body))
+}
+
+func TestReloaderTestSuite(t *testing.T) {
+	suite.Run(t, new(ReloaderTestSuite))
+}
@@ -0,0 +1,62 @@
+package tracing
+
+import (
+	"context"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"os"
+	"path/filepath"
+)
+
+func newOTLPExporter(ctx context.Context, cfg Config) (sdktrace.SpanExporter, error) {
+	var options []otlptracehttp.Option
+	if cfg.Endpoint != "" {
+		options = append(options, otlptracehttp.WithEndpoint(cfg.Endpoint))
+	}
+	if cfg.Insecure {
+		options = append(options, otlptracehttp.WithInsecure())
+	}
+	return otlptracehttp.New(ctx, options...)
+}
+
+func newStdoutExporter(ctx context.Context, cfg Config) (sdktrace.SpanExporter, error) {
+	return stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+}
+
+// fileExporter writes spans as JSON, one per line, and closes the file on
+// shutdown.
+type fileExporter struct {
+	sdktrace.SpanExporter
+	file *os.File
+}
+
+func (f *fileExporter) Shutdown(ctx context.Context) error {
+	err := f.SpanExporter.Shutdown(ctx)
+	if closeErr := f.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func newFileExporter(ctx context.Context, cfg Config) (sdktrace.SpanExporter, error) {
+	if err := os.MkdirAll(filepath.Dir(cfg.File), 0o755); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(cfg.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	exporter, err := stdouttrace.New(stdouttrace.WithWriter(file))
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &fileExporter{SpanExporter: exporter, file: file}, nil
+}
+
+func init() {
+	RegisterExporter("otlp", newOTLPExporter)
+	RegisterExporter("stdout", newStdoutExporter)
+	RegisterExporter("file", newFileExporter)
+}
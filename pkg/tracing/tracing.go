@@ -0,0 +1,108 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"strings"
+	"sync"
+)
+
+const instrumentationName = "crud-customer"
+
+type Config struct {
+	ServiceName string
+	// Exporter names a registered exporter: "otlp", "stdout" or "file".
+	Exporter string
+	// Endpoint is the host:port of the OTLP collector. When empty, the
+	// OTEL_EXPORTER_OTLP_ENDPOINT environment variable or the default of the
+	// exporter is used.
+	Endpoint string
+	Insecure bool
+	// File is written by the file exporter.
+	File string
+	// SampleRatio is the share of new traces sampled; traces started
+	// upstream keep the decision of their parent.
+	SampleRatio float64
+}
+
+type ExporterFactory func(ctx context.Context, cfg Config) (sdktrace.SpanExporter, error)
+
+var (
+	exportersMu sync.RWMutex
+	exporters   = map[string]ExporterFactory{}
+)
+
+func RegisterExporter(name string, factory ExporterFactory) {
+	exportersMu.Lock()
+	defer exportersMu.Unlock()
+	exporters[name] = factory
+}
+
+// Setup installs a global tracer provider exporting through the configured
+// exporter, and W3C trace-context and baggage propagation. The returned
+// function flushes pending spans and must be called before exiting.
+func Setup(ctx context.Context, cfg Config) (func(ctx context.Context) error, error) {
+	exportersMu.RLock()
+	factory, ok := exporters[cfg.Exporter]
+	exportersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown trace exporter %q", cfg.Exporter)
+	}
+	exporter, err := factory(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(attribute.String("service.name", cfg.ServiceName)))
+	if err != nil {
+		return nil, err
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(&noRootStatementSampler{
+			next: sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio)),
+		}),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return provider.Shutdown, nil
+}
+
+// noRootStatementSampler drops the spans of SQL statements run outside any
+// trace, such as the outbox polls, which would otherwise start a trace each.
+type noRootStatementSampler struct {
+	next sdktrace.Sampler
+}
+
+func (s *noRootStatementSampler) ShouldSample(parameters sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	if strings.HasPrefix(parameters.Name, "gorm.") && !trace.SpanContextFromContext(parameters.ParentContext).IsValid() {
+		return sdktrace.SamplingResult{Decision: sdktrace.Drop}
+	}
+	return s.next.ShouldSample(parameters)
+}
+
+func (s *noRootStatementSampler) Description() string {
+	return "NoRootStatement{" + s.next.Description() + "}"
+}
+
+// Start starts a span named name as a child of the span in ctx, if any.
+func Start(ctx context.Context, name string) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name)
+}
+
+// End records err, if any, on span and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
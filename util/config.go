@@ -1,27 +1,405 @@
 package util
 
 import (
+	"crud-customer/pkg/metrics"
 	"crud-customer/util/validator"
+	"errors"
+	"fmt"
+	"github.com/fsnotify/fsnotify"
+	govalidator "github.com/go-playground/validator/v10"
 	"github.com/spf13/viper"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
+	"sync"
+	"time"
 )
 
+// EnvPrefix prefixes the environment variables overriding config keys:
+// server.port is set by CRUDCUSTOMER_SERVER_PORT. A variable suffixed with
+// _FILE, like CRUDCUSTOMER_OUTBOX_HTTPURL_FILE, names a file holding the
+// value, for secrets mounted as files.
+const EnvPrefix = "CRUDCUSTOMER"
+
+// DefaultConfigFile is read when SetConfigFile is not called.
+const DefaultConfigFile = "config.yaml"
+
+var (
+	configFile    = DefaultConfigFile
+	configProfile string
+
+	// The keys registered by registerKeys and readSecretFiles, for
+	// ConfigSource.
+	defaultKeys = map[string]bool{}
+	envKeys     = map[string]bool{}
+	secretKeys  = map[string]string{}
+	sourceFiles = map[string]*viper.Viper{}
+
+	reloadMu       sync.Mutex
+	reloadHandlers []interface{}
+	lastConfig     interface{}
+	configWatcher  *fsnotify.Watcher
+)
+
+// SetConfigFile sets the file GetConfig reads and the profile layered on
+// top of it: profile "prod" of config.yaml is config.prod.yaml, next to it.
+// An empty profile reads the file alone.
+func SetConfigFile(file string, profile string) {
+	if file != "" {
+		configFile = file
+	}
+	configProfile = profile
+}
+
+// ProfileFile returns the profile file layered on top of file.
+func ProfileFile(file string, profile string) string {
+	ext := filepath.Ext(file)
+	return strings.TrimSuffix(file, ext) + "." + profile + ext
+}
+
+// GetConfig loads the config file, its profile, the environment and the
+// secret files, and validates the result. It then watches the config file
+// and its profile: every valid change is passed to the functions registered
+// with OnConfigChange, and invalid ones are logged and rejected.
 func GetConfig[T any]() (*T, error) {
-	viper.SetConfigName("config")
+	viper.SetConfigFile(configFile)
 	viper.SetConfigType("yaml")
-	viper.AddConfigPath("./")
-	viper.AutomaticEnv()
+	viper.SetEnvPrefix(EnvPrefix)
 	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
-	validate := validator.GetValidator()
+	viper.AutomaticEnv()
+	// Forget the sources of an earlier GetConfig, whose environment and
+	// files may have changed since.
+	defaultKeys = map[string]bool{}
+	envKeys = map[string]bool{}
+	secretKeys = map[string]string{}
+	sourceFiles = map[string]*viper.Viper{}
+	var cfg T
+	registerKeys("", reflect.TypeOf(cfg))
+	if err := readConfigFiles(); err != nil {
+		return nil, err
+	}
+	if err := readSecretFiles("", reflect.TypeOf(cfg)); err != nil {
+		return nil, err
+	}
+	loaded, err := loadConfig[T]()
+	if err != nil {
+		return nil, err
+	}
+
+	reloadMu.Lock()
+	lastConfig = *loaded
+	reloadMu.Unlock()
+	if err := watchConfigFiles(reloadConfig[T]); err != nil {
+		return nil, fmt.Errorf("error watching config file %s: %w", configFile, err)
+	}
+	return loaded, nil
+}
+
+// configFiles returns the config file and, with a profile, its profile file.
+func configFiles() []string {
+	files := []string{configFile}
+	if configProfile != "" {
+		files = append(files, ProfileFile(configFile, configProfile))
+	}
+	return files
+}
+
+// watchConfigFiles calls reload whenever the config file or its profile
+// changes, replacing the watcher of an earlier GetConfig. Directories are
+// watched rather than files so atomic renames are picked up; viper only
+// watches the config file, which would miss edits of the profile.
+func watchConfigFiles(reload func()) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	dirs := map[string]bool{}
+	for _, file := range configFiles() {
+		dir := filepath.Dir(file)
+		if dirs[dir] {
+			continue
+		}
+		dirs[dir] = true
+		if err := watcher.Add(dir); err != nil {
+			watcher.Close()
+			return err
+		}
+	}
+
+	reloadMu.Lock()
+	if configWatcher != nil {
+		configWatcher.Close()
+	}
+	configWatcher = watcher
+	reloadMu.Unlock()
+
+	go func() {
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename|fsnotify.Remove) != 0 && isConfigFile(event.Name) {
+					reload()
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				slog.Warn("error watching config file", "file", configFile, "error", err)
+			}
+		}
+	}()
+	return nil
+}
+
+func isConfigFile(name string) bool {
+	// Kubernetes swaps mounted config maps through a "..data" symlink.
+	if strings.HasPrefix(filepath.Base(name), "..") {
+		return true
+	}
+	for _, file := range configFiles() {
+		if filepath.Clean(name) == filepath.Clean(file) {
+			return true
+		}
+	}
+	return false
+}
+
+// OnConfigChange registers fn to receive every valid change of the config
+// loaded by GetConfig. fn runs on the watcher goroutine, so it must only
+// apply settings that are safe to change while serving.
+func OnConfigChange[T any](fn func(cfg *T)) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+	reloadHandlers = append(reloadHandlers, fn)
+}
+
+// readConfigFiles reads the config file and merges its profile over it.
+// The config file stays the one viper reads on the next call.
+func readConfigFiles() error {
 	if err := viper.ReadInConfig(); err != nil {
-		panic(err)
+		return fmt.Errorf("error reading config file %s: %w", configFile, err)
+	}
+	if configProfile == "" {
+		return nil
+	}
+	profileFile := ProfileFile(configFile, configProfile)
+	viper.SetConfigFile(profileFile)
+	defer viper.SetConfigFile(configFile)
+	if err := viper.MergeInConfig(); err != nil {
+		return fmt.Errorf("error reading config profile %s: %w", profileFile, err)
 	}
+	return nil
+}
+
+func loadConfig[T any]() (*T, error) {
 	var cfg T
 	if err := viper.Unmarshal(&cfg); err != nil {
-		return nil, err
+		return nil, fmt.Errorf("invalid config: %w", err)
 	}
-	if err := validate.Struct(cfg); err != nil {
-		return nil, err
+	if err := validator.GetValidator().Struct(cfg); err != nil {
+		return nil, validationError(reflect.TypeOf(cfg), err)
 	}
 	return &cfg, nil
 }
+
+// validationError lists every invalid key of the config, by the name it has
+// in the config file.
+func validationError(t reflect.Type, err error) error {
+	var validationErrors govalidator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+	problems := make([]string, 0, len(validationErrors))
+	for _, fieldErr := range validationErrors {
+		problem := fmt.Sprintf("%s: failed on %q", configKey(t, fieldErr.StructNamespace()), fieldErr.Tag())
+		if fieldErr.Param() != "" {
+			problem += fmt.Sprintf(" (%s)", fieldErr.Param())
+		}
+		problems = append(problems, problem)
+	}
+	return fmt.Errorf("invalid config:\n  %s", strings.Join(problems, "\n  "))
+}
+
+// configKey turns the struct namespace of a field, like
+// Config.Server.RateLimit.Groups[bulk].Requests, into its config key,
+// server.rateLimit.groups.bulk.requests.
+func configKey(t reflect.Type, namespace string) string {
+	parts := strings.Split(namespace, ".")[1:]
+	keys := make([]string, 0, len(parts))
+	for _, part := range parts {
+		name, index, _ := strings.Cut(part, "[")
+		for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Map {
+			t = t.Elem()
+		}
+		key := name
+		if t.Kind() == reflect.Struct {
+			if field, ok := t.FieldByName(name); ok {
+				key = fieldKey(field)
+				t = field.Type
+			}
+		}
+		keys = append(keys, key)
+		if index != "" {
+			keys = append(keys, strings.TrimSuffix(index, "]"))
+			for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Map {
+				t = t.Elem()
+			}
+		}
+	}
+	return strings.Join(keys, ".")
+}
+
+// reloadConfig reads the config files again, as viper keeps the previous
+// config when the file cannot be parsed. Editors save in several writes, so
+// a config equal to the last one is ignored.
+func reloadConfig[T any]() {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+	err := readConfigFiles()
+	var cfg *T
+	if err == nil {
+		cfg, err = loadConfig[T]()
+	}
+	if err != nil {
+		slog.Error("rejected config reload", "file", configFile, "error", err)
+		metrics.ConfigReloadsTotal.WithLabelValues(metrics.ReloadRejected).Inc()
+		return
+	}
+	if reflect.DeepEqual(lastConfig, *cfg) {
+		return
+	}
+	lastConfig = *cfg
+	for _, handler := range reloadHandlers {
+		if fn, ok := handler.(func(cfg *T)); ok {
+			fn(cfg)
+		}
+	}
+	metrics.ConfigReloadsTotal.WithLabelValues(metrics.ReloadApplied).Inc()
+}
+
+func fieldKey(field reflect.StructField) string {
+	key, _, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+	if key == "" {
+		// Untagged fields are matched ignoring case and written in lower
+		// camel case in the config file.
+		key = strings.ToLower(field.Name[:1]) + field.Name[1:]
+	}
+	return key
+}
+
+// configFields calls fn with the key of every field of the struct t
+// outside maps and slices, and descends into the fields that are structs
+// themselves when fn returns true.
+func configFields(prefix string, t reflect.Type, fn func(key string, field reflect.StructField) bool) {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key := fieldKey(field)
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		if fn(key, field) && field.Type.Kind() == reflect.Struct && field.Type != reflect.TypeOf(time.Time{}) {
+			configFields(key, field.Type, fn)
+		}
+	}
+}
+
+// registerKeys registers the `default` struct tag of every field in t with
+// viper, so optional config sections can be left out of the config file,
+// and binds every key to its environment variable, so that keys missing
+// from the file can be set from the environment too.
+func registerKeys(prefix string, t reflect.Type) {
+	configFields(prefix, t, func(key string, field reflect.StructField) bool {
+		if value, ok := field.Tag.Lookup("default"); ok {
+			viper.SetDefault(key, value)
+			_ = viper.BindEnv(key)
+			defaultKeys[key] = true
+			envKeys[key] = true
+			return false
+		}
+		if field.Type.Kind() != reflect.Struct {
+			_ = viper.BindEnv(key)
+			envKeys[key] = true
+		}
+		return true
+	})
+}
+
+// readSecretFiles sets every key whose _FILE environment variable is set to
+// the content of the file it names, without the trailing newline.
+func readSecretFiles(prefix string, t reflect.Type) error {
+	var errs []error
+	configFields(prefix, t, func(key string, field reflect.StructField) bool {
+		envVar := envName(key) + "_FILE"
+		file, ok := os.LookupEnv(envVar)
+		if !ok {
+			return true
+		}
+		content, err := os.ReadFile(file)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error reading %s: %w", envVar, err))
+			return false
+		}
+		viper.Set(key, strings.TrimRight(string(content), "\r\n"))
+		secretKeys[key] = envVar
+		return false
+	})
+	return errors.Join(errs...)
+}
+
+// envName returns the environment variable overriding key.
+func envName(key string) string {
+	return EnvPrefix + "_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// ConfigSource tells where the value of key in the config loaded by
+// GetConfig comes from, in the order viper looks: a secret file, the
+// environment, the profile file, the config file or the default. Keys set by
+// none of them are "unset".
+func ConfigSource(key string) string {
+	if envVar, ok := secretKeys[key]; ok {
+		return "env " + envVar
+	}
+	if envKeys[key] {
+		if _, ok := os.LookupEnv(envName(key)); ok {
+			return "env " + envName(key)
+		}
+	}
+	if configProfile != "" {
+		if profileFile := ProfileFile(configFile, configProfile); isSetIn(profileFile, key) {
+			return profileFile
+		}
+	}
+	if isSetIn(configFile, key) {
+		return configFile
+	}
+	if defaultKeys[key] {
+		return "default"
+	}
+	return "unset"
+}
+
+// isSetIn tells whether key is set in file. Files are read once.
+func isSetIn(file string, key string) bool {
+	v, ok := sourceFiles[file]
+	if !ok {
+		v = viper.New()
+		v.SetConfigFile(file)
+		v.SetConfigType("yaml")
+		if err := v.ReadInConfig(); err != nil {
+			v = nil
+		}
+		sourceFiles[file] = v
+	}
+	return v != nil && v.IsSet(key)
+}
@@ -0,0 +1,98 @@
+package util
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// ExampleConfig returns a config file for T with every key set to its
+// default, to the value of its `example` tag when it has none, or else to its
+// zero value, and commented with its validation rules. Entries of maps of
+// structs are shown commented out. Keys that are required without a default
+// need an `example` tag for the config to be valid.
+func ExampleConfig[T any]() string {
+	var cfg T
+	t := reflect.TypeOf(cfg)
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Example config generated from %s.\n", t)
+	b.WriteString("# Keys are set to their defaults, or to examples or zero values when they have none.\n")
+	writeExample(&b, 0, t)
+	return b.String()
+}
+
+func writeExample(b *strings.Builder, indent int, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		line := strings.Repeat("  ", indent) + fieldKey(field) + ":"
+		if field.Type.Kind() == reflect.Struct && field.Type != reflect.TypeOf(time.Time{}) {
+			b.WriteString(line + "\n")
+			writeExample(b, indent+1, field.Type)
+			continue
+		}
+		line += " " + exampleValue(field)
+		if comment := exampleComment(field); comment != "" {
+			line += " # " + comment
+		}
+		b.WriteString(line + "\n")
+
+		if field.Type.Kind() == reflect.Map && field.Type.Elem().Kind() == reflect.Struct {
+			var entry strings.Builder
+			entry.WriteString("<name>:\n")
+			writeExample(&entry, 1, field.Type.Elem())
+			for _, entryLine := range strings.Split(strings.TrimSuffix(entry.String(), "\n"), "\n") {
+				b.WriteString(strings.Repeat("  ", indent+1) + "# " + entryLine + "\n")
+			}
+		}
+	}
+}
+
+// exampleValue writes the default of field, from its `default` or else its
+// `example` tag, or its zero value in YAML.
+func exampleValue(field reflect.StructField) string {
+	value, ok := field.Tag.Lookup("default")
+	if !ok {
+		value, ok = field.Tag.Lookup("example")
+	}
+	switch {
+	case field.Type == durationType:
+		if !ok {
+			return "0s"
+		}
+		return value
+	case field.Type.Kind() == reflect.String:
+		return strconv.Quote(value)
+	case field.Type.Kind() == reflect.Slice:
+		if !ok {
+			return "[]"
+		}
+		items := strings.Split(value, ",")
+		for i, item := range items {
+			items[i] = strconv.Quote(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case field.Type.Kind() == reflect.Map:
+		return "{}"
+	case ok:
+		return value
+	}
+	return fmt.Sprint(reflect.Zero(field.Type).Interface())
+}
+
+func exampleComment(field reflect.StructField) string {
+	var comments []string
+	if rules := field.Tag.Get("validate"); rules != "" {
+		comments = append(comments, "validate: "+rules)
+	}
+	if field.Tag.Get("secret") == "true" {
+		comments = append(comments, "secret")
+	}
+	return strings.Join(comments, ", ")
+}
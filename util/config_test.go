@@ -0,0 +1,254 @@
+package util
+
+import (
+	"crud-customer/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/suite"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testConfig struct {
+	Server   testServerConfig
+	Database struct {
+		Password string
+	}
+	Groups map[string]testGroupConfig `validate:"dive"`
+}
+
+type testServerConfig struct {
+	Host string `default:"localhost"`
+	Port int    `validate:"required,min=1"`
+}
+
+type testGroupConfig struct {
+	Requests int `validate:"min=1"`
+}
+
+type ConfigTestSuite struct {
+	suite.Suite
+	dir  string
+	file string
+}
+
+func (s *ConfigTestSuite) SetupTest() {
+	resetConfig()
+	s.dir = s.T().TempDir()
+	s.file = filepath.Join(s.dir, "config.yaml")
+	SetConfigFile(s.file, "")
+}
+
+func (s *ConfigTestSuite) TearDownTest() {
+	resetConfig()
+}
+
+// resetConfig forgets the config loaded by an earlier GetConfig.
+func resetConfig() {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+	if configWatcher != nil {
+		configWatcher.Close()
+		configWatcher = nil
+	}
+	viper.Reset()
+	configFile = DefaultConfigFile
+	configProfile = ""
+	reloadHandlers = nil
+	lastConfig = nil
+}
+
+// stopWatching closes the watcher of GetConfig, so tests can reload
+// without racing it.
+func stopWatching() {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+	configWatcher.Close()
+	configWatcher = nil
+}
+
+func (s *ConfigTestSuite) writeFile(file string, content string) {
+	s.Require().NoError(os.WriteFile(file, []byte(content), 0o600))
+}
+
+func (s *ConfigTestSuite) TestDefaults() {
+	s.writeFile(s.file, "server:\n  port: 8080\n")
+
+	cfg, err := GetConfig[testConfig]()
+
+	s.Require().NoError(err)
+	s.Equal("localhost", cfg.Server.Host)
+	s.Equal(8080, cfg.Server.Port)
+	s.Equal("default", ConfigSource("server.host"))
+	s.Equal(s.file, ConfigSource("server.port"))
+	s.Equal("unset", ConfigSource("database.password"))
+}
+
+func (s *ConfigTestSuite) TestProfileLayering() {
+	s.writeFile(s.file, "server:\n  host: example.com\n  port: 8080\n")
+	profileFile := filepath.Join(s.dir, "config.prod.yaml")
+	s.writeFile(profileFile, "server:\n  port: 9090\n")
+	SetConfigFile(s.file, "prod")
+
+	cfg, err := GetConfig[testConfig]()
+
+	s.Require().NoError(err)
+	s.Equal(profileFile, ProfileFile(s.file, "prod"))
+	s.Equal("example.com", cfg.Server.Host)
+	s.Equal(9090, cfg.Server.Port)
+	s.Equal(s.file, ConfigSource("server.host"))
+	s.Equal(profileFile, ConfigSource("server.port"))
+}
+
+func (s *ConfigTestSuite) TestMissingProfile() {
+	s.writeFile(s.file, "server:\n  port: 8080\n")
+	SetConfigFile(s.file, "prod")
+
+	_, err := GetConfig[testConfig]()
+
+	s.ErrorContains(err, "error reading config profile")
+}
+
+func (s *ConfigTestSuite) TestEnvOverridesFiles() {
+	s.writeFile(s.file, "server:\n  port: 8080\n")
+	profileFile := filepath.Join(s.dir, "config.prod.yaml")
+	s.writeFile(profileFile, "server:\n  port: 9090\n")
+	SetConfigFile(s.file, "prod")
+	s.T().Setenv("CRUDCUSTOMER_SERVER_PORT", "7070")
+	s.T().Setenv("CRUDCUSTOMER_SERVER_HOST", "env.example.com")
+	s.T().Setenv("SERVER_PORT", "6060")
+
+	cfg, err := GetConfig[testConfig]()
+
+	s.Require().NoError(err)
+	s.Equal(7070, cfg.Server.Port)
+	s.Equal("env.example.com", cfg.Server.Host)
+	s.Equal("env CRUDCUSTOMER_SERVER_PORT", ConfigSource("server.port"))
+}
+
+func (s *ConfigTestSuite) TestEnvSetsKeysMissingFromFile() {
+	s.writeFile(s.file, "server:\n  port: 8080\n")
+	s.T().Setenv("CRUDCUSTOMER_DATABASE_PASSWORD", "secret")
+
+	cfg, err := GetConfig[testConfig]()
+
+	s.Require().NoError(err)
+	s.Equal("secret", cfg.Database.Password)
+}
+
+func (s *ConfigTestSuite) TestSecretFile() {
+	s.writeFile(s.file, "server:\n  port: 8080\ndatabase:\n  password: from-file\n")
+	secretFile := filepath.Join(s.dir, "password")
+	s.writeFile(secretFile, "s3cret\n")
+	s.T().Setenv("CRUDCUSTOMER_DATABASE_PASSWORD_FILE", secretFile)
+
+	cfg, err := GetConfig[testConfig]()
+
+	s.Require().NoError(err)
+	s.Equal("s3cret", cfg.Database.Password)
+	s.Equal("env CRUDCUSTOMER_DATABASE_PASSWORD_FILE", ConfigSource("database.password"))
+}
+
+func (s *ConfigTestSuite) TestMissingSecretFile() {
+	s.writeFile(s.file, "server:\n  port: 8080\n")
+	s.T().Setenv("CRUDCUSTOMER_DATABASE_PASSWORD_FILE", filepath.Join(s.dir, "missing"))
+
+	_, err := GetConfig[testConfig]()
+
+	s.ErrorContains(err, "error reading CRUDCUSTOMER_DATABASE_PASSWORD_FILE")
+}
+
+func (s *ConfigTestSuite) TestValidationError() {
+	s.writeFile(s.file, "groups:\n  bulk:\n    requests: 0\n")
+
+	_, err := GetConfig[testConfig]()
+
+	s.Require().Error(err)
+	s.Equal("invalid config:\n"+
+		"  server.port: failed on \"required\"\n"+
+		"  groups.bulk.requests: failed on \"min\" (1)", err.Error())
+}
+
+func (s *ConfigTestSuite) TestReloadsProfileChanges() {
+	s.writeFile(s.file, "server:\n  port: 8080\n")
+	profileFile := filepath.Join(s.dir, "config.prod.yaml")
+	s.writeFile(profileFile, "server:\n  port: 9090\n")
+	SetConfigFile(s.file, "prod")
+	_, err := GetConfig[testConfig]()
+	s.Require().NoError(err)
+	reloaded := make(chan *testConfig, 10)
+	OnConfigChange(func(cfg *testConfig) {
+		reloaded <- cfg
+	})
+
+	s.writeFile(profileFile, "server:\n  port: 9191\n")
+
+	select {
+	case cfg := <-reloaded:
+		s.Equal(9191, cfg.Server.Port)
+	case <-time.After(5 * time.Second):
+		s.Fail("profile change was not reloaded")
+	}
+}
+
+func (s *ConfigTestSuite) TestReloadAppliesValidChanges() {
+	s.writeFile(s.file, "server:\n  port: 8080\n")
+	_, err := GetConfig[testConfig]()
+	s.Require().NoError(err)
+	stopWatching()
+	var reloaded []*testConfig
+	OnConfigChange(func(cfg *testConfig) {
+		reloaded = append(reloaded, cfg)
+	})
+	applied := testutil.ToFloat64(metrics.ConfigReloadsTotal.WithLabelValues(metrics.ReloadApplied))
+
+	s.writeFile(s.file, "server:\n  port: 9090\n")
+	reloadConfig[testConfig]()
+	reloadConfig[testConfig]()
+
+	s.Require().Len(reloaded, 1)
+	s.Equal(9090, reloaded[0].Server.Port)
+	s.Equal(applied+1, testutil.ToFloat64(metrics.ConfigReloadsTotal.WithLabelValues(metrics.ReloadApplied)))
+}
+
+func (s *ConfigTestSuite) TestReloadRejectsInvalidChanges() {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "failing validation", content: "server:\n  port: 0\n"},
+		{name: "unparsable", content: "server: [port\n"},
+	}
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			resetConfig()
+			SetConfigFile(s.file, "")
+			s.writeFile(s.file, "server:\n  port: 8080\n")
+			_, err := GetConfig[testConfig]()
+			s.Require().NoError(err)
+			stopWatching()
+			var reloaded []*testConfig
+			OnConfigChange(func(cfg *testConfig) {
+				reloaded = append(reloaded, cfg)
+			})
+			rejected := testutil.ToFloat64(metrics.ConfigReloadsTotal.WithLabelValues(metrics.ReloadRejected))
+
+			s.writeFile(s.file, tt.content)
+			reloadConfig[testConfig]()
+
+			s.Empty(reloaded)
+			s.Equal(rejected+1, testutil.ToFloat64(metrics.ConfigReloadsTotal.WithLabelValues(metrics.ReloadRejected)))
+
+			// The previous config stays the one later changes compare to.
+			s.writeFile(s.file, "server:\n  port: 8080\n")
+			reloadConfig[testConfig]()
+			s.Empty(reloaded)
+		})
+	}
+}
+
+func TestConfigTestSuite(t *testing.T) {
+	suite.Run(t, new(ConfigTestSuite))
+}
@@ -1 +1,39 @@
 package testhelper
+
+import (
+	"context"
+	"crud-customer/config"
+	"crud-customer/pkg/database"
+	"crud-customer/pkg/fieldcrypt"
+	"crypto/sha256"
+	"gorm.io/gorm"
+)
+
+// NewKeyring returns a keyring holding a key for each of ids, derived from
+// the id so tests using the same ids can read each other's values.
+func NewKeyring(primary string, ids ...string) *fieldcrypt.Keyring {
+	keys := make(map[string][]byte, len(ids))
+	for _, id := range ids {
+		key := sha256.Sum256([]byte(id))
+		keys[id] = key[:]
+	}
+	blindIndexKey := sha256.Sum256([]byte("blind index"))
+	keyring, err := fieldcrypt.NewKeyring(primary, keys, blindIndexKey[:])
+	if err != nil {
+		panic(err)
+	}
+	return keyring
+}
+
+// NewMigratedDB opens the database file and applies the migrations, so tests
+// run against the schema the application uses.
+func NewMigratedDB(file string) *gorm.DB {
+	db, err := database.NewGormDB(&config.Config{Database: config.DatabaseConfig{File: file}})
+	if err != nil {
+		panic(err)
+	}
+	if _, err := db.Migrator().Up(context.Background(), 0); err != nil {
+		panic(err)
+	}
+	return db.GetDB()
+}
@@ -1,9 +1,10 @@
 package validator
 
-import "sync"
-
 import (
+	"crud-customer/pkg/logging"
 	govalidator "github.com/go-playground/validator/v10"
+	"github.com/labstack/gommon/bytes"
+	"sync"
 )
 
 var (
@@ -14,6 +15,15 @@ var (
 func GetValidator() *govalidator.Validate {
 	once.Do(func() {
 		validate := govalidator.New()
+		// bytesize accepts sizes like the body limit, e.g. "10M".
+		_ = validate.RegisterValidation("bytesize", func(fl govalidator.FieldLevel) bool {
+			_, err := bytes.Parse(fl.Field().String())
+			return err == nil
+		})
+		_ = validate.RegisterValidation("loglevel", func(fl govalidator.FieldLevel) bool {
+			_, err := logging.ParseLevel(fl.Field().String())
+			return err == nil
+		})
 		validatorInstance = validate
 	})
 